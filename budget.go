@@ -0,0 +1,27 @@
+package claudesdk
+
+import "github.com/wagiedev/claude-agent-sdk-go/internal/budget"
+
+// Budget is a spend limit in USD shared by many Query, QueryStream and Client
+// runs. Pass it to each run with WithBudget:
+//
+//	b := claudesdk.NewBudget(10)
+//	b.OnThreshold(0.8, func(ctx context.Context, s *claudesdk.BudgetSession) {
+//	    _ = s.SetModel(ctx, "claude-haiku-4-5")
+//	})
+//
+// Spend is estimated live from assistant message usage and reconciled with
+// the CLI's TotalCostUSD. When it reaches the limit, running sessions are
+// interrupted and new runs and turns fail with ErrBudgetExhausted.
+type Budget = budget.Budget
+
+// BudgetSession is one run charged to a Budget. Threshold callbacks receive it
+// to steer the run.
+type BudgetSession = budget.Session
+
+// BudgetThresholdFunc is called for each running session when spend crosses
+// a soft threshold registered with Budget.OnThreshold.
+type BudgetThresholdFunc = budget.ThresholdFunc
+
+// NewBudget creates a Budget with the given limit in USD.
+var NewBudget = budget.New
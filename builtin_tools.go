@@ -0,0 +1,146 @@
+package claudesdk
+
+import "github.com/wagiedev/claude-agent-sdk-go/internal/message"
+
+// Built-in tool names.
+const (
+	ToolBash         = message.ToolBash
+	ToolRead         = message.ToolRead
+	ToolWrite        = message.ToolWrite
+	ToolEdit         = message.ToolEdit
+	ToolMultiEdit    = message.ToolMultiEdit
+	ToolGlob         = message.ToolGlob
+	ToolGrep         = message.ToolGrep
+	ToolWebFetch     = message.ToolWebFetch
+	ToolWebSearch    = message.ToolWebSearch
+	ToolTask         = message.ToolTask
+	ToolTodoWrite    = message.ToolTodoWrite
+	ToolNotebookEdit = message.ToolNotebookEdit
+	ToolExitPlanMode = message.ToolExitPlanMode
+)
+
+// ToolInput is the typed input of a built-in tool. Switch on the concrete
+// type returned by ToolUseBlock.Decode or DecodeToolInput:
+//
+//	input, err := claudesdk.DecodeToolInput(toolName, rawInput)
+//	switch in := input.(type) {
+//	case *claudesdk.BashInput:
+//	    check(in.Command)
+//	case *claudesdk.EditInput:
+//	    check(in.FilePath)
+//	}
+type ToolInput = message.ToolInput
+
+// ToolResult is the typed result of a built-in tool, decoded from
+// UserMessage.ToolUseResult.
+type ToolResult = message.ToolResult
+
+// BashInput is the input of the Bash tool.
+type BashInput = message.BashInput
+
+// ReadInput is the input of the Read tool.
+type ReadInput = message.ReadInput
+
+// WriteInput is the input of the Write tool.
+type WriteInput = message.WriteInput
+
+// EditInput is the input of the Edit tool.
+type EditInput = message.EditInput
+
+// EditOperation is one replacement of a MultiEdit call.
+type EditOperation = message.EditOperation
+
+// MultiEditInput is the input of the MultiEdit tool.
+type MultiEditInput = message.MultiEditInput
+
+// GlobInput is the input of the Glob tool.
+type GlobInput = message.GlobInput
+
+// GrepInput is the input of the Grep tool.
+type GrepInput = message.GrepInput
+
+// WebFetchInput is the input of the WebFetch tool.
+type WebFetchInput = message.WebFetchInput
+
+// WebSearchInput is the input of the WebSearch tool.
+type WebSearchInput = message.WebSearchInput
+
+// TaskInput is the input of the Task tool, which starts a subagent.
+type TaskInput = message.TaskInput
+
+// Todo is an item of the todo list kept with TodoWrite.
+type Todo = message.Todo
+
+// TodoWriteInput is the input of the TodoWrite tool.
+type TodoWriteInput = message.TodoWriteInput
+
+// NotebookEditInput is the input of the NotebookEdit tool.
+type NotebookEditInput = message.NotebookEditInput
+
+// ExitPlanModeInput is the input of the ExitPlanMode tool.
+type ExitPlanModeInput = message.ExitPlanModeInput
+
+// Hunk is one hunk of a unified diff in a tool result.
+type Hunk = message.Hunk
+
+// BashResult is the result of the Bash tool.
+type BashResult = message.BashResult
+
+// ReadFile is the file content returned by the Read tool.
+type ReadFile = message.ReadFile
+
+// ReadResult is the result of the Read tool.
+type ReadResult = message.ReadResult
+
+// WriteResult is the result of the Write tool.
+type WriteResult = message.WriteResult
+
+// EditResult is the result of the Edit tool.
+type EditResult = message.EditResult
+
+// MultiEditResult is the result of the MultiEdit tool.
+type MultiEditResult = message.MultiEditResult
+
+// GlobResult is the result of the Glob tool.
+type GlobResult = message.GlobResult
+
+// GrepResult is the result of the Grep tool.
+type GrepResult = message.GrepResult
+
+// WebFetchResult is the result of the WebFetch tool.
+type WebFetchResult = message.WebFetchResult
+
+// WebSearchResult is the result of the WebSearch tool.
+type WebSearchResult = message.WebSearchResult
+
+// TaskResult is the result of the Task tool.
+type TaskResult = message.TaskResult
+
+// TodoWriteResult is the result of the TodoWrite tool.
+type TodoWriteResult = message.TodoWriteResult
+
+// NotebookEditResult is the result of the NotebookEdit tool.
+type NotebookEditResult = message.NotebookEditResult
+
+// ExitPlanModeResult is the result of the ExitPlanMode tool.
+type ExitPlanModeResult = message.ExitPlanModeResult
+
+// DecodeToolInput returns the typed input of a built-in tool, e.g. the input
+// passed to a ToolPermissionCallback or a PreToolUse hook. Other tools, such
+// as MCP tools, return ErrUnknownTool.
+func DecodeToolInput(toolName string, input map[string]any) (ToolInput, error) {
+	return message.DecodeToolInput(toolName, input)
+}
+
+// DecodeToolResult returns the typed result of a built-in tool from
+// UserMessage.ToolUseResult, given the name of the tool that produced it.
+// Other tools return ErrUnknownTool.
+func DecodeToolResult(toolName string, result map[string]any) (ToolResult, error) {
+	return message.DecodeToolResult(toolName, result)
+}
+
+// EncodeToolInput converts a typed tool input to the map form used for
+// updated inputs in permission results and hook decisions.
+func EncodeToolInput(input ToolInput) (map[string]any, error) {
+	return message.EncodeToolInput(input)
+}
@@ -0,0 +1,551 @@
+// Package changes records the files an agent writes and reports them as
+// unified diffs, per turn and per session, without shelling out to git.
+//
+// A Tracker snapshots each file before and after the Write, Edit, MultiEdit
+// and NotebookEdit tools run, using PreToolUse and PostToolUse hooks. Feed it
+// the message stream too, so it knows where turns end:
+//
+//	tracker := changes.NewTracker()
+//	client := claudesdk.NewClient()
+//	err := client.Start(ctx, tracker.Option())
+//	...
+//	for msg, err := range client.ReceiveResponse(ctx) {
+//	    tracker.Observe(msg)
+//	}
+//	fmt.Print(tracker.LastTurn().Diff())
+//
+// Without the hooks, changes are rebuilt from the tool results in the message
+// stream, which covers Write, Edit and MultiEdit.
+package changes
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	claudesdk "github.com/wagiedev/claude-agent-sdk-go"
+)
+
+// editTools are the tools whose changes are tracked.
+var editTools = []string{
+	claudesdk.ToolWrite, claudesdk.ToolEdit, claudesdk.ToolMultiEdit, claudesdk.ToolNotebookEdit,
+}
+
+// Status is how a change set affects a file.
+type Status string
+
+const (
+	// StatusCreated means the file did not exist before.
+	StatusCreated Status = "created"
+	// StatusModified means the file's content changed.
+	StatusModified Status = "modified"
+	// StatusDeleted means the file no longer exists.
+	StatusDeleted Status = "deleted"
+)
+
+// FileChange is the change to one file.
+type FileChange struct {
+	// Path is the absolute path of the file.
+	Path string
+	// Name is Path relative to the session's working directory when it is
+	// inside it. Diff headers use it.
+	Name   string
+	Status Status
+	// Before and After are the file's content; Before is "" for created files
+	// and After is "" for deleted ones.
+	Before string
+	After  string
+	// SymlinkBefore and SymlinkAfter report that the path was a symbolic
+	// link before or after the change; Before or After is then the link
+	// target, which Diff compares as text. Only Compare reports symlinks.
+	SymlinkBefore bool
+	SymlinkAfter  bool
+	// Diff is the unified diff of the change.
+	Diff string
+	// Added and Deleted count changed lines; both are 0 for binary files.
+	Added   int
+	Deleted int
+}
+
+// ChangeSet is the set of changes made in a turn or a session.
+type ChangeSet struct {
+	// Turn is the 0-based index of the turn, or -1 for a session change set.
+	Turn int
+	// UserMessageID is the UUID of the prompt that started the turn. Pass it to
+	// Client.RewindFiles to undo the turn when file checkpointing is enabled.
+	UserMessageID string
+	// Files are sorted by path. Files changed and then restored are omitted.
+	Files []*FileChange
+}
+
+// Diff returns the unified diff of every file in the change set.
+func (c *ChangeSet) Diff() string {
+	var b strings.Builder
+
+	for _, f := range c.Files {
+		b.WriteString(f.Diff)
+	}
+
+	return b.String()
+}
+
+// Stats returns the number of changed files and of added and deleted lines.
+func (c *ChangeSet) Stats() (files, added, deleted int) {
+	for _, f := range c.Files {
+		added += f.Added
+		deleted += f.Deleted
+	}
+
+	return len(c.Files), added, deleted
+}
+
+// Created returns the paths of created files.
+func (c *ChangeSet) Created() []string { return c.paths(StatusCreated) }
+
+// Deleted returns the paths of deleted files.
+func (c *ChangeSet) Deleted() []string { return c.paths(StatusDeleted) }
+
+func (c *ChangeSet) paths(status Status) []string {
+	var out []string
+
+	for _, f := range c.Files {
+		if f.Status == status {
+			out = append(out, f.Path)
+		}
+	}
+
+	return out
+}
+
+// content is a file's content at one point; nil means the file did not exist.
+type content = *string
+
+// fileState is a file's content before the first and after the last change.
+// For symbolic links, the content is the link target.
+type fileState struct {
+	name   string
+	before content
+	after  content
+
+	symlinkBefore bool
+	symlinkAfter  bool
+}
+
+// turnState collects the changes of one turn.
+type turnState struct {
+	userMessageID string
+	files         map[string]*fileState
+}
+
+// snapshot is a file's content before a tool call.
+type snapshot struct {
+	path   string
+	name   string
+	before content
+}
+
+// Tracker records file changes made by edit tools. It is safe for concurrent
+// use.
+type Tracker struct {
+	mu      sync.Mutex
+	cwd     string
+	pending map[string]snapshot
+	// calls maps tool use IDs to tool calls seen in messages, for rebuilding
+	// changes from tool results; recorded holds calls the hooks handled.
+	calls    map[string]*claudesdk.ToolUseBlock
+	recorded map[string]struct{}
+	turns    []*turnState
+	current  *turnState
+	session  map[string]*fileState
+}
+
+// NewTracker creates an empty Tracker.
+func NewTracker() *Tracker {
+	return &Tracker{
+		pending:  make(map[string]snapshot),
+		calls:    make(map[string]*claudesdk.ToolUseBlock),
+		recorded: make(map[string]struct{}),
+		current:  &turnState{files: make(map[string]*fileState)},
+		session:  make(map[string]*fileState),
+	}
+}
+
+// Hooks returns the PreToolUse, PostToolUse and PostToolUseFailure hooks
+// that snapshot edited files.
+func (t *Tracker) Hooks() map[claudesdk.HookEvent][]*claudesdk.HookMatcher {
+	matcher := new(strings.Join(editTools, "|"))
+
+	return map[claudesdk.HookEvent][]*claudesdk.HookMatcher{
+		claudesdk.HookEventPreToolUse: {{
+			Matcher: matcher,
+			Hooks:   []claudesdk.HookCallback{claudesdk.OnPreToolUse(t.preToolUse)},
+		}},
+		claudesdk.HookEventPostToolUse: {{
+			Matcher: matcher,
+			Hooks:   []claudesdk.HookCallback{claudesdk.OnPostToolUse(t.postToolUse)},
+		}},
+		claudesdk.HookEventPostToolUseFailure: {{
+			Matcher: matcher,
+			Hooks:   []claudesdk.HookCallback{claudesdk.OnPostToolUseFailure(t.postToolUseFailure)},
+		}},
+	}
+}
+
+// Option adds the tracker's hooks to the hooks already configured. Pass it
+// after WithHooks, which replaces the configured hooks.
+func (t *Tracker) Option() claudesdk.Option {
+	return func(o *claudesdk.ClaudeAgentOptions) {
+		hooks := maps.Clone(o.Hooks)
+		if hooks == nil {
+			hooks = make(map[claudesdk.HookEvent][]*claudesdk.HookMatcher, 3)
+		}
+
+		for event, matchers := range t.Hooks() {
+			hooks[event] = slices.Concat(hooks[event], matchers)
+		}
+
+		o.Hooks = hooks
+	}
+}
+
+// Observe follows the message stream: it records the working directory and
+// the prompt of each turn, rebuilds changes from tool results the hooks did
+// not handle, and ends the turn on a result message.
+func (t *Tracker) Observe(msg claudesdk.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch m := msg.(type) {
+	case *claudesdk.SystemMessage:
+		if cwd, ok := m.Data["cwd"].(string); ok && m.Subtype == "init" {
+			t.cwd = cwd
+		}
+	case *claudesdk.AssistantMessage:
+		for _, block := range m.Content {
+			if use, ok := block.(*claudesdk.ToolUseBlock); ok && slices.Contains(editTools, use.Name) {
+				t.calls[use.ID] = use
+			}
+		}
+	case *claudesdk.UserMessage:
+		t.observeUser(m)
+	case *claudesdk.ResultMessage:
+		t.turns = append(t.turns, t.current)
+		t.current = &turnState{files: make(map[string]*fileState)}
+		clear(t.pending)
+		clear(t.calls)
+		clear(t.recorded)
+	}
+}
+
+// Current returns the changes of the turn in progress.
+func (t *Tracker) Current() *ChangeSet {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.current.changeSet(len(t.turns))
+}
+
+// LastTurn returns the changes of the last completed turn, or an empty
+// change set before the first turn ends.
+func (t *Tracker) LastTurn() *ChangeSet {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.turns) == 0 {
+		return &ChangeSet{Turn: -1}
+	}
+
+	return t.turns[len(t.turns)-1].changeSet(len(t.turns) - 1)
+}
+
+// Turns returns the changes of each completed turn, indexed by turn.
+func (t *Tracker) Turns() []*ChangeSet {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make([]*ChangeSet, len(t.turns))
+	for i, turn := range t.turns {
+		out[i] = turn.changeSet(i)
+	}
+
+	return out
+}
+
+// Session returns every change made in the session, comparing each file
+// before its first change with its content after the last one.
+func (t *Tracker) Session() *ChangeSet {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return buildChangeSet(-1, "", t.session)
+}
+
+// Sync re-reads every tracked file, so the session change set reflects the
+// files as they are now. Call it after Client.RewindFiles restores files.
+func (t *Tracker) Sync() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for path, state := range t.session {
+		after, err := readFile(path)
+		if err != nil {
+			return err
+		}
+
+		state.after = after
+	}
+
+	return nil
+}
+
+func (t *Tracker) preToolUse(
+	_ context.Context,
+	input *claudesdk.PreToolUseHookInput,
+) (claudesdk.PreToolUseHookDecision, error) {
+	path := toolPath(input.ToolName, input.ToolInput, input.Cwd)
+	if path == "" {
+		return claudesdk.PreToolUseHookDecision{}, nil
+	}
+
+	// Tracking is best effort: an unreadable file must not block the tool
+	before, err := readFile(path)
+	if err != nil {
+		return claudesdk.PreToolUseHookDecision{}, nil //nolint:nilerr // see above
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pending[input.ToolUseID] = snapshot{path: path, name: displayName(path, input.Cwd), before: before}
+
+	return claudesdk.PreToolUseHookDecision{}, nil
+}
+
+func (t *Tracker) postToolUse(
+	_ context.Context,
+	input *claudesdk.PostToolUseHookInput,
+) (claudesdk.PostToolUseHookDecision, error) {
+	t.mu.Lock()
+	snap, ok := t.pending[input.ToolUseID]
+	delete(t.pending, input.ToolUseID)
+	t.mu.Unlock()
+
+	if !ok {
+		return claudesdk.PostToolUseHookDecision{}, nil
+	}
+
+	after, err := readFile(snap.path)
+	if err != nil {
+		return claudesdk.PostToolUseHookDecision{}, nil //nolint:nilerr // tracking is best effort
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.recorded[input.ToolUseID] = struct{}{}
+	t.record(snap.path, snap.name, snap.before, after)
+
+	return claudesdk.PostToolUseHookDecision{}, nil
+}
+
+func (t *Tracker) postToolUseFailure(
+	_ context.Context,
+	input *claudesdk.PostToolUseFailureHookInput,
+) (claudesdk.PostToolUseFailureHookDecision, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.pending, input.ToolUseID)
+
+	return claudesdk.PostToolUseFailureHookDecision{}, nil
+}
+
+// observeUser notes the prompt starting a turn and rebuilds the changes of
+// tool results the hooks did not record.
+func (t *Tracker) observeUser(m *claudesdk.UserMessage) {
+	var results []*claudesdk.ToolResultBlock
+
+	for _, block := range m.Content.Blocks() {
+		if result, ok := block.(*claudesdk.ToolResultBlock); ok {
+			results = append(results, result)
+		}
+	}
+
+	if len(results) == 0 {
+		if t.current.userMessageID == "" && m.UUID != nil && m.ParentToolUseID == nil {
+			t.current.userMessageID = *m.UUID
+		}
+
+		return
+	}
+
+	// ToolUseResult describes the message's only tool result
+	if len(results) != 1 || results[0].IsError {
+		return
+	}
+
+	id := results[0].ToolUseID
+
+	use, ok := t.calls[id]
+	if _, done := t.recorded[id]; done || !ok {
+		return
+	}
+
+	t.recorded[id] = struct{}{}
+
+	result, err := m.DecodeToolUseResult(use.Name)
+	if err != nil || result == nil {
+		return
+	}
+
+	var (
+		path          string
+		before, after content
+	)
+
+	switch r := result.(type) {
+	case *claudesdk.WriteResult:
+		path, before, after = r.FilePath, r.OriginalFile, new(r.Content)
+	case *claudesdk.EditResult:
+		path, before = r.FilePath, new(r.OriginalFile)
+		after = new(replace(r.OriginalFile, claudesdk.EditOperation{
+			OldString: r.OldString, NewString: r.NewString, ReplaceAll: r.ReplaceAll,
+		}))
+	case *claudesdk.MultiEditResult:
+		path, before = r.FilePath, new(r.OriginalFileContents)
+
+		text := r.OriginalFileContents
+		for _, op := range r.Edits {
+			text = replace(text, op)
+		}
+
+		after = new(text)
+	default:
+		return
+	}
+
+	t.record(path, displayName(path, t.cwd), before, after)
+}
+
+// record adds a change to the current turn and the session.
+func (t *Tracker) record(path, name string, before, after content) {
+	if state, ok := t.current.files[path]; ok {
+		state.after = after
+	} else {
+		t.current.files[path] = &fileState{name: name, before: before, after: after}
+	}
+
+	if state, ok := t.session[path]; ok {
+		state.after = after
+	} else {
+		t.session[path] = &fileState{name: name, before: before, after: after}
+	}
+}
+
+func (s *turnState) changeSet(turn int) *ChangeSet {
+	return buildChangeSet(turn, s.userMessageID, s.files)
+}
+
+func buildChangeSet(turn int, userMessageID string, files map[string]*fileState) *ChangeSet {
+	set := &ChangeSet{Turn: turn, UserMessageID: userMessageID}
+
+	for _, path := range slices.Sorted(maps.Keys(files)) {
+		state := files[path]
+		if equal(state.before, state.after) && state.symlinkBefore == state.symlinkAfter {
+			continue
+		}
+
+		change := &FileChange{
+			Path: path, Name: state.name, Status: StatusModified,
+			SymlinkBefore: state.symlinkBefore, SymlinkAfter: state.symlinkAfter,
+		}
+
+		switch {
+		case state.before == nil:
+			change.Status = StatusCreated
+		case state.after == nil:
+			change.Status = StatusDeleted
+		}
+
+		if state.before != nil {
+			change.Before = *state.before
+		}
+
+		if state.after != nil {
+			change.After = *state.after
+		}
+
+		change.Diff, change.Added, change.Deleted = unifiedDiff(state.name, state.before, state.after)
+		set.Files = append(set.Files, change)
+	}
+
+	return set
+}
+
+// toolPath returns the absolute path of the file an edit tool changes.
+func toolPath(toolName string, input map[string]any, cwd string) string {
+	key := "file_path"
+	if toolName == claudesdk.ToolNotebookEdit {
+		key = "notebook_path"
+	}
+
+	path, _ := input[key].(string)
+	if path == "" {
+		return ""
+	}
+
+	if !filepath.IsAbs(path) && cwd != "" {
+		path = filepath.Join(cwd, path)
+	}
+
+	return filepath.Clean(path)
+}
+
+// displayName returns path relative to cwd when it is inside it.
+func displayName(path, cwd string) string {
+	if cwd != "" {
+		if rel, err := filepath.Rel(cwd, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+
+	return strings.TrimPrefix(filepath.ToSlash(path), "/")
+}
+
+// readFile returns a file's content, or nil if it does not exist.
+func readFile(path string) (content, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if stderrors.Is(err, fs.ErrNotExist) {
+			return nil, nil //nolint:nilnil // a missing file has no content
+		}
+
+		return nil, fmt.Errorf("snapshot %s: %w", path, err)
+	}
+
+	return new(string(data)), nil
+}
+
+// replace applies one Edit replacement the way the Edit tool does.
+func replace(text string, op claudesdk.EditOperation) string {
+	if op.ReplaceAll {
+		return strings.ReplaceAll(text, op.OldString, op.NewString)
+	}
+
+	return strings.Replace(text, op.OldString, op.NewString, 1)
+}
+
+func equal(a, b content) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
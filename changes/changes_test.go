@@ -0,0 +1,237 @@
+package changes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	claudesdk "github.com/wagiedev/claude-agent-sdk-go"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"
+	after := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\nn"
+
+	diff, added, deleted := unifiedDiff("x.txt", &before, &after)
+	assert.Equal(t, `--- a/x.txt
++++ b/x.txt
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -11,3 +11,4 @@
+ k
+ l
+ m
++n
+\ No newline at end of file
+`, diff)
+	assert.Equal(t, 2, added)
+	assert.Equal(t, 1, deleted)
+
+	diff, added, _ = unifiedDiff("new.go", nil, new("package x\n"))
+	assert.Equal(t, "--- /dev/null\n+++ b/new.go\n@@ -0,0 +1 @@\n+package x\n", diff)
+	assert.Equal(t, 1, added)
+
+	diff, _, deleted = unifiedDiff("old.go", new("x\ny\n"), nil)
+	assert.Equal(t, "--- a/old.go\n+++ /dev/null\n@@ -1,2 +0,0 @@\n-x\n-y\n", diff)
+	assert.Equal(t, 2, deleted)
+
+	diff, added, _ = unifiedDiff("bin", new("\x00"), new("\x01\x00"))
+	assert.Contains(t, diff, "Binary files a/bin and b/bin differ")
+	assert.Zero(t, added)
+}
+
+func TestDiffLines(t *testing.T) {
+	a := lines("x\ny\nz\nx\ny\n")
+	b := lines("y\nz\nq\nx\n")
+
+	var rebuilt []string
+
+	for _, e := range diffLines(a, b) {
+		if e.kind != '-' {
+			rebuilt = append(rebuilt, e.line)
+		}
+	}
+
+	assert.Equal(t, b, rebuilt)
+}
+
+func TestDiffLines_Large(t *testing.T) {
+	var created, other strings.Builder
+
+	for i := range 8000 {
+		fmt.Fprintf(&created, "line %d\n", i)
+		fmt.Fprintf(&other, "other %d\n", i)
+	}
+
+	allocs := testing.AllocsPerRun(1, func() {
+		script := diffLines(nil, lines(created.String()))
+		require.Len(t, script, 8000)
+		assert.Equal(t, byte('+'), script[0].kind)
+	})
+	assert.Less(t, allocs, 100.0, "a created file is diffed without a search")
+
+	// Beyond maxEditDistance the changed range is replaced as a whole
+	a, b := lines(created.String()), lines(other.String())
+	script := diffLines(a, b)
+	require.Len(t, script, len(a)+len(b))
+	assert.Equal(t, edit{'-', "line 0\n"}, script[0])
+	assert.Equal(t, edit{'+', "other 0\n"}, script[len(a)])
+}
+
+func TestTrackerHooks(t *testing.T) {
+	dir := t.TempDir()
+	edited := filepath.Join(dir, "main.go")
+	require.NoError(t, os.WriteFile(edited, []byte("package main\n"), 0o600))
+
+	tracker := NewTracker()
+
+	run := func(id, tool, path string, apply func()) {
+		t.Helper()
+
+		input := map[string]any{"file_path": path}
+		base := claudesdk.BaseHookInput{Cwd: dir}
+
+		_, err := tracker.preToolUse(t.Context(), &claudesdk.PreToolUseHookInput{
+			BaseInput: base, ToolName: tool, ToolInput: input, ToolUseID: id,
+		})
+		require.NoError(t, err)
+
+		apply()
+
+		_, err = tracker.postToolUse(t.Context(), &claudesdk.PostToolUseHookInput{
+			BaseInput: base, ToolName: tool, ToolInput: input, ToolUseID: id,
+		})
+		require.NoError(t, err)
+	}
+
+	tracker.Observe(&claudesdk.UserMessage{UUID: new("prompt-1"), Content: claudesdk.NewUserMessageContent("go")})
+
+	run("t1", claudesdk.ToolEdit, edited, func() {
+		require.NoError(t, os.WriteFile(edited, []byte("package main\n\nfunc main() {}\n"), 0o600))
+	})
+	run("t2", claudesdk.ToolWrite, "notes.md", func() {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.md"), []byte("hi\n"), 0o600))
+	})
+
+	current := tracker.Current()
+	require.Len(t, current.Files, 2)
+	assert.Equal(t, "prompt-1", current.UserMessageID)
+
+	tracker.Observe(&claudesdk.ResultMessage{Subtype: "success"})
+
+	turn := tracker.LastTurn()
+	assert.Equal(t, 0, turn.Turn)
+	assert.Equal(t, []string{filepath.Join(dir, "notes.md")}, turn.Created())
+
+	files, added, deleted := turn.Stats()
+	assert.Equal(t, 2, files)
+	assert.Equal(t, 3, added)
+	assert.Zero(t, deleted)
+	assert.Contains(t, turn.Diff(), "--- a/main.go\n+++ b/main.go\n")
+
+	run("t3", claudesdk.ToolEdit, edited, func() {
+		require.NoError(t, os.WriteFile(edited, []byte("package main\n"), 0o600))
+	})
+	tracker.Observe(&claudesdk.ResultMessage{Subtype: "success"})
+
+	require.Len(t, tracker.Turns(), 2)
+	assert.Len(t, tracker.Turns()[1].Files, 1)
+
+	session := tracker.Session()
+	require.Len(t, session.Files, 1, "files restored to their original content are omitted")
+	assert.Equal(t, StatusCreated, session.Files[0].Status)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "notes.md")))
+	require.NoError(t, tracker.Sync())
+	assert.Empty(t, tracker.Session().Files, "Sync picks up rewound files")
+}
+
+func TestTrackerMessages(t *testing.T) {
+	tracker := NewTracker()
+
+	tracker.Observe(&claudesdk.SystemMessage{Subtype: "init", Data: map[string]any{"cwd": "/work"}})
+	tracker.Observe(&claudesdk.AssistantMessage{Content: []claudesdk.ContentBlock{
+		&claudesdk.ToolUseBlock{ID: "t1", Name: claudesdk.ToolEdit},
+	}})
+	tracker.Observe(&claudesdk.UserMessage{
+		Content: claudesdk.NewUserMessageContentBlocks([]claudesdk.ContentBlock{
+			&claudesdk.ToolResultBlock{ToolUseID: "t1"},
+		}),
+		ToolUseResult: map[string]any{
+			"filePath": "/work/pkg/a.go", "oldString": "old", "newString": "new",
+			"originalFile": "x := old\ny := old\n",
+		},
+	})
+	tracker.Observe(&claudesdk.ResultMessage{Subtype: "success"})
+
+	turn := tracker.LastTurn()
+	require.Len(t, turn.Files, 1)
+
+	change := turn.Files[0]
+	assert.Equal(t, "pkg/a.go", change.Name)
+	assert.Equal(t, StatusModified, change.Status)
+	assert.Equal(t, "x := new\ny := old\n", change.After)
+	assert.True(t, strings.HasPrefix(change.Diff, "--- a/pkg/a.go\n"))
+}
+
+func TestCompare(t *testing.T) {
+	before, after := t.TempDir(), t.TempDir()
+
+	write := func(dir, name, data string) {
+		t.Helper()
+
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+	}
+
+	write(before, "same.txt", "same\n")
+	write(after, "same.txt", "same\n")
+	write(before, "pkg/edit.go", "a\n")
+	write(after, "pkg/edit.go", "b\n")
+	write(before, "gone.txt", "x\n")
+	write(after, "new.txt", "y\n")
+	write(after, ".git/HEAD", "ref: refs/heads/main\n")
+
+	set, err := Compare(before, after)
+	require.NoError(t, err)
+	require.Len(t, set.Files, 3)
+
+	assert.Equal(t, []string{filepath.Join(before, "new.txt")}, set.Created())
+	assert.Equal(t, []string{filepath.Join(before, "gone.txt")}, set.Deleted())
+	assert.Contains(t, set.Diff(), "--- a/pkg/edit.go\n+++ b/pkg/edit.go\n@@ -1 +1 @@\n-a\n+b\n")
+}
+
+func TestCompare_Symlinks(t *testing.T) {
+	before, after := t.TempDir(), t.TempDir()
+
+	require.NoError(t, os.Symlink("a.txt", filepath.Join(before, "same")))
+	require.NoError(t, os.Symlink("a.txt", filepath.Join(after, "same")))
+	require.NoError(t, os.Symlink("a.txt", filepath.Join(before, "moved")))
+	require.NoError(t, os.Symlink("b.txt", filepath.Join(after, "moved")))
+	require.NoError(t, os.Symlink("/etc/passwd", filepath.Join(after, "new")))
+
+	set, err := Compare(before, after)
+	require.NoError(t, err)
+	require.Len(t, set.Files, 2)
+
+	moved, created := set.Files[0], set.Files[1]
+	assert.Equal(t, StatusModified, moved.Status)
+	assert.True(t, moved.SymlinkBefore)
+	assert.True(t, moved.SymlinkAfter)
+	assert.Equal(t, "b.txt", moved.After)
+
+	assert.Equal(t, StatusCreated, created.Status)
+	assert.True(t, created.SymlinkAfter)
+	assert.Equal(t, "/etc/passwd", created.After)
+}
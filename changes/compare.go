@@ -0,0 +1,169 @@
+package changes
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+)
+
+// Compare returns the changes that turn the tree in before into the tree in
+// after, such as a copy of a workspace an agent worked in. Paths are in
+// before and names are relative to it. Regular files and symbolic links are
+// compared, symbolic links by their target, and .git directories are skipped.
+func Compare(before, after string) (*ChangeSet, error) {
+	oldFiles, err := treeFiles(before)
+	if err != nil {
+		return nil, err
+	}
+
+	newFiles, err := treeFiles(after)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*fileState)
+
+	names := maps.Clone(oldFiles)
+	maps.Copy(names, newFiles)
+
+	// Only changed files are read in full, so large unchanged trees stay cheap
+	for name := range names {
+		oldPath, newPath := filepath.Join(before, name), filepath.Join(after, name)
+
+		oldLink, inOld := oldFiles[name]
+		newLink, inNew := newFiles[name]
+
+		if inOld && inNew && oldLink == newLink {
+			same, err := sameEntry(oldPath, newPath, oldLink)
+			if err != nil {
+				return nil, fmt.Errorf("compare %s: %w", name, err)
+			}
+
+			if same {
+				continue
+			}
+		}
+
+		old, err := readEntry(oldPath, inOld, oldLink)
+		if err != nil {
+			return nil, err
+		}
+
+		updated, err := readEntry(newPath, inNew, newLink)
+		if err != nil {
+			return nil, err
+		}
+
+		files[oldPath] = &fileState{
+			name: filepath.ToSlash(name), before: old, after: updated,
+			symlinkBefore: oldLink, symlinkAfter: newLink,
+		}
+	}
+
+	return buildChangeSet(-1, "", files), nil
+}
+
+// treeFiles returns the regular files and symbolic links under root,
+// relative to it, mapped to whether they are symbolic links.
+func treeFiles(root string) (map[string]bool, error) {
+	files := make(map[string]bool)
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		symlink := d.Type()&fs.ModeSymlink != 0
+		if !symlink && !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		files[rel] = symlink
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk %s: %w", root, err)
+	}
+
+	return files, nil
+}
+
+// readEntry returns the content of a file, or the target of a symbolic
+// link. It returns nil if the entry is not in its tree.
+func readEntry(path string, exists, symlink bool) (content, error) {
+	switch {
+	case !exists:
+		return nil, nil //nolint:nilnil // a missing file has no content
+	case symlink:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot %s: %w", path, err)
+		}
+
+		return &target, nil
+	default:
+		return readFile(path)
+	}
+}
+
+// sameEntry reports whether two files have the same content, or two
+// symbolic links the same target.
+func sameEntry(a, b string, symlink bool) (bool, error) {
+	if !symlink {
+		return sameFile(a, b)
+	}
+
+	targetA, err := os.Readlink(a)
+	if err != nil {
+		return false, err
+	}
+
+	targetB, err := os.Readlink(b)
+	if err != nil {
+		return false, err
+	}
+
+	return targetA == targetB, nil
+}
+
+// sameFile reports whether two files have the same content.
+func sameFile(a, b string) (bool, error) {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+
+	if infoA.Size() != infoB.Size() {
+		return false, nil
+	}
+
+	dataA, err := os.ReadFile(a)
+	if err != nil {
+		return false, err
+	}
+
+	dataB, err := os.ReadFile(b)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(dataA, dataB), nil
+}
@@ -0,0 +1,294 @@
+package changes
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around each change.
+const contextLines = 3
+
+// edit is one line of an edit script.
+type edit struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// lines splits s into lines, keeping line terminators.
+func lines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	out := strings.SplitAfter(s, "\n")
+	if out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+
+	return out
+}
+
+// diffLines returns an edit script turning a into b, using Myers' algorithm
+// after trimming the common prefix and suffix. The script is the shortest
+// unless the change exceeds maxEditDistance.
+func diffLines(a, b []string) []edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	script := make([]edit, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		script = append(script, edit{' ', line})
+	}
+
+	script = append(script, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+
+	for _, line := range a[len(a)-suffix:] {
+		script = append(script, edit{' ', line})
+	}
+
+	return script
+}
+
+// maxEditDistance bounds the edit scripts myers searches for. Larger changes
+// are shown as replacing the whole changed range, which keeps time and memory
+// bounded when a tool rewrites a large file.
+const maxEditDistance = 1000
+
+// myers returns the shortest edit script turning a into b, or a whole-range
+// replacement when it is longer than maxEditDistance.
+func myers(a, b []string) []edit {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return replaceAll(a, b)
+	}
+
+	limit := min(n+m, maxEditDistance)
+	offset := limit + 1
+	v := make([]int, 2*offset+1)
+
+	// trace[d] holds v[-d..d] as it was before step d, all backtracking needs
+	var (
+		trace [][]int
+		found bool
+	)
+
+search:
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, slices.Clone(v[offset-d:offset+d+1]))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				found = true
+
+				break search
+			}
+		}
+	}
+
+	if !found {
+		return replaceAll(a, b)
+	}
+
+	var script []edit
+
+	x, y := n, m
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		// at returns v[k] before step d
+		at := func(k int) int { return trace[d][k+d] }
+		k := x - y
+
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+
+		prevX := 0
+		if d > 0 {
+			prevX = at(prevK)
+		}
+
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			script = append(script, edit{' ', a[x-1]})
+			x--
+			y--
+		}
+
+		if d == 0 {
+			break
+		}
+
+		if x == prevX {
+			script = append(script, edit{'+', b[y-1]})
+			y--
+		} else {
+			script = append(script, edit{'-', a[x-1]})
+			x--
+		}
+	}
+
+	slices.Reverse(script)
+
+	return script
+}
+
+// replaceAll returns the edit script that deletes every line of a and then
+// adds every line of b.
+func replaceAll(a, b []string) []edit {
+	script := make([]edit, 0, len(a)+len(b))
+
+	for _, line := range a {
+		script = append(script, edit{'-', line})
+	}
+
+	for _, line := range b {
+		script = append(script, edit{'+', line})
+	}
+
+	return script
+}
+
+// unifiedDiff returns the unified diff of before and after, with the counts
+// of added and deleted lines. An empty side is shown as /dev/null.
+func unifiedDiff(path string, before, after *string) (diff string, added, deleted int) {
+	oldName, newName := "a/"+path, "b/"+path
+
+	var oldText, newText string
+
+	if before == nil {
+		oldName = "/dev/null"
+	} else {
+		oldText = *before
+	}
+
+	if after == nil {
+		newName = "/dev/null"
+	} else {
+		newText = *after
+	}
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", oldName, newName)
+
+	if isBinary(oldText) || isBinary(newText) {
+		fmt.Fprintf(&buf, "Binary files %s and %s differ\n", oldName, newName)
+
+		return buf.String(), 0, 0
+	}
+
+	script := diffLines(lines(oldText), lines(newText))
+
+	for _, e := range script {
+		switch e.kind {
+		case '+':
+			added++
+		case '-':
+			deleted++
+		}
+	}
+
+	writeHunks(&buf, script)
+
+	return buf.String(), added, deleted
+}
+
+// writeHunks writes the changes of script as unified diff hunks.
+func writeHunks(buf *bytes.Buffer, script []edit) {
+	// oldAt and newAt are the 1-based line numbers before each edit
+	oldAt := make([]int, len(script)+1)
+	newAt := make([]int, len(script)+1)
+	oldAt[0], newAt[0] = 1, 1
+
+	for i, e := range script {
+		oldAt[i+1], newAt[i+1] = oldAt[i], newAt[i]
+
+		if e.kind != '+' {
+			oldAt[i+1]++
+		}
+
+		if e.kind != '-' {
+			newAt[i+1]++
+		}
+	}
+
+	for i := 0; i < len(script); {
+		if script[i].kind == ' ' {
+			i++
+
+			continue
+		}
+
+		start := max(0, i-contextLines)
+		end := i
+
+		// Extend the hunk while the next change is close enough to share context
+		for j := i; j < len(script); j++ {
+			if script[j].kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*contextLines {
+				break
+			}
+		}
+
+		end = min(len(script), end+contextLines)
+
+		oldLen, newLen := oldAt[end]-oldAt[start], newAt[end]-newAt[start]
+		fmt.Fprintf(buf, "@@ -%s +%s @@\n", hunkRange(oldAt[start], oldLen), hunkRange(newAt[start], newLen))
+
+		for _, e := range script[start:end] {
+			buf.WriteByte(e.kind)
+			buf.WriteString(e.line)
+
+			if !strings.HasSuffix(e.line, "\n") {
+				buf.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		i = end
+	}
+}
+
+// hunkRange formats a hunk's line range. Empty ranges name the line before
+// them, as diff does.
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+
+	if length == 1 {
+		return fmt.Sprint(start)
+	}
+
+	return fmt.Sprintf("%d,%d", start, length)
+}
+
+func isBinary(s string) bool {
+	return strings.IndexByte(s[:min(len(s), 8000)], 0) >= 0
+}
@@ -0,0 +1,24 @@
+package claudesdk
+
+import "github.com/wagiedev/claude-agent-sdk-go/internal/checkpoint"
+
+// CheckpointRegistry records a checkpoint for each prompt of a session. Pass
+// it to WithCheckpoints to list checkpoints and undo turns with
+// Client.UndoLastTurn:
+//
+//	checkpoints := claudesdk.NewCheckpointRegistry()
+//	client.Start(ctx, claudesdk.WithCheckpoints(checkpoints))
+//	// ...
+//	for _, cp := range checkpoints.List() {
+//	    fmt.Println(cp.Time.Format(time.Kitchen), cp.Prompt)
+//	}
+type CheckpointRegistry = checkpoint.Registry
+
+// Checkpoint is the state of a session just before a prompt was sent. Its
+// UserMessageID can be passed to Client.RewindFiles.
+type Checkpoint = checkpoint.Checkpoint
+
+// NewCheckpointRegistry creates an empty checkpoint registry.
+func NewCheckpointRegistry() *CheckpointRegistry {
+	return checkpoint.New()
+}
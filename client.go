@@ -103,14 +103,38 @@ type Client interface {
 	// Returns the status of all configured MCP servers.
 	GetMCPStatus(ctx context.Context) (*MCPStatus, error)
 
+	// AddHook registers a hook matcher for event on the running session and
+	// returns an ID for RemoveHook. Requires a client started with
+	// WithHookRegistry whose registry includes event.
+	AddHook(event HookEvent, matcher *HookMatcher) (string, error)
+
+	// RemoveHook unregisters a hook added with AddHook or HookRegistry.Add.
+	// It returns false if no hook with the ID is registered.
+	RemoveHook(id string) (bool, error)
+
 	// RewindFiles rewinds tracked files to their state at a specific user message.
 	// The userMessageID should be the ID of a previous user message in the conversation.
 	// Requires EnableFileCheckpointing=true in ClaudeAgentOptions.
 	RewindFiles(ctx context.Context, userMessageID string) error
 
+	// UndoLastTurn rolls the conversation back to before the last prompt. It
+	// rewinds files to the last checkpoint and continues in a fork of the
+	// session that ends at the assistant message before that prompt, so the
+	// files and the conversation agree. Pending ReceiveMessages iterators end.
+	// Requires WithCheckpoints; returns ErrNoCheckpoint when there is no
+	// prompt to undo. ctx bounds the undo, not the forked session. If the
+	// fork fails after files were rewound, the error wraps ErrUndoIncomplete,
+	// the checkpoint is returned, and the client stays on the old session.
+	UndoLastTurn(ctx context.Context) (*Checkpoint, error)
+
 	// Close terminates the session and cleans up resources.
 	// After Close(), the client cannot be reused. Safe to call multiple times.
 	Close() error
+
+	// CloseContext is like Close, but sends the CLI SIGTERM and waits for it
+	// to exit until ctx is done before killing it and the commands it
+	// started.
+	CloseContext(ctx context.Context) error
 }
 
 // NewClient creates a new interactive client.
@@ -3,6 +3,7 @@ package claudesdk
 import (
 	"context"
 	"iter"
+	"sync"
 
 	"github.com/wagiedev/claude-agent-sdk-go/internal/client"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/config"
@@ -11,7 +12,10 @@ import (
 
 // clientWrapper wraps the internal client to adapt it to the public interface.
 type clientWrapper struct {
-	impl *client.Client
+	// mu guards impl, which UndoLastTurn replaces with a forked session.
+	mu     sync.RWMutex
+	impl   *client.Client
+	closed bool
 }
 
 // Compile-time check that *clientWrapper implements the Client interface.
@@ -22,14 +26,22 @@ func newClientImpl() Client {
 	return &clientWrapper{impl: client.New()}
 }
 
+// current returns the client of the active session.
+func (c *clientWrapper) current() *client.Client {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.impl
+}
+
 // Start establishes a connection to the Claude CLI.
 func (c *clientWrapper) Start(ctx context.Context, opts ...Option) error {
-	return c.impl.Start(ctx, applyAgentOptionsToConfig(opts))
+	return c.current().Start(ctx, applyAgentOptionsToConfig(opts))
 }
 
 // StartWithPrompt establishes a connection and immediately sends an initial prompt.
 func (c *clientWrapper) StartWithPrompt(ctx context.Context, prompt string, opts ...Option) error {
-	return c.impl.StartWithPrompt(ctx, prompt, applyAgentOptionsToConfig(opts))
+	return c.current().StartWithPrompt(ctx, prompt, applyAgentOptionsToConfig(opts))
 }
 
 // StartWithStream establishes a connection and streams initial messages.
@@ -47,57 +59,109 @@ func (c *clientWrapper) StartWithStream(
 		}
 	}
 
-	return c.impl.StartWithStream(ctx, convertedMessages, applyAgentOptionsToConfig(opts))
+	return c.current().StartWithStream(ctx, convertedMessages, applyAgentOptionsToConfig(opts))
 }
 
 // Query sends a user prompt to Claude.
 func (c *clientWrapper) Query(ctx context.Context, prompt string, sessionID ...string) error {
-	return c.impl.Query(ctx, prompt, sessionID...)
+	return c.current().Query(ctx, prompt, sessionID...)
 }
 
 // ReceiveMessages returns an iterator that yields messages indefinitely.
 func (c *clientWrapper) ReceiveMessages(ctx context.Context) iter.Seq2[Message, error] {
-	return c.impl.ReceiveMessages(ctx)
+	return c.current().ReceiveMessages(ctx)
 }
 
 // ReceiveResponse returns an iterator that yields messages until a ResultMessage is received.
 func (c *clientWrapper) ReceiveResponse(ctx context.Context) iter.Seq2[Message, error] {
-	return c.impl.ReceiveResponse(ctx)
+	return c.current().ReceiveResponse(ctx)
 }
 
 // Interrupt sends an interrupt signal to stop Claude's current processing.
 func (c *clientWrapper) Interrupt(ctx context.Context) error {
-	return c.impl.Interrupt(ctx)
+	return c.current().Interrupt(ctx)
 }
 
 // SetPermissionMode changes the permission mode during conversation.
 func (c *clientWrapper) SetPermissionMode(ctx context.Context, mode string) error {
-	return c.impl.SetPermissionMode(ctx, mode)
+	return c.current().SetPermissionMode(ctx, mode)
 }
 
 // SetModel changes the AI model during conversation.
 func (c *clientWrapper) SetModel(ctx context.Context, model *string) error {
-	return c.impl.SetModel(ctx, model)
+	return c.current().SetModel(ctx, model)
 }
 
 // GetServerInfo returns server initialization info including available commands.
 func (c *clientWrapper) GetServerInfo() map[string]any {
-	return c.impl.GetServerInfo()
+	return c.current().GetServerInfo()
 }
 
 // GetMCPStatus queries the CLI for live MCP server connection status.
 func (c *clientWrapper) GetMCPStatus(ctx context.Context) (*MCPStatus, error) {
-	return c.impl.GetMCPStatus(ctx)
+	return c.current().GetMCPStatus(ctx)
+}
+
+// AddHook registers a hook matcher for event on the running session.
+func (c *clientWrapper) AddHook(event HookEvent, matcher *HookMatcher) (string, error) {
+	return c.current().AddHook(event, matcher)
+}
+
+// RemoveHook unregisters a hook added with AddHook.
+func (c *clientWrapper) RemoveHook(id string) (bool, error) {
+	return c.current().RemoveHook(id)
 }
 
 // RewindFiles rewinds tracked files to their state at a specific user message.
 func (c *clientWrapper) RewindFiles(ctx context.Context, userMessageID string) error {
-	return c.impl.RewindFiles(ctx, userMessageID)
+	return c.current().RewindFiles(ctx, userMessageID)
+}
+
+// UndoLastTurn rewinds files and forks the session to before the last prompt.
+func (c *clientWrapper) UndoLastTurn(ctx context.Context) (*Checkpoint, error) {
+	prev := c.current()
+
+	next, cp, err := prev.UndoLastTurn(ctx)
+	if err != nil {
+		// cp is set when files were rewound but the fork failed
+		return cp, err
+	}
+
+	c.mu.Lock()
+
+	if c.closed || c.impl != prev {
+		c.mu.Unlock()
+		_ = next.Close()
+
+		return nil, ErrClientClosed
+	}
+
+	c.impl = next
+	c.mu.Unlock()
+
+	// The forked session replaces the old one, whose shutdown errors no
+	// longer affect the caller
+	_ = prev.Close()
+
+	return cp, nil
 }
 
 // Close terminates the session and cleans up resources.
 func (c *clientWrapper) Close() error {
-	return c.impl.Close()
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+
+	return c.current().Close()
+}
+
+// CloseContext terminates the session, waiting for the CLI to exit until ctx is done.
+func (c *clientWrapper) CloseContext(ctx context.Context) error {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+
+	return c.current().CloseContext(ctx)
 }
 
 // applyAgentOptionsToConfig converts public options to internal config.Options.
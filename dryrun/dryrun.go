@@ -0,0 +1,433 @@
+// Package dryrun previews what an agent would do to a workspace without
+// letting it touch the workspace.
+//
+// Run mirrors the working directory into a scratch copy, runs the agent
+// there, and returns the changes as a patch against the original tree along
+// with the commands the agent executed. The original is only changed if the
+// caller applies the preview:
+//
+//	preview, err := dryrun.Run(ctx, ".", untrustedPrompt)
+//	defer preview.Close()
+//	fmt.Print(preview.Patch)
+//	for _, cmd := range preview.Commands {
+//	    fmt.Println("$", cmd.Command)
+//	}
+//	if approved {
+//	    err = preview.Apply()
+//	}
+//
+// Edit tools are denied for paths outside the scratch copy, including paths
+// that leave it through a symbolic link. Commands are kept in it by the CLI's
+// sandbox, which Run enables unless the options configure one: sandboxed
+// commands can only write under their working directory, the scratch copy,
+// and cannot fall back to running unsandboxed. The sandbox needs a platform
+// the CLI supports it on (macOS and Linux). Sandbox settings passed to Run
+// replace the default; settings that disable it let commands change the
+// original workspace, so never pass them with an untrusted prompt.
+package dryrun
+
+import (
+	"cmp"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	claudesdk "github.com/wagiedev/claude-agent-sdk-go"
+	"github.com/wagiedev/claude-agent-sdk-go/changes"
+)
+
+// ErrConflict indicates the original workspace changed after the preview
+// was made, so applying it would overwrite those changes.
+var ErrConflict = stderrors.New("workspace changed since preview")
+
+// editTools are the tools confined to the scratch copy.
+var editTools = []string{
+	claudesdk.ToolWrite, claudesdk.ToolEdit, claudesdk.ToolMultiEdit, claudesdk.ToolNotebookEdit,
+}
+
+// Command is a shell command the agent executed.
+type Command struct {
+	// ToolUseID identifies the Bash tool call.
+	ToolUseID string
+	// Command is the command line.
+	Command string
+	// Description is the agent's description of the command.
+	Description string
+	// Output is the command's output as reported to the agent.
+	Output string
+	// IsError reports whether the command failed or was denied.
+	IsError bool
+}
+
+// Preview is the outcome of a dry run.
+type Preview struct {
+	// Dir is the original working directory.
+	Dir string
+	// Scratch is the copy of Dir the agent worked in. It is removed by Close.
+	Scratch string
+	// Changes are the changes to apply to Dir, with paths in Dir.
+	Changes *changes.ChangeSet
+	// Patch is the unified diff of Changes, applicable with git apply or
+	// patch -p1 in Dir. Symbolic link changes appear as changes to the link
+	// target; only Apply recreates them as links.
+	Patch string
+	// Commands are the shell commands the agent executed, in order.
+	Commands []Command
+	// Messages are the messages of the query.
+	Messages []claudesdk.Message
+	// Result is the query's result message, if it finished.
+	Result *claudesdk.ResultMessage
+
+	root string
+}
+
+// Run mirrors dir into a scratch directory and runs prompt there. The
+// Preview is returned whenever the scratch copy was made, even if the query
+// failed; call Close to remove the copy.
+func Run(ctx context.Context, dir, prompt string, opts ...claudesdk.Option) (*Preview, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve workspace: %w", err)
+	}
+
+	root, err := os.MkdirTemp("", "claude-dryrun-")
+	if err != nil {
+		return nil, fmt.Errorf("create scratch directory: %w", err)
+	}
+
+	// The CLI reports resolved paths, which must match Scratch
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+
+	// Keep the workspace's name, which the agent may rely on
+	p := &Preview{Dir: dir, Scratch: filepath.Join(root, filepath.Base(dir)), root: root}
+
+	if err := mirror(dir, p.Scratch); err != nil {
+		_ = os.RemoveAll(root)
+
+		return nil, err
+	}
+
+	opts = slices.Concat(opts, []claudesdk.Option{p.confine(), sandboxed(), claudesdk.WithCwd(p.Scratch)})
+
+	runErr := p.query(ctx, prompt, opts)
+
+	if p.Changes, err = changes.Compare(p.Dir, p.Scratch); err != nil {
+		return p, stderrors.Join(runErr, err)
+	}
+
+	p.Patch = p.Changes.Diff()
+
+	return p, runErr
+}
+
+// query runs the prompt and records its messages and commands.
+func (p *Preview) query(ctx context.Context, prompt string, opts []claudesdk.Option) error {
+	var (
+		runErr   error
+		commands = make(map[string]int)
+	)
+
+	for msg, err := range claudesdk.Query(ctx, prompt, opts...) {
+		if err != nil {
+			runErr = stderrors.Join(runErr, err)
+
+			continue
+		}
+
+		p.Messages = append(p.Messages, msg)
+
+		switch m := msg.(type) {
+		case *claudesdk.AssistantMessage:
+			for _, block := range m.Content {
+				call, ok := block.(*claudesdk.ToolUseBlock)
+				if !ok || call.Name != claudesdk.ToolBash {
+					continue
+				}
+
+				input, err := call.Decode()
+				if err != nil {
+					continue
+				}
+
+				if bash, ok := input.(*claudesdk.BashInput); ok {
+					commands[call.ID] = len(p.Commands)
+					p.Commands = append(p.Commands, Command{
+						ToolUseID: call.ID, Command: bash.Command, Description: bash.Description,
+					})
+				}
+			}
+		case *claudesdk.UserMessage:
+			for _, block := range m.Content.Blocks() {
+				result, ok := block.(*claudesdk.ToolResultBlock)
+				if !ok {
+					continue
+				}
+
+				if i, ok := commands[result.ToolUseID]; ok {
+					p.Commands[i].Output = resultText(result)
+					p.Commands[i].IsError = result.IsError
+				}
+			}
+		case *claudesdk.ResultMessage:
+			p.Result = m
+		}
+	}
+
+	if runErr == nil && p.Result != nil && p.Result.IsError {
+		runErr = fmt.Errorf("query failed: %s", p.Result.Subtype)
+	}
+
+	return runErr
+}
+
+// confine adds a PreToolUse hook that denies edits outside the scratch copy.
+func (p *Preview) confine() claudesdk.Option {
+	guard := func(_ context.Context, input *claudesdk.PreToolUseHookInput) (claudesdk.PreToolUseHookDecision, error) {
+		key := "file_path"
+		if input.ToolName == claudesdk.ToolNotebookEdit {
+			key = "notebook_path"
+		}
+
+		path, _ := input.ToolInput[key].(string)
+		if path != "" && !filepath.IsAbs(path) {
+			path = filepath.Join(cmp.Or(input.Cwd, p.Scratch), path)
+		}
+
+		if path == "" {
+			return claudesdk.PreToolUseHookDecision{}, nil
+		}
+
+		// Symbolic links are mirrored as they are, so check where the edit
+		// would really land
+		if resolved, err := resolve(path); err == nil && inside(p.Scratch, resolved) {
+			return claudesdk.PreToolUseHookDecision{}, nil
+		}
+
+		return claudesdk.PreToolUseHookDecision{
+			Permission: claudesdk.HookPermissionDeny,
+			Reason:     fmt.Sprintf("This is a dry run: only files under %s can be changed.", p.Scratch),
+		}, nil
+	}
+
+	return func(o *claudesdk.ClaudeAgentOptions) {
+		hooks := maps.Clone(o.Hooks)
+		if hooks == nil {
+			hooks = make(map[claudesdk.HookEvent][]*claudesdk.HookMatcher, 1)
+		}
+
+		hooks[claudesdk.HookEventPreToolUse] = slices.Concat(hooks[claudesdk.HookEventPreToolUse],
+			[]*claudesdk.HookMatcher{{
+				Matcher: new(strings.Join(editTools, "|")),
+				Hooks:   []claudesdk.HookCallback{claudesdk.OnPreToolUse(guard)},
+			}})
+		o.Hooks = hooks
+	}
+}
+
+// sandboxed enables the CLI's sandbox unless the options configure one, so
+// commands cannot write outside the scratch copy.
+func sandboxed() claudesdk.Option {
+	return func(o *claudesdk.ClaudeAgentOptions) {
+		if o.SandboxSettings == nil {
+			o.SandboxSettings = &claudesdk.SandboxSettings{
+				Enabled:                  new(true),
+				AllowUnsandboxedCommands: new(false),
+			}
+		}
+	}
+}
+
+// Apply writes the changes to the original workspace. It fails with
+// ErrConflict, changing nothing, if any changed file was modified in the
+// workspace since the preview was made. Apply before Close.
+func (p *Preview) Apply() error {
+	if p.Changes == nil {
+		return nil
+	}
+
+	for _, change := range p.Changes.Files {
+		if err := unchanged(change); err != nil {
+			return err
+		}
+	}
+
+	for _, change := range p.Changes.Files {
+		if err := p.apply(change); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unchanged checks that a file still has the content, or a symbolic link
+// the target, the preview started from.
+func unchanged(change *changes.FileChange) error {
+	if change.Status == changes.StatusCreated {
+		if _, err := os.Lstat(change.Path); !stderrors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: %s was created", ErrConflict, change.Name)
+		}
+
+		return nil
+	}
+
+	var (
+		data string
+		err  error
+	)
+
+	info, err := os.Lstat(change.Path)
+	if err == nil && (info.Mode()&fs.ModeSymlink != 0) != change.SymlinkBefore {
+		return fmt.Errorf("%w: %s was replaced", ErrConflict, change.Name)
+	}
+
+	if change.SymlinkBefore {
+		data, err = os.Readlink(change.Path)
+	} else {
+		var raw []byte
+
+		raw, err = os.ReadFile(change.Path)
+		data = string(raw)
+	}
+
+	switch {
+	case err != nil:
+		return fmt.Errorf("%w: %s: %w", ErrConflict, change.Name, err)
+	case data != change.Before:
+		return fmt.Errorf("%w: %s was modified", ErrConflict, change.Name)
+	}
+
+	return nil
+}
+
+func (p *Preview) apply(change *changes.FileChange) error {
+	// A file replaced by a link or a link replaced by a file must be removed
+	// first; writing through the old link would change its target instead
+	if change.Status == changes.StatusDeleted ||
+		(change.Status == changes.StatusModified && (change.SymlinkBefore || change.SymlinkAfter)) {
+		if err := os.Remove(change.Path); err != nil {
+			return fmt.Errorf("apply %s: %w", change.Name, err)
+		}
+	}
+
+	if change.Status == changes.StatusDeleted {
+		return nil
+	}
+
+	if change.SymlinkAfter {
+		if err := os.MkdirAll(filepath.Dir(change.Path), 0o755); err != nil {
+			return fmt.Errorf("apply %s: %w", change.Name, err)
+		}
+
+		if err := os.Symlink(change.After, change.Path); err != nil {
+			return fmt.Errorf("apply %s: %w", change.Name, err)
+		}
+
+		return nil
+	}
+
+	perm := os.FileMode(0o644)
+	if info, err := os.Stat(filepath.Join(p.Scratch, filepath.FromSlash(change.Name))); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(change.Path), 0o755); err != nil {
+		return fmt.Errorf("apply %s: %w", change.Name, err)
+	}
+
+	if err := os.WriteFile(change.Path, []byte(change.After), perm); err != nil {
+		return fmt.Errorf("apply %s: %w", change.Name, err)
+	}
+
+	return nil
+}
+
+// Close removes the scratch copy.
+func (p *Preview) Close() error {
+	if p == nil || p.root == "" {
+		return nil
+	}
+
+	if err := os.RemoveAll(p.root); err != nil {
+		return fmt.Errorf("remove scratch directory: %w", err)
+	}
+
+	return nil
+}
+
+// maxLinks bounds how many symbolic links resolve follows.
+const maxLinks = 40
+
+// resolve returns path with every symbolic link resolved, including a
+// dangling link at its end and links in the parents of a file that does not
+// exist yet, so it names the file an edit of path would really change.
+func resolve(path string) (string, error) {
+	path = filepath.Clean(path)
+
+	var rest []string
+
+	for range maxLinks {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...), nil
+		}
+
+		if !stderrors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+
+		// A dangling link is followed to its missing target
+		if info, err := os.Lstat(path); err == nil && info.Mode()&fs.ModeSymlink != 0 {
+			target, err := os.Readlink(path)
+			if err != nil {
+				return "", err
+			}
+
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(path), target)
+			}
+
+			path = filepath.Clean(target)
+
+			continue
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", err
+		}
+
+		rest = append([]string{filepath.Base(path)}, rest...)
+		path = parent
+	}
+
+	return "", fmt.Errorf("resolve %s: too many links", path)
+}
+
+// inside reports whether path is dir or under it.
+func inside(dir, path string) bool {
+	rel, err := filepath.Rel(dir, filepath.Clean(path))
+
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// resultText returns the text of a tool result.
+func resultText(result *claudesdk.ToolResultBlock) string {
+	var parts []string
+
+	for _, block := range result.Content {
+		if text, ok := block.(*claudesdk.TextBlock); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+
+	return strings.Join(parts, "\n")
+}
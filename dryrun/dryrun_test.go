@@ -0,0 +1,293 @@
+package dryrun
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	claudesdk "github.com/wagiedev/claude-agent-sdk-go"
+)
+
+// agentTransport stands in for an agent: when started it edits files in the
+// query's working directory, then reports a Bash call and a result.
+type agentTransport struct {
+	cwd     *string
+	sandbox **claudesdk.SandboxSettings
+	edit    func(dir string) error
+	msgs    chan map[string]any
+	errs    chan error
+}
+
+func (a *agentTransport) Start(_ context.Context) error {
+	a.msgs = make(chan map[string]any, 10)
+	a.errs = make(chan error)
+
+	if sandbox := *a.sandbox; sandbox == nil || !*sandbox.Enabled || *sandbox.AllowUnsandboxedCommands {
+		return errors.New("commands are not sandboxed")
+	}
+
+	return a.edit(*a.cwd)
+}
+
+// SendMessage answers the initialize request the confining hook requires,
+// then plays the agent's messages.
+func (a *agentTransport) SendMessage(_ context.Context, data []byte) error {
+	var req struct {
+		RequestID string `json:"request_id"` //nolint:tagliatelle // CLI wire format
+		Request   struct {
+			Subtype string `json:"subtype"`
+		} `json:"request"`
+	}
+
+	if err := json.Unmarshal(data, &req); err != nil || req.Request.Subtype != "initialize" {
+		return nil //nolint:nilerr // only the initialize request is answered
+	}
+
+	a.msgs <- map[string]any{"type": "control_response", "response": map[string]any{
+		"request_id": req.RequestID, "subtype": "success", "result": map[string]any{},
+	}}
+	a.msgs <- map[string]any{"type": "assistant", "message": map[string]any{"model": "m", "content": []any{
+		map[string]any{"type": "tool_use", "id": "t1", "name": "Bash", "input": map[string]any{
+			"command": "go test ./...", "description": "Run tests",
+		}},
+	}}}
+	a.msgs <- map[string]any{"type": "user", "message": map[string]any{"content": []any{
+		map[string]any{"type": "tool_result", "tool_use_id": "t1", "content": "ok", "is_error": false},
+	}}}
+	a.msgs <- map[string]any{
+		"type": "result", "subtype": "success", "session_id": "s1",
+		"duration_ms": 1, "duration_api_ms": 1, "num_turns": 1,
+	}
+
+	close(a.msgs)
+
+	return nil
+}
+
+func (a *agentTransport) ReadMessages(context.Context) (<-chan map[string]any, <-chan error) {
+	return a.msgs, a.errs
+}
+
+func (a *agentTransport) Close() error    { return nil }
+func (a *agentTransport) IsReady() bool   { return true }
+func (a *agentTransport) EndInput() error { return nil }
+
+func agent(edit func(dir string) error) []claudesdk.Option {
+	transport := &agentTransport{edit: edit}
+
+	return []claudesdk.Option{
+		claudesdk.WithTransport(transport),
+		// Run sets the working directory and sandbox last; keep pointers to them
+		func(o *claudesdk.ClaudeAgentOptions) { transport.cwd, transport.sandbox = &o.Cwd, &o.SandboxSettings },
+	}
+}
+
+func workspace(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git", "objects", "ab"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".git", "objects", "ab", "cdef"), []byte("blob"), 0o400))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "old.txt"), []byte("old\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "run.sh"), []byte("#!/bin/sh\n"), 0o700))
+
+	return dir
+}
+
+func TestRun(t *testing.T) {
+	dir := workspace(t)
+
+	preview, err := Run(t.Context(), dir, "refactor", agent(func(scratch string) error {
+		if err := os.WriteFile(filepath.Join(scratch, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o600); err != nil {
+			return err
+		}
+
+		if err := os.Remove(filepath.Join(scratch, "old.txt")); err != nil {
+			return err
+		}
+
+		return os.WriteFile(filepath.Join(scratch, "new.txt"), []byte("new\n"), 0o640)
+	})...)
+	require.NoError(t, err)
+
+	defer preview.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", string(data), "the workspace is untouched")
+
+	info, err := os.Stat(filepath.Join(preview.Scratch, "run.sh"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+
+	assert.Equal(t, filepath.Base(dir), filepath.Base(preview.Scratch))
+	assert.Contains(t, preview.Patch, "--- a/main.go\n+++ b/main.go\n")
+	assert.Contains(t, preview.Patch, "--- a/old.txt\n+++ /dev/null\n")
+	require.Len(t, preview.Changes.Files, 3)
+
+	assert.Equal(t, []Command{{
+		ToolUseID: "t1", Command: "go test ./...", Description: "Run tests", Output: "ok",
+	}}, preview.Commands)
+	assert.NotNil(t, preview.Result)
+
+	require.NoError(t, preview.Apply())
+
+	data, err = os.ReadFile(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\nfunc main() {}\n", string(data))
+	assert.NoFileExists(t, filepath.Join(dir, "old.txt"))
+
+	info, err = os.Stat(filepath.Join(dir, "new.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+
+	require.NoError(t, preview.Close())
+	assert.NoDirExists(t, preview.Scratch)
+}
+
+func TestSandboxed(t *testing.T) {
+	opts := &claudesdk.ClaudeAgentOptions{}
+	sandboxed()(opts)
+	require.NotNil(t, opts.SandboxSettings)
+	assert.True(t, *opts.SandboxSettings.Enabled)
+	assert.False(t, *opts.SandboxSettings.AllowUnsandboxedCommands)
+
+	custom := &claudesdk.SandboxSettings{Enabled: new(true), ExcludedCommands: []string{"docker"}}
+	opts = &claudesdk.ClaudeAgentOptions{SandboxSettings: custom}
+	sandboxed()(opts)
+	assert.Same(t, custom, opts.SandboxSettings, "the caller's settings replace the default")
+}
+
+func TestApplyConflict(t *testing.T) {
+	dir := workspace(t)
+
+	preview, err := Run(t.Context(), dir, "edit", agent(func(scratch string) error {
+		return os.WriteFile(filepath.Join(scratch, "main.go"), []byte("package agent\n"), 0o600)
+	})...)
+	require.NoError(t, err)
+
+	defer preview.Close()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package user\n"), 0o600))
+	require.ErrorIs(t, preview.Apply(), ErrConflict)
+
+	data, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package user\n", string(data))
+}
+
+func TestConfine(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+
+	outside := t.TempDir()
+	p := &Preview{Scratch: filepath.Join(root, "ws")}
+
+	require.NoError(t, os.MkdirAll(filepath.Join(p.Scratch, "pkg"), 0o700))
+	require.NoError(t, os.Symlink(outside, filepath.Join(p.Scratch, "abs")))
+	require.NoError(t, os.Symlink("../..", filepath.Join(p.Scratch, "pkg", "up")))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "missing.go"), filepath.Join(p.Scratch, "dangling.go")))
+	require.NoError(t, os.Symlink("a.go", filepath.Join(p.Scratch, "pkg", "b.go")))
+
+	opts := &claudesdk.ClaudeAgentOptions{}
+	p.confine()(opts)
+
+	matchers := opts.Hooks[claudesdk.HookEventPreToolUse]
+	require.Len(t, matchers, 1)
+
+	decide := func(path string) string {
+		t.Helper()
+
+		out, err := matchers[0].Hooks[0](t.Context(), &claudesdk.PreToolUseHookInput{
+			BaseInput: claudesdk.BaseHookInput{Cwd: p.Scratch},
+			ToolName:  claudesdk.ToolWrite,
+			ToolInput: map[string]any{"file_path": path},
+		}, nil, nil)
+		require.NoError(t, err)
+
+		sync, ok := out.(*claudesdk.SyncHookJSONOutput)
+		require.True(t, ok)
+
+		specific, ok := sync.HookSpecificOutput.(*claudesdk.PreToolUseHookSpecificOutput)
+		if !ok || specific.PermissionDecision == nil {
+			return ""
+		}
+
+		return *specific.PermissionDecision
+	}
+
+	assert.Empty(t, decide("main.go"))
+	assert.Empty(t, decide(filepath.Join(p.Scratch, "pkg", "a.go")))
+	assert.Empty(t, decide("new/dir/file.go"))
+	assert.Empty(t, decide("pkg/b.go"), "links inside the scratch copy are allowed")
+	assert.Equal(t, "deny", decide(filepath.Join(root, "other.go")))
+	assert.Equal(t, "deny", decide("../escape.go"))
+	assert.Equal(t, "deny", decide("abs/file.go"), "absolute links cannot lead out of the copy")
+	assert.Equal(t, "deny", decide("pkg/up/file.go"), "relative links cannot lead out of the copy")
+	assert.Equal(t, "deny", decide("dangling.go"), "writes through dangling links are denied")
+}
+
+func TestApplySymlinks(t *testing.T) {
+	dir := workspace(t)
+	require.NoError(t, os.Symlink("main.go", filepath.Join(dir, "link")))
+
+	preview, err := Run(t.Context(), dir, "relink", agent(func(scratch string) error {
+		if err := os.Remove(filepath.Join(scratch, "link")); err != nil {
+			return err
+		}
+
+		if err := os.Symlink("old.txt", filepath.Join(scratch, "link")); err != nil {
+			return err
+		}
+
+		return os.Symlink("run.sh", filepath.Join(scratch, "new-link"))
+	})...)
+	require.NoError(t, err)
+
+	defer preview.Close()
+
+	require.Len(t, preview.Changes.Files, 2)
+	assert.True(t, preview.Changes.Files[0].SymlinkAfter)
+
+	require.NoError(t, preview.Apply())
+
+	target, err := os.Readlink(filepath.Join(dir, "link"))
+	require.NoError(t, err)
+	assert.Equal(t, "old.txt", target)
+
+	target, err = os.Readlink(filepath.Join(dir, "new-link"))
+	require.NoError(t, err)
+	assert.Equal(t, "run.sh", target)
+
+	data, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", string(data), "the old link's target is not written")
+}
+
+func TestMirrorHardlinksGitObjects(t *testing.T) {
+	dir := workspace(t)
+	dst := filepath.Join(t.TempDir(), "copy")
+
+	require.NoError(t, mirror(dir, dst))
+
+	src, err := os.Stat(filepath.Join(dir, ".git", "objects", "ab", "cdef"))
+	require.NoError(t, err)
+
+	linked, err := os.Stat(filepath.Join(dst, ".git", "objects", "ab", "cdef"))
+	require.NoError(t, err)
+	assert.True(t, os.SameFile(src, linked))
+
+	src, err = os.Stat(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+
+	copied, err := os.Stat(filepath.Join(dst, "main.go"))
+	require.NoError(t, err)
+	assert.False(t, os.SameFile(src, copied), "workspace files are never hardlinked")
+}
@@ -0,0 +1,93 @@
+package dryrun
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// mirror recreates the tree in src at dst. Files are cloned with reflinks
+// where the filesystem supports them and copied otherwise, so writes in dst
+// never reach src. Hardlinks would be cheaper but share writes made in
+// place, so only git objects, which are never modified, are hardlinked.
+// Symbolic links are copied as they are and may point out of dst; the
+// confining hook resolves them before allowing an edit.
+func mirror(src, dst string) error {
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0o700)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+
+			return os.Symlink(link, target)
+		case !d.Type().IsRegular():
+			// Sockets, pipes and devices are not part of a workspace
+			return nil
+		case isGitObject(rel) && os.Link(path, target) == nil:
+			return nil
+		default:
+			return cloneFile(path, target, info.Mode().Perm())
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("mirror %s: %w", src, err)
+	}
+
+	return nil
+}
+
+// isGitObject reports whether rel is a loose or packed git object, which git
+// writes once and never changes.
+func isGitObject(rel string) bool {
+	return strings.HasPrefix(filepath.ToSlash(rel), ".git/objects/")
+}
+
+// cloneFile creates dst with the content of src, sharing its blocks when
+// the filesystem supports reflinks.
+func cloneFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+
+	if reflink(out, in) != nil {
+		// io.Copy uses copy_file_range where available, which may still
+		// share blocks
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+
+			return err
+		}
+	}
+
+	return out.Close()
+}
@@ -38,4 +38,66 @@ var (
 
 	// ErrRequestTimeout indicates a request timed out.
 	ErrRequestTimeout = errors.ErrRequestTimeout
+
+	// ErrHookRegistryDisabled indicates hooks were added or removed on a client
+	// that was started without a hook registry.
+	ErrHookRegistryDisabled = errors.ErrHookRegistryDisabled
+
+	// ErrBudgetExhausted indicates a shared budget has no spend left, so the
+	// run was rejected or interrupted.
+	ErrBudgetExhausted = errors.ErrBudgetExhausted
+
+	// ErrCircuitOpen indicates a circuit breaker rejected the run after
+	// repeated rate limit, server or CLI process failures.
+	ErrCircuitOpen = errors.ErrCircuitOpen
+
+	// ErrUnknownTool indicates a tool input or result was decoded for a tool
+	// that is not a built-in tool, such as an MCP tool.
+	ErrUnknownTool = errors.ErrUnknownTool
+
+	// ErrCheckpointsDisabled indicates a turn was undone on a client that was
+	// started without a checkpoint registry.
+	ErrCheckpointsDisabled = errors.ErrCheckpointsDisabled
+
+	// ErrNoCheckpoint indicates there is no recorded turn to undo.
+	ErrNoCheckpoint = errors.ErrNoCheckpoint
+
+	// ErrUndoIncomplete indicates an undo rewound files but could not fork
+	// the session, so the conversation still includes the undone turn.
+	ErrUndoIncomplete = errors.ErrUndoIncomplete
+
+	// ErrInvalidEnvironment indicates the CLI environment violates the
+	// environment policy or enables conflicting settings.
+	ErrInvalidEnvironment = errors.ErrInvalidEnvironment
+
+	// ErrInvalidProvider indicates a provider configuration is missing
+	// required settings or has invalid ones.
+	ErrInvalidProvider = errors.ErrInvalidProvider
+)
+
+// Failure classes. A ClassifiedError matches one of these with errors.Is.
+var (
+	// ErrRateLimited indicates the API rejected requests for exceeding a rate
+	// limit (HTTP 429). Retryable.
+	ErrRateLimited = errors.ErrRateLimited
+
+	// ErrOverloaded indicates the API is overloaded or failing (HTTP 529 and
+	// other 5xx responses). Retryable.
+	ErrOverloaded = errors.ErrOverloaded
+
+	// ErrAuth indicates missing or invalid credentials.
+	ErrAuth = errors.ErrAuth
+
+	// ErrBilling indicates the account cannot be billed, e.g. no credit left.
+	ErrBilling = errors.ErrBilling
+
+	// ErrKilled indicates the CLI process was killed by SIGKILL or SIGTERM,
+	// e.g. by a resource limit, the OOM killer or a cancelled context.
+	ErrKilled = errors.ErrKilled
+
+	// ErrMaxTurns indicates the run stopped at its MaxTurns limit.
+	ErrMaxTurns = errors.ErrMaxTurns
+
+	// ErrBudgetExceeded indicates the run stopped at its MaxBudgetUSD limit.
+	ErrBudgetExceeded = errors.ErrBudgetExceeded
 )
@@ -51,46 +51,26 @@ func examplePreToolUse() {
 	timeout := 5.0
 
 	// Hook to check bash commands
-	checkBashCommand := func(
+	checkBashCommand := claudesdk.OnPreToolUse(func(
 		ctx context.Context,
-		input claudesdk.HookInput,
-		toolUseID *string,
-		hookCtx *claudesdk.HookContext,
-	) (claudesdk.HookJSONOutput, error) {
-		preToolInput, ok := input.(*claudesdk.PreToolUseHookInput)
-		if !ok {
-			continueFlag := true
-
-			return &claudesdk.SyncHookJSONOutput{Continue: &continueFlag}, nil
-		}
-
-		if preToolInput.ToolName != bashToolName {
-			continueFlag := true
-
-			return &claudesdk.SyncHookJSONOutput{Continue: &continueFlag}, nil
-		}
-
-		command, _ := preToolInput.ToolInput["command"].(string)
+		input *claudesdk.PreToolUseHookInput,
+	) (claudesdk.PreToolUseHookDecision, error) {
+		command, _ := input.ToolInput["command"].(string)
 		blockPatterns := []string{"foo.sh"}
 
 		for _, pattern := range blockPatterns {
 			if strings.Contains(command, pattern) {
 				fmt.Printf("[HOOK] Blocked command: %s\n", command)
 
-				return &claudesdk.SyncHookJSONOutput{
-					HookSpecificOutput: &claudesdk.PreToolUseHookSpecificOutput{
-						HookEventName:            "PreToolUse",
-						PermissionDecision:       new("deny"),
-						PermissionDecisionReason: new("Command contains invalid pattern: " + pattern),
-					},
+				return claudesdk.PreToolUseHookDecision{
+					Permission: claudesdk.HookPermissionDeny,
+					Reason:     "Command contains invalid pattern: " + pattern,
 				}, nil
 			}
 		}
 
-		continueFlag := true
-
-		return &claudesdk.SyncHookJSONOutput{Continue: &continueFlag}, nil
-	}
+		return claudesdk.PreToolUseHookDecision{}, nil
+	})
 
 	if err := client.Start(ctx,
 		claudesdk.WithLogger(logger),
@@ -161,19 +141,14 @@ func exampleUserPromptSubmit() {
 	timeout := 5.0
 
 	// Hook to add custom instructions at session start
-	addCustomInstructions := func(
+	addCustomInstructions := claudesdk.OnUserPromptSubmit(func(
 		ctx context.Context,
-		input claudesdk.HookInput,
-		toolUseID *string,
-		hookCtx *claudesdk.HookContext,
-	) (claudesdk.HookJSONOutput, error) {
-		return &claudesdk.SyncHookJSONOutput{
-			HookSpecificOutput: &claudesdk.UserPromptSubmitHookSpecificOutput{
-				HookEventName:     "UserPromptSubmit",
-				AdditionalContext: new("My favorite color is hot pink"),
-			},
+		input *claudesdk.UserPromptSubmitHookInput,
+	) (claudesdk.UserPromptSubmitHookDecision, error) {
+		return claudesdk.UserPromptSubmitHookDecision{
+			AdditionalContext: "My favorite color is hot pink",
 		}, nil
-	}
+	})
 
 	if err := client.Start(ctx,
 		claudesdk.WithLogger(logger),
@@ -224,36 +199,24 @@ func examplePostToolUse() {
 	timeout := 5.0
 
 	// Hook to review tool output
-	reviewToolOutput := func(
+	reviewToolOutput := claudesdk.OnPostToolUse(func(
 		ctx context.Context,
-		input claudesdk.HookInput,
-		toolUseID *string,
-		hookCtx *claudesdk.HookContext,
-	) (claudesdk.HookJSONOutput, error) {
-		postToolInput, ok := input.(*claudesdk.PostToolUseHookInput)
-		if !ok {
-			continueFlag := true
-
-			return &claudesdk.SyncHookJSONOutput{Continue: &continueFlag}, nil
-		}
-
-		toolResponse := fmt.Sprintf("%v", postToolInput.ToolResponse)
+		input *claudesdk.PostToolUseHookInput,
+	) (claudesdk.PostToolUseHookDecision, error) {
+		toolResponse := fmt.Sprintf("%v", input.ToolResponse)
 
 		// If the tool produced an error, add helpful context
 		if strings.Contains(strings.ToLower(toolResponse), "error") {
-			return &claudesdk.SyncHookJSONOutput{
-				SystemMessage: new("The command produced an error. You may want to try a different approach."),
-				Reason:        new("Tool execution failed - consider checking the command syntax"),
-				HookSpecificOutput: &claudesdk.PostToolUseHookSpecificOutput{
-					HookEventName: "PostToolUse",
+			return claudesdk.PostToolUseHookDecision{
+				Outcome: claudesdk.HookOutcome{
+					SystemMessage: "The command produced an error. You may want to try a different approach.",
 				},
+				Reason: "Tool execution failed - consider checking the command syntax",
 			}, nil
 		}
 
-		continueFlag := true
-
-		return &claudesdk.SyncHookJSONOutput{Continue: &continueFlag}, nil
-	}
+		return claudesdk.PostToolUseHookDecision{}, nil
+	})
 
 	if err := client.Start(ctx,
 		claudesdk.WithLogger(logger),
@@ -307,48 +270,31 @@ func exampleDecisionFields() {
 	timeout := 5.0
 
 	// Hook with strict approval logic
-	strictApprovalHook := func(
+	strictApprovalHook := claudesdk.OnPreToolUse(func(
 		ctx context.Context,
-		input claudesdk.HookInput,
-		toolUseID *string,
-		hookCtx *claudesdk.HookContext,
-	) (claudesdk.HookJSONOutput, error) {
-		preToolInput, ok := input.(*claudesdk.PreToolUseHookInput)
-		if !ok {
-			continueFlag := true
-
-			return &claudesdk.SyncHookJSONOutput{Continue: &continueFlag}, nil
-		}
-
+		input *claudesdk.PreToolUseHookInput,
+	) (claudesdk.PreToolUseHookDecision, error) {
 		// Block any Write operations to specific files
-		if preToolInput.ToolName == writeToolName {
-			filePath, _ := preToolInput.ToolInput["file_path"].(string)
+		if input.ToolName == writeToolName {
+			filePath, _ := input.ToolInput["file_path"].(string)
 
 			if strings.Contains(strings.ToLower(filePath), "important") {
 				fmt.Printf("[HOOK] Blocked Write to: %s\n", filePath)
 
-				return &claudesdk.SyncHookJSONOutput{
-					Reason:        new("Writes to files containing 'important' in the name are not allowed for safety"),
-					SystemMessage: new("Write operation blocked by security policy"),
-					HookSpecificOutput: &claudesdk.PreToolUseHookSpecificOutput{
-						HookEventName:            "PreToolUse",
-						PermissionDecision:       new("deny"),
-						PermissionDecisionReason: new("Security policy blocks writes to important files"),
-					},
+				return claudesdk.PreToolUseHookDecision{
+					Outcome:    claudesdk.HookOutcome{SystemMessage: "Write operation blocked by security policy"},
+					Permission: claudesdk.HookPermissionDeny,
+					Reason:     "Security policy blocks writes to important files",
 				}, nil
 			}
 		}
 
 		// Allow everything else explicitly
-		return &claudesdk.SyncHookJSONOutput{
-			Reason: new("Tool use approved after security review"),
-			HookSpecificOutput: &claudesdk.PreToolUseHookSpecificOutput{
-				HookEventName:            "PreToolUse",
-				PermissionDecision:       new("allow"),
-				PermissionDecisionReason: new("Tool passed security checks"),
-			},
+		return claudesdk.PreToolUseHookDecision{
+			Permission: claudesdk.HookPermissionAllow,
+			Reason:     "Tool passed security checks",
 		}, nil
-	}
+	})
 
 	if err := client.Start(ctx,
 		claudesdk.WithLogger(logger),
@@ -422,38 +368,27 @@ func exampleContinueControl() {
 	timeout := 5.0
 
 	// Hook to stop on critical errors
-	stopOnErrorHook := func(
+	stopOnErrorHook := claudesdk.OnPostToolUse(func(
 		ctx context.Context,
-		input claudesdk.HookInput,
-		toolUseID *string,
-		hookCtx *claudesdk.HookContext,
-	) (claudesdk.HookJSONOutput, error) {
-		postToolInput, ok := input.(*claudesdk.PostToolUseHookInput)
-		if !ok {
-			continueFlag := true
-
-			return &claudesdk.SyncHookJSONOutput{Continue: &continueFlag}, nil
-		}
-
-		toolResponse := fmt.Sprintf("%v", postToolInput.ToolResponse)
+		input *claudesdk.PostToolUseHookInput,
+	) (claudesdk.PostToolUseHookDecision, error) {
+		toolResponse := fmt.Sprintf("%v", input.ToolResponse)
 
 		// Stop execution if we see a critical error
 		if strings.Contains(strings.ToLower(toolResponse), "critical") {
 			fmt.Println("[HOOK] Critical error detected - stopping execution")
 
-			continueFlag := false
-
-			return &claudesdk.SyncHookJSONOutput{
-				Continue:      &continueFlag,
-				StopReason:    new("Critical error detected in tool output - execution halted for safety"),
-				SystemMessage: new("Execution stopped due to critical error"),
+			return claudesdk.PostToolUseHookDecision{
+				Outcome: claudesdk.HookOutcome{
+					Stop:          true,
+					StopReason:    "Critical error detected in tool output - execution halted for safety",
+					SystemMessage: "Execution stopped due to critical error",
+				},
 			}, nil
 		}
 
-		continueFlag := true
-
-		return &claudesdk.SyncHookJSONOutput{Continue: &continueFlag}, nil
-	}
+		return claudesdk.PostToolUseHookDecision{}, nil
+	})
 
 	if err := client.Start(ctx,
 		claudesdk.WithLogger(logger),
@@ -489,6 +424,88 @@ func exampleContinueControl() {
 	fmt.Println()
 }
 
+// exampleComposition demonstrates glob matchers and chained hooks.
+func exampleComposition() {
+	fmt.Println("=== Hook Composition Example ===")
+	fmt.Println("This example chains an input rewriter with a policy check, matched by glob.")
+	fmt.Println()
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	client := claudesdk.NewClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	defer client.Close()
+
+	// Rewrite commands to run quietly; later hooks see the rewritten input.
+	quiet := claudesdk.OnPreToolUse(func(
+		ctx context.Context,
+		input *claudesdk.PreToolUseHookInput,
+	) (claudesdk.PreToolUseHookDecision, error) {
+		command, _ := input.ToolInput["command"].(string)
+		if !strings.HasPrefix(command, "echo ") {
+			return claudesdk.PreToolUseHookDecision{}, nil
+		}
+
+		return claudesdk.PreToolUseHookDecision{
+			UpdatedInput: map[string]any{"command": command + " > /dev/null"},
+		}, nil
+	})
+
+	// Deny anything that still touches the network.
+	noNetwork := claudesdk.OnPreToolUse(func(
+		ctx context.Context,
+		input *claudesdk.PreToolUseHookInput,
+	) (claudesdk.PreToolUseHookDecision, error) {
+		command, _ := input.ToolInput["command"].(string)
+		if strings.Contains(command, "curl") {
+			return claudesdk.PreToolUseHookDecision{
+				Permission: claudesdk.HookPermissionDeny,
+				Reason:     "Network access is disabled",
+			}, nil
+		}
+
+		return claudesdk.PreToolUseHookDecision{}, nil
+	})
+
+	matcher, err := claudesdk.MatchHookGlob("Bash|mcp__shell__*", claudesdk.ChainHooks(quiet, noNetwork))
+	if err != nil {
+		fmt.Printf("Invalid matcher: %v\n", err)
+
+		return
+	}
+
+	if err := client.Start(ctx,
+		claudesdk.WithLogger(logger),
+		claudesdk.WithModel(fastModelName),
+		claudesdk.WithMaxTurns(maxTurnsPerExample),
+		claudesdk.WithAllowedTools(bashToolName),
+		claudesdk.WithPermissionMode("bypassPermissions"),
+		claudesdk.WithHooks(map[claudesdk.HookEvent][]*claudesdk.HookMatcher{
+			claudesdk.HookEventPreToolUse: {matcher},
+		}),
+	); err != nil {
+		fmt.Printf("Failed to connect: %v\n", err)
+
+		return
+	}
+
+	fmt.Println("User: Run the bash command: curl https://example.com")
+
+	if err := client.Query(ctx, "Run the bash command: curl https://example.com"); err != nil {
+		fmt.Printf("Failed to send query: %v\n", err)
+
+		return
+	}
+
+	for msg := range client.ReceiveResponse(ctx) {
+		displayMessage(msg)
+	}
+
+	fmt.Println()
+}
+
 func main() {
 	fmt.Println("Starting Claude SDK Hooks Examples...")
 	fmt.Println(strings.Repeat("=", 50))
@@ -500,6 +517,7 @@ func main() {
 		"PostToolUse":      examplePostToolUse,
 		"DecisionFields":   exampleDecisionFields,
 		"ContinueControl":  exampleContinueControl,
+		"Composition":      exampleComposition,
 	}
 
 	if len(os.Args) < 2 {
@@ -517,6 +535,7 @@ func main() {
 		fmt.Println("  PostToolUse     - Review tool output with reason and systemMessage")
 		fmt.Println("  DecisionFields  - Use permissionDecision='allow'/'deny' with reason")
 		fmt.Println("  ContinueControl - Control execution with continue and stopReason")
+		fmt.Println("  Composition     - Chain hooks behind a glob matcher")
 
 		return
 	}
@@ -526,7 +545,7 @@ func main() {
 	if exampleName == "all" {
 		exampleOrder := []string{
 			"PreToolUse", "UserPromptSubmit", "PostToolUse",
-			"DecisionFields", "ContinueControl",
+			"DecisionFields", "ContinueControl", "Composition",
 		}
 
 		for _, name := range exampleOrder {
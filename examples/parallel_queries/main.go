@@ -8,6 +8,7 @@ import (
 	"time"
 
 	claudesdk "github.com/wagiedev/claude-agent-sdk-go"
+	"github.com/wagiedev/claude-agent-sdk-go/worktree"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -158,6 +159,45 @@ func parallelTranslations() {
 	fmt.Println()
 }
 
+func isolatedEdits() {
+	fmt.Println("=== Isolated Edits ===")
+	fmt.Println("Running 2 editing agents on this repository, each in its own git worktree.")
+	fmt.Println()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
+	defer cancel()
+
+	runner, err := worktree.New(".")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+
+		return
+	}
+
+	// Keep only the diffs; remove the worktrees and branches afterwards
+	runner.Retention = worktree.RetainNone
+	runner.Options = []claudesdk.Option{
+		claudesdk.WithPermissionMode("acceptEdits"),
+		claudesdk.WithMaxTurns(10),
+	}
+
+	results, err := runner.RunAll(ctx,
+		worktree.Task{Name: "doc-comments", Prompt: "Add a doc comment to one undocumented exported function."},
+		worktree.Task{Name: "typos", Prompt: "Fix one typo in a comment."},
+	)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+	}
+
+	for _, res := range results {
+		if res == nil {
+			continue
+		}
+
+		fmt.Printf("[%s] changed %d file(s)\n%s\n", res.Name, len(res.Files), res.Diff)
+	}
+}
+
 func main() {
 	fmt.Println("Parallel Queries Examples")
 	fmt.Println()
@@ -165,4 +205,5 @@ func main() {
 	fmt.Println()
 
 	parallelTranslations()
+	isolatedEdits()
 }
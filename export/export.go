@@ -0,0 +1,234 @@
+// Package export renders conversations as Markdown, as a self-contained HTML
+// report, or as Anthropic Messages API JSON.
+//
+// Every exporter reads the messages a query or client yields, so it can render
+// a live stream, a slice, or a transcript read from disk:
+//
+//	var msgs []claudesdk.Message
+//	for msg, err := range claudesdk.Query(ctx, prompt) {
+//	    ...
+//	    msgs = append(msgs, msg)
+//	}
+//	err := export.HTML(f, "Ticket 4711", slices.Values(msgs))
+//
+// Messages written by subagents, which carry a ParentToolUseID, are grouped
+// under the Task call that started them.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	claudesdk "github.com/wagiedev/claude-agent-sdk-go"
+)
+
+// Roles of the conversation turns.
+const (
+	roleUser      = "user"
+	roleAssistant = "assistant"
+	roleSubagent  = "subagent"
+)
+
+// System message subtypes that are rendered.
+const (
+	subtypeInit            = "init"
+	subtypeCompactBoundary = "compact_boundary"
+)
+
+// subagentTools start subagents. Their tool use ID is the ParentToolUseID of
+// the subagent's messages.
+var subagentTools = map[string]struct{}{"Task": {}, "Agent": {}}
+
+// turn identifies who is speaking, so consecutive messages from the same
+// speaker render under one heading.
+type turn struct {
+	role string
+	// subagent is the tool use ID of the Task call that started a subagent.
+	subagent string
+}
+
+// conversation tracks the speaker and the subagents started so far.
+type conversation struct {
+	current turn
+	agents  map[string]string
+}
+
+func newConversation() *conversation {
+	return &conversation{agents: make(map[string]string)}
+}
+
+// enter records that a message from t follows, reporting whether it starts a
+// new turn.
+func (c *conversation) enter(t turn) bool {
+	if t == c.current {
+		return false
+	}
+
+	c.current = t
+
+	return true
+}
+
+// reset ends the current turn.
+func (c *conversation) reset() {
+	c.current = turn{}
+}
+
+// turnOf returns the speaker of a user or assistant message. Messages that
+// only return tool results continue the turn that made the tool calls.
+func (c *conversation) turnOf(role string, parent *string, blocks []claudesdk.ContentBlock) turn {
+	if parent != nil && *parent != "" {
+		return turn{role: roleSubagent, subagent: *parent}
+	}
+
+	if role == roleUser && onlyToolResults(blocks) && c.current.role == roleAssistant {
+		return c.current
+	}
+
+	return turn{role: role}
+}
+
+// observe remembers the subagents started by an assistant message.
+func (c *conversation) observe(blocks []claudesdk.ContentBlock) {
+	for _, block := range blocks {
+		use, ok := block.(*claudesdk.ToolUseBlock)
+		if !ok {
+			continue
+		}
+
+		if _, isAgent := subagentTools[use.Name]; !isAgent {
+			continue
+		}
+
+		label := stringInput(use.Input, "subagent_type")
+		if desc := stringInput(use.Input, "description"); desc != "" {
+			label = strings.TrimPrefix(label+": "+desc, ": ")
+		}
+
+		c.agents[use.ID] = label
+	}
+}
+
+// title returns the heading of a turn.
+func (c *conversation) title(t turn) string {
+	switch t.role {
+	case roleUser:
+		return "User"
+	case roleSubagent:
+		if label := c.agents[t.subagent]; label != "" {
+			return "Subagent: " + label
+		}
+
+		return "Subagent " + t.subagent
+	default:
+		return "Assistant"
+	}
+}
+
+func onlyToolResults(blocks []claudesdk.ContentBlock) bool {
+	for _, block := range blocks {
+		if _, ok := block.(*claudesdk.ToolResultBlock); !ok {
+			return false
+		}
+	}
+
+	return len(blocks) > 0
+}
+
+// toolSubject returns the input that best describes a tool call, such as the
+// file read or the command run.
+func toolSubject(use *claudesdk.ToolUseBlock) string {
+	for _, key := range []string{"file_path", "command", "pattern", "url", "query", "description", "path"} {
+		if value := stringInput(use.Input, key); value != "" {
+			return firstLine(value)
+		}
+	}
+
+	return ""
+}
+
+func stringInput(input map[string]any, key string) string {
+	value, _ := input[key].(string)
+
+	return value
+}
+
+func firstLine(s string) string {
+	line, _, cut := strings.Cut(s, "\n")
+	if cut {
+		return line + " …"
+	}
+
+	return line
+}
+
+// toolInput formats a tool call's input as indented JSON.
+func toolInput(use *claudesdk.ToolUseBlock) string {
+	data, err := json.MarshalIndent(use.Input, "", "  ")
+	if err != nil {
+		return fmt.Sprint(use.Input)
+	}
+
+	return string(data)
+}
+
+// toolOutput returns the text of a tool result.
+func toolOutput(result *claudesdk.ToolResultBlock) string {
+	parts := make([]string, 0, len(result.Content))
+
+	for _, block := range result.Content {
+		if text, ok := block.(*claudesdk.TextBlock); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+
+	return strings.Join(parts, "\n")
+}
+
+// sessionLine describes the session an init message starts.
+func sessionLine(msg *claudesdk.SystemMessage) string {
+	parts := []string{"Session"}
+
+	for _, key := range []string{"session_id", "model", "cwd"} {
+		if value, ok := msg.Data[key].(string); ok && value != "" {
+			parts = append(parts, value)
+		}
+	}
+
+	return strings.Join(parts, " · ")
+}
+
+// resultLine summarizes a result message.
+func resultLine(msg *claudesdk.ResultMessage) string {
+	parts := []string{msg.Subtype}
+
+	if msg.NumTurns > 0 {
+		parts = append(parts, fmt.Sprintf("%d turns", msg.NumTurns))
+	}
+
+	if msg.DurationMs > 0 {
+		d := time.Duration(msg.DurationMs) * time.Millisecond
+		parts = append(parts, d.Round(100*time.Millisecond).String())
+	}
+
+	if msg.TotalCostUSD != nil {
+		parts = append(parts, fmt.Sprintf("$%.4f", *msg.TotalCostUSD))
+	}
+
+	return strings.Join(parts, " · ")
+}
+
+// printer writes formatted output, keeping the first write error.
+type printer struct {
+	w   io.Writer
+	err error
+}
+
+func (p *printer) printf(format string, args ...any) {
+	if p.err == nil {
+		_, p.err = fmt.Fprintf(p.w, format, args...)
+	}
+}
@@ -0,0 +1,116 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	claudesdk "github.com/wagiedev/claude-agent-sdk-go"
+)
+
+func conversationMessages() []claudesdk.Message {
+	return []claudesdk.Message{
+		&claudesdk.SystemMessage{Subtype: "init", Data: map[string]any{"session_id": "s1", "model": "claude-sonnet-4-6"}},
+		&claudesdk.UserMessage{Content: claudesdk.NewUserMessageContent("Why does <Parse> fail?")},
+		&claudesdk.AssistantMessage{ID: "msg_1", Content: []claudesdk.ContentBlock{
+			&claudesdk.ThinkingBlock{Thinking: "Look at the parser.", Signature: "sig"},
+		}},
+		&claudesdk.AssistantMessage{ID: "msg_1", Content: []claudesdk.ContentBlock{
+			&claudesdk.ToolUseBlock{ID: "toolu_1", Name: "Task", Input: map[string]any{
+				"subagent_type": "Explore", "description": "Find the parser", "prompt": "find it",
+			}},
+		}},
+		&claudesdk.UserMessage{ParentToolUseID: new("toolu_1"), Content: claudesdk.NewUserMessageContent("find it")},
+		&claudesdk.AssistantMessage{ParentToolUseID: new("toolu_1"), Content: []claudesdk.ContentBlock{
+			&claudesdk.ToolUseBlock{ID: "toolu_2", Name: "Read", Input: map[string]any{"file_path": "parse.go"}},
+		}},
+		&claudesdk.UserMessage{Content: claudesdk.NewUserMessageContentBlocks([]claudesdk.ContentBlock{
+			&claudesdk.ToolResultBlock{ToolUseID: "toolu_1", Content: []claudesdk.ContentBlock{
+				&claudesdk.TextBlock{Text: "It is in parse.go ```go"},
+			}},
+		})},
+		&claudesdk.AssistantMessage{Content: []claudesdk.ContentBlock{&claudesdk.TextBlock{Text: "The parser skips `<` tokens."}}},
+		&claudesdk.AssistantMessage{
+			Error:   new(claudesdk.AssistantMessageErrorRateLimit),
+			Content: []claudesdk.ContentBlock{&claudesdk.TextBlock{Text: "API Error: rate limited"}},
+		},
+		&claudesdk.ResultMessage{Subtype: "success", NumTurns: 2, DurationMs: 4200, TotalCostUSD: new(0.0123)},
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Markdown(&buf, slices.Values(conversationMessages())))
+
+	out := buf.String()
+
+	assert.Contains(t, out, "_Session · s1 · claude-sonnet-4-6_")
+	assert.Contains(t, out, "## User\n\nWhy does <Parse> fail?")
+	assert.Equal(t, 2, strings.Count(out, "## Assistant"), "tool results continue the assistant turn")
+	assert.Contains(t, out, "> **Thinking**\n>\n> Look at the parser.")
+	assert.Contains(t, out, "## Subagent: Explore: Find the parser\n\n**Prompt:** find it")
+	assert.Contains(t, out, "**Tool call:** `Read` `parse.go`")
+	assert.Contains(t, out, "````\nIt is in parse.go ```go\n````", "fences are longer than the content's backtick runs")
+	assert.Contains(t, out, "**Result:** success · 2 turns · 4.2s · $0.0123")
+}
+
+func TestHTML(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, HTML(&buf, "Ticket <42>", slices.Values(conversationMessages())))
+
+	out := buf.String()
+
+	assert.True(t, strings.HasPrefix(out, "<!DOCTYPE html>"))
+	assert.Contains(t, out, "<title>Ticket &lt;42&gt;</title>")
+	assert.Contains(t, out, "Why does &lt;Parse&gt; fail?")
+	assert.Contains(t, out, "<details class=\"thinking\" open>")
+	assert.Contains(t, out, "<section class=\"subagent\">\n<h2>Subagent: Explore: Find the parser</h2>")
+	assert.Contains(t, out, "<summary>Tool call: <code>Read</code> parse.go</summary>")
+	assert.Equal(t, strings.Count(out, "<section"), strings.Count(out, "</section>"))
+	assert.NotContains(t, out, "<link", "the report is self-contained")
+	assert.True(t, strings.HasSuffix(out, "</html>\n"))
+}
+
+func TestMessagesAPI(t *testing.T) {
+	out := MessagesAPI(slices.Values(conversationMessages()))
+	require.Len(t, out, 4)
+
+	assert.Equal(t, "user", out[0].Role)
+	assert.Equal(t, "assistant", out[1].Role)
+	assert.Len(t, out[1].Content, 2, "the parts of one response are merged")
+	assert.Equal(t, "user", out[2].Role)
+	assert.Equal(t, "assistant", out[3].Role, "CLI errors are not replayed")
+	assert.Len(t, out[3].Content, 1)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMessagesAPI(&buf, slices.Values(conversationMessages())))
+
+	var decoded []map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	require.Len(t, decoded, 4)
+
+	blocks, ok := decoded[1]["content"].([]any)
+	require.True(t, ok)
+
+	thinking, ok := blocks[0].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "thinking", thinking["type"])
+	assert.Equal(t, "sig", thinking["signature"])
+
+	results, ok := decoded[2]["content"].([]any)
+	require.True(t, ok)
+
+	result, ok := results[0].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "tool_result", result["type"])
+	assert.Equal(t, "toolu_1", result["tool_use_id"])
+
+	buf.Reset()
+	require.NoError(t, WriteMessagesAPI(&buf, slices.Values([]claudesdk.Message{})))
+	assert.Equal(t, "[]\n", buf.String())
+}
@@ -0,0 +1,136 @@
+package export
+
+import (
+	"html"
+	"io"
+	"iter"
+	"strings"
+
+	claudesdk "github.com/wagiedev/claude-agent-sdk-go"
+)
+
+// htmlStyle is inlined so the report is a single file that can be attached to
+// a ticket or opened offline.
+const htmlStyle = `body{font:15px/1.5 -apple-system,BlinkMacSystemFont,"Segoe UI",sans-serif;max-width:56rem;margin:2rem auto;padding:0 1rem;color:#1f2328;background:#fff}
+h1{font-size:1.4rem}h2{font-size:.8rem;text-transform:uppercase;letter-spacing:.05em;color:#59636e;margin:0 0 .5rem}
+section{border:1px solid #d1d9e0;border-radius:6px;padding:.75rem 1rem;margin:1rem 0}
+section.user{background:#f6f8fa}section.subagent{margin-left:2rem;border-left:4px solid #8250df}
+.text{white-space:pre-wrap;margin:.5rem 0}.prompt{font-style:italic}
+details{margin:.5rem 0;border-radius:4px;background:#f6f8fa}summary{cursor:pointer;padding:.25rem .5rem}
+details.thinking{background:#fff8c5}details.error{background:#ffebe9}
+pre{margin:0;padding:.5rem;overflow-x:auto;white-space:pre-wrap;font:13px/1.4 ui-monospace,SFMono-Regular,Menlo,monospace}
+code{font:13px ui-monospace,SFMono-Regular,Menlo,monospace}
+.meta{color:#59636e;font-size:.85rem;margin:1rem 0}
+`
+
+// HTML writes a conversation as a self-contained HTML report. Tool calls and
+// results are folded, thinking is shown, and each subagent's messages are a
+// labelled section.
+func HTML(w io.Writer, title string, msgs iter.Seq[claudesdk.Message]) error {
+	p := &printer{w: w}
+	c := newConversation()
+	open := false
+
+	enter := func(t turn) {
+		if !c.enter(t) {
+			return
+		}
+
+		if open {
+			p.printf("</section>\n")
+		}
+
+		p.printf("<section class=\"%s\">\n<h2>%s</h2>\n", t.role, html.EscapeString(c.title(t)))
+		open = true
+	}
+
+	leave := func() {
+		c.reset()
+
+		if open {
+			p.printf("</section>\n")
+			open = false
+		}
+	}
+
+	p.printf("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	p.printf("<title>%s</title>\n<style>\n%s</style>\n</head>\n<body>\n", html.EscapeString(title), htmlStyle)
+
+	if title != "" {
+		p.printf("<h1>%s</h1>\n", html.EscapeString(title))
+	}
+
+	for msg := range msgs {
+		switch m := msg.(type) {
+		case *claudesdk.UserMessage:
+			blocks := m.Content.Blocks()
+			t := c.turnOf(roleUser, m.ParentToolUseID, blocks)
+			enter(t)
+			htmlBlocks(p, blocks, t.role == roleSubagent)
+		case *claudesdk.AssistantMessage:
+			c.observe(m.Content)
+			enter(c.turnOf(roleAssistant, m.ParentToolUseID, m.Content))
+			htmlBlocks(p, m.Content, false)
+		case *claudesdk.SystemMessage:
+			switch m.Subtype {
+			case subtypeInit:
+				leave()
+				p.printf("<p class=\"meta\">%s</p>\n", html.EscapeString(sessionLine(m)))
+			case subtypeCompactBoundary:
+				leave()
+				p.printf("<p class=\"meta\">Conversation compacted</p>\n")
+			}
+		case *claudesdk.ResultMessage:
+			leave()
+			p.printf("<p class=\"meta\"><strong>Result:</strong> %s</p>\n", html.EscapeString(resultLine(m)))
+		}
+	}
+
+	leave()
+	p.printf("</body>\n</html>\n")
+
+	return p.err
+}
+
+// htmlBlocks writes the blocks of a message. Text a subagent was prompted
+// with is marked as its prompt.
+func htmlBlocks(p *printer, blocks []claudesdk.ContentBlock, prompt bool) {
+	for _, block := range blocks {
+		switch b := block.(type) {
+		case *claudesdk.TextBlock:
+			if strings.TrimSpace(b.Text) == "" {
+				continue
+			}
+
+			class := "text"
+			if prompt {
+				class += " prompt"
+			}
+
+			p.printf("<div class=\"%s\">%s</div>\n", class, html.EscapeString(b.Text))
+		case *claudesdk.ThinkingBlock:
+			if b.Thinking == "" {
+				continue
+			}
+
+			p.printf("<details class=\"thinking\" open>\n<summary>Thinking</summary>\n<div class=\"text\">%s</div>\n</details>\n",
+				html.EscapeString(strings.TrimSpace(b.Thinking)))
+		case *claudesdk.ToolUseBlock:
+			p.printf("<details class=\"tool\">\n<summary>Tool call: <code>%s</code>", html.EscapeString(b.Name))
+
+			if subject := toolSubject(b); subject != "" {
+				p.printf(" %s", html.EscapeString(subject))
+			}
+
+			p.printf("</summary>\n<pre>%s</pre>\n</details>\n", html.EscapeString(toolInput(b)))
+		case *claudesdk.ToolResultBlock:
+			class, label := "tool-result", "Tool result"
+			if b.IsError {
+				class, label = "tool-result error", "Tool error"
+			}
+
+			p.printf("<details class=\"%s\">\n<summary>%s</summary>\n<pre>%s</pre>\n</details>\n",
+				class, label, html.EscapeString(toolOutput(b)))
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package export
+
+import (
+	"io"
+	"iter"
+	"strings"
+
+	claudesdk "github.com/wagiedev/claude-agent-sdk-go"
+)
+
+// Markdown writes a conversation as Markdown. Each turn gets a heading, tool
+// calls and results are fenced code blocks, and thinking is quoted.
+func Markdown(w io.Writer, msgs iter.Seq[claudesdk.Message]) error {
+	p := &printer{w: w}
+	c := newConversation()
+
+	for msg := range msgs {
+		switch m := msg.(type) {
+		case *claudesdk.UserMessage:
+			blocks := m.Content.Blocks()
+			t := c.turnOf(roleUser, m.ParentToolUseID, blocks)
+			markdownTurn(p, c, t)
+			markdownBlocks(p, blocks, t.role == roleSubagent)
+		case *claudesdk.AssistantMessage:
+			c.observe(m.Content)
+			markdownTurn(p, c, c.turnOf(roleAssistant, m.ParentToolUseID, m.Content))
+			markdownBlocks(p, m.Content, false)
+		case *claudesdk.SystemMessage:
+			switch m.Subtype {
+			case subtypeInit:
+				c.reset()
+				p.printf("_%s_\n\n", sessionLine(m))
+			case subtypeCompactBoundary:
+				c.reset()
+				p.printf("---\n\n_Conversation compacted_\n\n")
+			}
+		case *claudesdk.ResultMessage:
+			c.reset()
+			p.printf("---\n\n**Result:** %s\n\n", resultLine(m))
+		}
+	}
+
+	return p.err
+}
+
+func markdownTurn(p *printer, c *conversation, t turn) {
+	if c.enter(t) {
+		p.printf("## %s\n\n", c.title(t))
+	}
+}
+
+// markdownBlocks writes the blocks of a message. Text a subagent was prompted
+// with is labelled as its prompt.
+func markdownBlocks(p *printer, blocks []claudesdk.ContentBlock, prompt bool) {
+	for _, block := range blocks {
+		switch b := block.(type) {
+		case *claudesdk.TextBlock:
+			if strings.TrimSpace(b.Text) == "" {
+				continue
+			}
+
+			if prompt {
+				p.printf("**Prompt:** ")
+			}
+
+			p.printf("%s\n\n", b.Text)
+		case *claudesdk.ThinkingBlock:
+			if b.Thinking == "" {
+				continue
+			}
+
+			p.printf("> **Thinking**\n>\n> %s\n\n", strings.ReplaceAll(strings.TrimSpace(b.Thinking), "\n", "\n> "))
+		case *claudesdk.ToolUseBlock:
+			p.printf("**Tool call:** `%s`", b.Name)
+
+			if subject := toolSubject(b); subject != "" {
+				p.printf(" %s", inlineCode(subject))
+			}
+
+			p.printf("\n\n%s\n\n", fenced("json", toolInput(b)))
+		case *claudesdk.ToolResultBlock:
+			label := "Tool result"
+			if b.IsError {
+				label = "Tool error"
+			}
+
+			p.printf("**%s:**\n\n%s\n\n", label, fenced("", toolOutput(b)))
+		}
+	}
+}
+
+// fenced wraps s in a code fence longer than any backtick run inside it.
+func fenced(lang, s string) string {
+	fence := strings.Repeat("`", max(3, longestRun(s, '`')+1))
+
+	return fence + lang + "\n" + strings.TrimRight(s, "\n") + "\n" + fence
+}
+
+// inlineCode wraps s in backticks, padding it when it contains backticks.
+func inlineCode(s string) string {
+	ticks := strings.Repeat("`", longestRun(s, '`')+1)
+	if strings.Contains(s, "`") {
+		return ticks + " " + s + " " + ticks
+	}
+
+	return ticks + s + ticks
+}
+
+func longestRun(s string, r rune) int {
+	var longest, run int
+
+	for _, c := range s {
+		if c != r {
+			run = 0
+
+			continue
+		}
+
+		run++
+		longest = max(longest, run)
+	}
+
+	return longest
+}
@@ -0,0 +1,116 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+
+	claudesdk "github.com/wagiedev/claude-agent-sdk-go"
+)
+
+// APIMessage is a message in the Anthropic Messages API format.
+type APIMessage struct {
+	Role    string                   `json:"role"`
+	Content []claudesdk.ContentBlock `json:"content"`
+}
+
+// MessagesAPI converts the main conversation to the messages parameter of an
+// Anthropic Messages API request, so it can be replayed or inspected with
+// other tooling.
+//
+// Subagent messages, system and result messages, and errors the CLI reported
+// as assistant messages are left out. Consecutive messages with the same role,
+// such as the parts of one response the CLI streams separately, are merged.
+func MessagesAPI(msgs iter.Seq[claudesdk.Message]) []APIMessage {
+	var out []APIMessage
+
+	add := func(role string, blocks []claudesdk.ContentBlock) {
+		content := make([]claudesdk.ContentBlock, 0, len(blocks))
+
+		for _, block := range blocks {
+			if b := apiBlock(block); b != nil {
+				content = append(content, b)
+			}
+		}
+
+		if len(content) == 0 {
+			return
+		}
+
+		if n := len(out); n > 0 && out[n-1].Role == role {
+			out[n-1].Content = append(out[n-1].Content, content...)
+
+			return
+		}
+
+		out = append(out, APIMessage{Role: role, Content: content})
+	}
+
+	for msg := range msgs {
+		switch m := msg.(type) {
+		case *claudesdk.UserMessage:
+			if m.ParentToolUseID == nil {
+				add(roleUser, m.Content.Blocks())
+			}
+		case *claudesdk.AssistantMessage:
+			if m.ParentToolUseID == nil && m.Error == nil {
+				add(roleAssistant, m.Content)
+			}
+		}
+	}
+
+	return out
+}
+
+// WriteMessagesAPI writes the conversation as a JSON array of Messages API
+// messages.
+func WriteMessagesAPI(w io.Writer, msgs iter.Seq[claudesdk.Message]) error {
+	out := MessagesAPI(msgs)
+	if out == nil {
+		out = []APIMessage{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("encode messages: %w", err)
+	}
+
+	return nil
+}
+
+// apiBlock copies a content block with the fields the API requires set, or
+// returns nil for blocks that carry nothing.
+func apiBlock(block claudesdk.ContentBlock) claudesdk.ContentBlock {
+	switch b := block.(type) {
+	case *claudesdk.TextBlock:
+		if b.Text == "" {
+			return nil
+		}
+
+		return &claudesdk.TextBlock{Type: b.BlockType(), Text: b.Text}
+	case *claudesdk.ThinkingBlock:
+		return &claudesdk.ThinkingBlock{Type: b.BlockType(), Thinking: b.Thinking, Signature: b.Signature}
+	case *claudesdk.ToolUseBlock:
+		input := b.Input
+		if input == nil {
+			input = map[string]any{}
+		}
+
+		return &claudesdk.ToolUseBlock{Type: b.BlockType(), ID: b.ID, Name: b.Name, Input: input}
+	case *claudesdk.ToolResultBlock:
+		result := &claudesdk.ToolResultBlock{Type: b.BlockType(), ToolUseID: b.ToolUseID, IsError: b.IsError}
+
+		for _, inner := range b.Content {
+			if c := apiBlock(inner); c != nil {
+				result.Content = append(result.Content, c)
+			}
+		}
+
+		return result
+	default:
+		return nil
+	}
+}
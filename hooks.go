@@ -1,8 +1,121 @@
 package claudesdk
 
+import "github.com/wagiedev/claude-agent-sdk-go/internal/hook"
+
 // Hook types are re-exported from types.go for convenience.
 // See types.go for documentation on hook-related types including:
 // - HookEvent, HookInput, HookCallback, HookMatcher
 // - All hook event constants (HookEventPreToolUse, etc.)
 // - All hook input types (PreToolUseHookInput, etc.)
 // - All hook output types (HookJSONOutput, SyncHookJSONOutput, etc.)
+//
+// This file holds the typed hook helpers, which let callbacks work with
+// concrete inputs and decisions instead of building SyncHookJSONOutput by hand.
+
+// HookPermissionDecision is the permission outcome of a PreToolUse or
+// PermissionRequest hook.
+type HookPermissionDecision = hook.PermissionDecision
+
+const (
+	// HookPermissionDefault leaves the decision to the normal permission flow.
+	HookPermissionDefault = hook.PermissionDefault
+	// HookPermissionAllow approves the tool call without prompting.
+	HookPermissionAllow = hook.PermissionAllow
+	// HookPermissionDeny rejects the tool call.
+	HookPermissionDeny = hook.PermissionDeny
+	// HookPermissionAsk asks the user to confirm the tool call.
+	HookPermissionAsk = hook.PermissionAsk
+)
+
+// HookOutcome holds the fields shared by every typed hook decision.
+type HookOutcome = hook.Outcome
+
+// PreToolUseHookDecision is the typed result of a PreToolUse hook.
+type PreToolUseHookDecision = hook.PreToolUseDecision
+
+// PostToolUseHookDecision is the typed result of a PostToolUse hook.
+type PostToolUseHookDecision = hook.PostToolUseDecision
+
+// PostToolUseFailureHookDecision is the typed result of a PostToolUseFailure hook.
+type PostToolUseFailureHookDecision = hook.PostToolUseFailureDecision
+
+// UserPromptSubmitHookDecision is the typed result of a UserPromptSubmit hook.
+type UserPromptSubmitHookDecision = hook.UserPromptSubmitDecision
+
+// StopHookDecision is the typed result of a Stop or SubagentStop hook.
+type StopHookDecision = hook.StopDecision
+
+// ContextHookDecision is the typed result of Notification, SubagentStart and
+// SessionStart hooks.
+type ContextHookDecision = hook.ContextDecision
+
+// PermissionRequestHookDecision is the typed result of a PermissionRequest hook.
+type PermissionRequestHookDecision = hook.PermissionRequestDecision
+
+// OnPreToolUse adapts a typed PreToolUse handler to a HookCallback.
+var OnPreToolUse = hook.OnPreToolUse
+
+// OnPostToolUse adapts a typed PostToolUse handler to a HookCallback.
+var OnPostToolUse = hook.OnPostToolUse
+
+// OnPostToolUseFailure adapts a typed PostToolUseFailure handler to a HookCallback.
+var OnPostToolUseFailure = hook.OnPostToolUseFailure
+
+// OnUserPromptSubmit adapts a typed UserPromptSubmit handler to a HookCallback.
+var OnUserPromptSubmit = hook.OnUserPromptSubmit
+
+// OnStop adapts a typed Stop handler to a HookCallback.
+var OnStop = hook.OnStop
+
+// OnSubagentStop adapts a typed SubagentStop handler to a HookCallback.
+var OnSubagentStop = hook.OnSubagentStop
+
+// OnSubagentStart adapts a typed SubagentStart handler to a HookCallback.
+var OnSubagentStart = hook.OnSubagentStart
+
+// OnNotification adapts a typed Notification handler to a HookCallback.
+var OnNotification = hook.OnNotification
+
+// OnSessionStart adapts a typed SessionStart handler to a HookCallback.
+// AdditionalContext is injected into the new or resumed session.
+var OnSessionStart = hook.OnSessionStart
+
+// OnSessionEnd adapts a typed SessionEnd handler to a HookCallback.
+var OnSessionEnd = hook.OnSessionEnd
+
+// OnPreCompact adapts a typed PreCompact handler to a HookCallback.
+var OnPreCompact = hook.OnPreCompact
+
+// OnPermissionRequest adapts a typed PermissionRequest handler to a HookCallback.
+var OnPermissionRequest = hook.OnPermissionRequest
+
+// ChainHooks composes callbacks into one that runs them in order. The first
+// deny, block or stop wins; updated tool inputs compose across callbacks.
+var ChainHooks = hook.Chain
+
+// MatchHookRegex returns a HookMatcher whose hooks run only when the tool name
+// (or other event subject) fully matches a regular expression. The pattern is
+// evaluated by the SDK.
+var MatchHookRegex = hook.MatchRegex
+
+// MustMatchHookRegex is like MatchHookRegex but panics on an invalid pattern.
+var MustMatchHookRegex = hook.MustMatchRegex
+
+// MatchHookGlob returns a HookMatcher whose hooks run only when the tool name
+// (or other event subject) matches a glob such as "mcp__github__*".
+var MatchHookGlob = hook.MatchGlob
+
+// HookRegistry holds hooks that can be added and removed while a session runs.
+// See WithHookRegistry.
+type HookRegistry = hook.Registry
+
+// NewHookRegistry creates a HookRegistry that dispatches the given events.
+// Hooks can only be added for these events.
+var NewHookRegistry = hook.NewRegistry
+
+// ErrHookEventNotRegistered is returned when adding a hook for an event the
+// HookRegistry was not created with.
+var ErrHookEventNotRegistered = hook.ErrEventNotRegistered
+
+// HookSubject returns the value hook matchers are tested against for input.
+var HookSubject = hook.Subject
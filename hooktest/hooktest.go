@@ -0,0 +1,241 @@
+// Package hooktest runs hook callbacks against synthetic inputs without
+// starting the Claude CLI.
+//
+// Inputs built with this package are serialized and parsed exactly as the SDK
+// parses CLI hook_callback requests, matchers are routed the way the CLI
+// routes them, and each callback's response is the JSON the SDK would send
+// back:
+//
+//	result, err := hooktest.Run(ctx, hooks, hooktest.PreToolUse("Bash", map[string]any{
+//	    "command": "rm -rf /",
+//	}))
+//	require.NoError(t, err)
+//	require.Equal(t, "deny", result.PermissionDecision())
+package hooktest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	claudesdk "github.com/wagiedev/claude-agent-sdk-go"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/hook"
+)
+
+// Response is the reply one hook callback sends to the CLI.
+type Response struct {
+	// Event is the hook event the callback ran for.
+	Event claudesdk.HookEvent
+	// MatcherIndex and HookIndex locate the callback in the hooks configuration.
+	MatcherIndex int
+	HookIndex    int
+	// JSON is the serialized response, byte for byte what the CLI receives.
+	JSON json.RawMessage
+	// Wire is JSON decoded into generic values.
+	Wire map[string]any
+}
+
+// Continue reports whether the response lets the session continue.
+func (r *Response) Continue() bool {
+	c, ok := r.Wire["continue"].(bool)
+
+	return !ok || c
+}
+
+// Decision returns the top-level decision field, e.g. "block".
+func (r *Response) Decision() string {
+	d, _ := r.Wire["decision"].(string)
+
+	return d
+}
+
+// PermissionDecision returns the PreToolUse permission decision, or the
+// PermissionRequest decision behavior.
+func (r *Response) PermissionDecision() string {
+	specific := r.specific()
+
+	if d, ok := specific["permissionDecision"].(string); ok {
+		return d
+	}
+
+	decision, _ := specific["decision"].(map[string]any)
+	behavior, _ := decision["behavior"].(string)
+
+	return behavior
+}
+
+// AdditionalContext returns the hook-specific additional context.
+func (r *Response) AdditionalContext() string {
+	c, _ := r.specific()["additionalContext"].(string)
+
+	return c
+}
+
+// UpdatedInput returns the tool input the hook replaced, if any.
+func (r *Response) UpdatedInput() map[string]any {
+	specific := r.specific()
+
+	if u, ok := specific["updatedInput"].(map[string]any); ok {
+		return u
+	}
+
+	decision, _ := specific["decision"].(map[string]any)
+	u, _ := decision["updatedInput"].(map[string]any)
+
+	return u
+}
+
+func (r *Response) specific() map[string]any {
+	s, _ := r.Wire["hookSpecificOutput"].(map[string]any)
+
+	return s
+}
+
+// Result holds the responses of every callback that ran, in order.
+type Result struct {
+	Responses []Response
+}
+
+// PermissionDecision combines the responses the way the CLI does: any "deny"
+// wins, then "ask", then "allow". It returns "" when no hook decided.
+func (r *Result) PermissionDecision() string {
+	var decision string
+
+	for i := range r.Responses {
+		switch d := r.Responses[i].PermissionDecision(); d {
+		case "deny":
+			return d
+		case "ask":
+			decision = d
+		case "allow":
+			if decision == "" {
+				decision = d
+			}
+		}
+	}
+
+	return decision
+}
+
+// Blocked reports whether any response blocks, denies or stops the event.
+func (r *Result) Blocked() bool {
+	for i := range r.Responses {
+		resp := &r.Responses[i]
+		if !resp.Continue() || resp.Decision() == "block" || resp.PermissionDecision() == "deny" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AdditionalContext returns the non-empty additional context of each response.
+func (r *Result) AdditionalContext() []string {
+	var contexts []string
+
+	for i := range r.Responses {
+		if c := r.Responses[i].AdditionalContext(); c != "" {
+			contexts = append(contexts, c)
+		}
+	}
+
+	return contexts
+}
+
+// Run delivers input to the hooks configured for its event, as passed to
+// WithHooks. Matchers are selected by their Matcher pattern like the CLI
+// selects them; each callback is then invoked through the same parse, predicate
+// and serialization path the SDK uses for hook_callback requests. Matcher
+// timeouts bound the callback context.
+//
+// Run stops at the first callback error and returns it.
+func Run(
+	ctx context.Context,
+	hooks map[claudesdk.HookEvent][]*claudesdk.HookMatcher,
+	input claudesdk.HookInput,
+) (*Result, error) {
+	inputData, err := wire(input)
+	if err != nil {
+		return nil, err
+	}
+
+	event := input.GetHookEventName()
+	result := &Result{}
+
+	for mi, m := range hooks[event] {
+		if m == nil || !hook.MatchName(m.Matcher, input) {
+			continue
+		}
+
+		for hi, cb := range m.Hooks {
+			resp, err := invoke(ctx, m, cb, inputData)
+			if err != nil {
+				return result, fmt.Errorf("%s matcher %d hook %d: %w", event, mi, hi, err)
+			}
+
+			data, err := json.Marshal(resp)
+			if err != nil {
+				return result, fmt.Errorf("%s matcher %d hook %d: marshal response: %w", event, mi, hi, err)
+			}
+
+			var decoded map[string]any
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				return result, fmt.Errorf("%s matcher %d hook %d: decode response: %w", event, mi, hi, err)
+			}
+
+			result.Responses = append(result.Responses, Response{
+				Event:        event,
+				MatcherIndex: mi,
+				HookIndex:    hi,
+				JSON:         data,
+				Wire:         decoded,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// invoke runs a single callback of m with the matcher timeout applied.
+func invoke(
+	ctx context.Context,
+	m *claudesdk.HookMatcher,
+	cb claudesdk.HookCallback,
+	inputData map[string]any,
+) (map[string]any, error) {
+	if m.Timeout != nil && *m.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(*m.Timeout*float64(time.Second)))
+		defer cancel()
+	}
+
+	var toolUseID *string
+	if id, ok := inputData["tool_use_id"].(string); ok && id != "" {
+		toolUseID = &id
+	}
+
+	return hook.Invoke(ctx, hook.Filter(m.Predicate, cb), inputData, toolUseID)
+}
+
+// wire converts input to the JSON object the CLI would send.
+func wire(input claudesdk.HookInput) (map[string]any, error) {
+	if unknown, ok := input.(*claudesdk.UnknownHookInput); ok && unknown.Raw != nil {
+		return unknown.Raw, nil
+	}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("marshal hook input: %w", err)
+	}
+
+	var inputData map[string]any
+	if err := json.Unmarshal(data, &inputData); err != nil {
+		return nil, fmt.Errorf("decode hook input: %w", err)
+	}
+
+	inputData["hook_event_name"] = string(input.GetHookEventName())
+
+	return inputData, nil
+}
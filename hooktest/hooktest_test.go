@@ -0,0 +1,138 @@
+package hooktest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	claudesdk "github.com/wagiedev/claude-agent-sdk-go"
+)
+
+func safetyHooks() map[claudesdk.HookEvent][]*claudesdk.HookMatcher {
+	guard := claudesdk.OnPreToolUse(func(
+		_ context.Context,
+		in *claudesdk.PreToolUseHookInput,
+	) (claudesdk.PreToolUseHookDecision, error) {
+		command, _ := in.ToolInput["command"].(string)
+		if strings.Contains(command, "rm -rf") {
+			return claudesdk.PreToolUseHookDecision{
+				Permission: claudesdk.HookPermissionDeny,
+				Reason:     "destructive command",
+			}, nil
+		}
+
+		return claudesdk.PreToolUseHookDecision{}, nil
+	})
+
+	return map[claudesdk.HookEvent][]*claudesdk.HookMatcher{
+		claudesdk.HookEventPreToolUse: {
+			{Matcher: new("Bash"), Hooks: []claudesdk.HookCallback{guard}},
+			claudesdk.MustMatchHookRegex("mcp__.*", guard),
+		},
+		claudesdk.HookEventSessionStart: {{
+			Hooks: []claudesdk.HookCallback{claudesdk.OnSessionStart(func(
+				_ context.Context,
+				in *claudesdk.SessionStartHookInput,
+			) (claudesdk.ContextHookDecision, error) {
+				return claudesdk.ContextHookDecision{AdditionalContext: "tenant for " + in.SessionID}, nil
+			})},
+		}},
+	}
+}
+
+func TestRun_Deny(t *testing.T) {
+	result, err := Run(context.Background(), safetyHooks(), PreToolUse("Bash", map[string]any{
+		"command": "rm -rf /",
+	}))
+	require.NoError(t, err)
+	require.Len(t, result.Responses, 2, "the regex matcher is routed by the CLI but its predicate rejects Bash")
+	require.Equal(t, "deny", result.PermissionDecision())
+	require.True(t, result.Blocked())
+	require.JSONEq(t, `{
+		"continue": true,
+		"hookSpecificOutput": {
+			"hookEventName": "PreToolUse",
+			"permissionDecision": "deny",
+			"permissionDecisionReason": "destructive command"
+		}
+	}`, string(result.Responses[0].JSON))
+	require.JSONEq(t, `{"continue": true}`, string(result.Responses[1].JSON))
+}
+
+func TestRun_PredicateFilteredHookContinues(t *testing.T) {
+	hooks := safetyHooks()
+	hooks[claudesdk.HookEventPreToolUse] = hooks[claudesdk.HookEventPreToolUse][1:]
+
+	result, err := Run(context.Background(), hooks, PreToolUse("Read", nil))
+	require.NoError(t, err)
+	require.Len(t, result.Responses, 1)
+	require.JSONEq(t, `{"continue": true}`, string(result.Responses[0].JSON))
+	require.Empty(t, result.PermissionDecision())
+	require.False(t, result.Blocked())
+}
+
+func TestRun_SessionStartContext(t *testing.T) {
+	result, err := Run(context.Background(), safetyHooks(), SessionStart("resume", WithSessionID("s-42")))
+	require.NoError(t, err)
+	require.Equal(t, []string{"tenant for s-42"}, result.AdditionalContext())
+}
+
+func TestRun_NoMatchingHooks(t *testing.T) {
+	result, err := Run(context.Background(), safetyHooks(), Stop(false))
+	require.NoError(t, err)
+	require.Empty(t, result.Responses)
+}
+
+func TestRun_CallbackError(t *testing.T) {
+	wantErr := errors.New("boom")
+	hooks := map[claudesdk.HookEvent][]*claudesdk.HookMatcher{
+		claudesdk.HookEventStop: {{Hooks: []claudesdk.HookCallback{func(
+			context.Context, claudesdk.HookInput, *string, *claudesdk.HookContext,
+		) (claudesdk.HookJSONOutput, error) {
+			return nil, wantErr
+		}}}},
+	}
+
+	_, err := Run(context.Background(), hooks, Stop(true))
+	require.ErrorIs(t, err, wantErr)
+}
+
+func TestRun_WireRoundTrip(t *testing.T) {
+	var got claudesdk.HookInput
+
+	var gotToolUseID *string
+
+	capture := func(
+		_ context.Context, in claudesdk.HookInput, toolUseID *string, _ *claudesdk.HookContext,
+	) (claudesdk.HookJSONOutput, error) {
+		got, gotToolUseID = in, toolUseID
+
+		return &claudesdk.SyncHookJSONOutput{}, nil
+	}
+
+	hooks := map[claudesdk.HookEvent][]*claudesdk.HookMatcher{
+		claudesdk.HookEventPostToolUse: {{Hooks: []claudesdk.HookCallback{capture}}},
+		"FutureEvent":                  {{Hooks: []claudesdk.HookCallback{capture}}},
+	}
+
+	_, err := Run(context.Background(), hooks, PostToolUse("Read", map[string]any{"limit": 10}, "ok",
+		WithCwd("/repo"), WithToolUseID("toolu_9")))
+	require.NoError(t, err)
+
+	post, ok := got.(*claudesdk.PostToolUseHookInput)
+	require.True(t, ok)
+	require.Equal(t, "/repo", post.Cwd)
+	require.Equal(t, map[string]any{"limit": 10.0}, post.ToolInput, "numbers arrive as JSON numbers")
+	require.Equal(t, "toolu_9", *gotToolUseID)
+
+	_, err = Run(context.Background(), hooks, Unknown("FutureEvent", map[string]any{"x": "y"}))
+	require.NoError(t, err)
+
+	unknown, ok := got.(*claudesdk.UnknownHookInput)
+	require.True(t, ok)
+	require.Equal(t, "y", unknown.Raw["x"])
+	require.Equal(t, DefaultSessionID, unknown.SessionID)
+}
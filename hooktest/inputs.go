@@ -0,0 +1,259 @@
+package hooktest
+
+import claudesdk "github.com/wagiedev/claude-agent-sdk-go"
+
+// Default values used for the common fields of every built input.
+const (
+	DefaultSessionID      = "hooktest-session"
+	DefaultTranscriptPath = "/tmp/hooktest/transcript.jsonl"
+	DefaultCwd            = "/tmp/hooktest"
+	DefaultPermissionMode = "default"
+	DefaultToolUseID      = "toolu_hooktest"
+)
+
+// Option customizes a built input.
+type Option func(*inputOptions)
+
+type inputOptions struct {
+	base      claudesdk.BaseHookInput
+	toolUseID string
+}
+
+// WithSessionID sets the session ID of the input.
+func WithSessionID(id string) Option {
+	return func(o *inputOptions) { o.base.SessionID = id }
+}
+
+// WithTranscriptPath sets the transcript path of the input.
+func WithTranscriptPath(path string) Option {
+	return func(o *inputOptions) { o.base.TranscriptPath = path }
+}
+
+// WithCwd sets the working directory of the input.
+func WithCwd(cwd string) Option {
+	return func(o *inputOptions) { o.base.Cwd = cwd }
+}
+
+// WithPermissionMode sets the permission mode of the input. An empty mode
+// omits the field, as older CLIs do.
+func WithPermissionMode(mode string) Option {
+	return func(o *inputOptions) {
+		if mode == "" {
+			o.base.PermissionMode = nil
+
+			return
+		}
+
+		o.base.PermissionMode = &mode
+	}
+}
+
+// WithToolUseID sets the tool use ID of tool event inputs.
+func WithToolUseID(id string) Option {
+	return func(o *inputOptions) { o.toolUseID = id }
+}
+
+func applyOptions(opts []Option) *inputOptions {
+	o := &inputOptions{
+		base: claudesdk.BaseHookInput{
+			SessionID:      DefaultSessionID,
+			TranscriptPath: DefaultTranscriptPath,
+			Cwd:            DefaultCwd,
+			PermissionMode: new(DefaultPermissionMode),
+		},
+		toolUseID: DefaultToolUseID,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// PreToolUse builds a PreToolUse input.
+func PreToolUse(toolName string, toolInput map[string]any, opts ...Option) *claudesdk.PreToolUseHookInput {
+	o := applyOptions(opts)
+
+	return &claudesdk.PreToolUseHookInput{
+		BaseInput:     o.base,
+		HookEventName: string(claudesdk.HookEventPreToolUse),
+		ToolName:      toolName,
+		ToolInput:     toolInput,
+		ToolUseID:     o.toolUseID,
+	}
+}
+
+// PostToolUse builds a PostToolUse input.
+func PostToolUse(
+	toolName string,
+	toolInput map[string]any,
+	toolResponse any,
+	opts ...Option,
+) *claudesdk.PostToolUseHookInput {
+	o := applyOptions(opts)
+
+	return &claudesdk.PostToolUseHookInput{
+		BaseInput:     o.base,
+		HookEventName: string(claudesdk.HookEventPostToolUse),
+		ToolName:      toolName,
+		ToolInput:     toolInput,
+		ToolUseID:     o.toolUseID,
+		ToolResponse:  toolResponse,
+	}
+}
+
+// PostToolUseFailure builds a PostToolUseFailure input.
+func PostToolUseFailure(
+	toolName string,
+	toolInput map[string]any,
+	errMsg string,
+	opts ...Option,
+) *claudesdk.PostToolUseFailureHookInput {
+	o := applyOptions(opts)
+
+	return &claudesdk.PostToolUseFailureHookInput{
+		BaseInput:     o.base,
+		HookEventName: string(claudesdk.HookEventPostToolUseFailure),
+		ToolName:      toolName,
+		ToolInput:     toolInput,
+		ToolUseID:     o.toolUseID,
+		Error:         errMsg,
+	}
+}
+
+// PermissionRequest builds a PermissionRequest input.
+func PermissionRequest(
+	toolName string,
+	toolInput map[string]any,
+	opts ...Option,
+) *claudesdk.PermissionRequestHookInput {
+	o := applyOptions(opts)
+
+	return &claudesdk.PermissionRequestHookInput{
+		BaseInput:     o.base,
+		HookEventName: string(claudesdk.HookEventPermissionRequest),
+		ToolName:      toolName,
+		ToolInput:     toolInput,
+	}
+}
+
+// UserPromptSubmit builds a UserPromptSubmit input.
+func UserPromptSubmit(prompt string, opts ...Option) *claudesdk.UserPromptSubmitHookInput {
+	o := applyOptions(opts)
+
+	return &claudesdk.UserPromptSubmitHookInput{
+		BaseInput:     o.base,
+		HookEventName: string(claudesdk.HookEventUserPromptSubmit),
+		Prompt:        prompt,
+	}
+}
+
+// Stop builds a Stop input.
+func Stop(stopHookActive bool, opts ...Option) *claudesdk.StopHookInput {
+	o := applyOptions(opts)
+
+	return &claudesdk.StopHookInput{
+		BaseInput:      o.base,
+		HookEventName:  string(claudesdk.HookEventStop),
+		StopHookActive: stopHookActive,
+	}
+}
+
+// SubagentStart builds a SubagentStart input.
+func SubagentStart(agentID, agentType string, opts ...Option) *claudesdk.SubagentStartHookInput {
+	o := applyOptions(opts)
+
+	return &claudesdk.SubagentStartHookInput{
+		BaseInput:     o.base,
+		HookEventName: string(claudesdk.HookEventSubagentStart),
+		AgentID:       agentID,
+		AgentType:     agentType,
+	}
+}
+
+// SubagentStop builds a SubagentStop input.
+func SubagentStop(agentID, agentType string, opts ...Option) *claudesdk.SubagentStopHookInput {
+	o := applyOptions(opts)
+
+	return &claudesdk.SubagentStopHookInput{
+		BaseInput:           o.base,
+		HookEventName:       string(claudesdk.HookEventSubagentStop),
+		AgentID:             agentID,
+		AgentType:           agentType,
+		AgentTranscriptPath: DefaultTranscriptPath,
+	}
+}
+
+// PreCompact builds a PreCompact input; trigger is "manual" or "auto".
+func PreCompact(trigger string, opts ...Option) *claudesdk.PreCompactHookInput {
+	o := applyOptions(opts)
+
+	return &claudesdk.PreCompactHookInput{
+		BaseInput:     o.base,
+		HookEventName: string(claudesdk.HookEventPreCompact),
+		Trigger:       trigger,
+	}
+}
+
+// Notification builds a Notification input.
+func Notification(message, notificationType string, opts ...Option) *claudesdk.NotificationHookInput {
+	o := applyOptions(opts)
+
+	return &claudesdk.NotificationHookInput{
+		BaseInput:        o.base,
+		HookEventName:    string(claudesdk.HookEventNotification),
+		Message:          message,
+		NotificationType: notificationType,
+	}
+}
+
+// SessionStart builds a SessionStart input; source is "startup", "resume",
+// "clear" or "compact".
+func SessionStart(source string, opts ...Option) *claudesdk.SessionStartHookInput {
+	o := applyOptions(opts)
+
+	return &claudesdk.SessionStartHookInput{
+		BaseInput:     o.base,
+		HookEventName: string(claudesdk.HookEventSessionStart),
+		Source:        source,
+	}
+}
+
+// SessionEnd builds a SessionEnd input.
+func SessionEnd(reason string, opts ...Option) *claudesdk.SessionEndHookInput {
+	o := applyOptions(opts)
+
+	return &claudesdk.SessionEndHookInput{
+		BaseInput:     o.base,
+		HookEventName: string(claudesdk.HookEventSessionEnd),
+		Reason:        reason,
+	}
+}
+
+// Unknown builds an input for an event this SDK version does not know.
+// fields are added to the common fields.
+func Unknown(event string, fields map[string]any, opts ...Option) *claudesdk.UnknownHookInput {
+	o := applyOptions(opts)
+
+	raw := map[string]any{
+		"hook_event_name": event,
+		"session_id":      o.base.SessionID,
+		"transcript_path": o.base.TranscriptPath,
+		"cwd":             o.base.Cwd,
+	}
+
+	if o.base.PermissionMode != nil {
+		raw["permission_mode"] = *o.base.PermissionMode
+	}
+
+	for k, v := range fields {
+		raw[k] = v
+	}
+
+	return &claudesdk.UnknownHookInput{
+		BaseInput:     o.base,
+		HookEventName: event,
+		Raw:           raw,
+	}
+}
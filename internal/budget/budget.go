@@ -0,0 +1,401 @@
+// Package budget enforces a USD spend limit shared by many concurrent runs.
+package budget
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/errors"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/message"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/models"
+)
+
+// Controls steer a running session. A connected Client implements Controls.
+type Controls interface {
+	Interrupt(ctx context.Context) error
+	SetModel(ctx context.Context, model *string) error
+}
+
+// ThresholdFunc is called for each running session when spend crosses a soft
+// threshold, and for sessions that start after it was crossed. It runs on its
+// own goroutine.
+type ThresholdFunc func(ctx context.Context, s *Session)
+
+type threshold struct {
+	fraction float64
+	fn       ThresholdFunc
+	crossed  bool
+}
+
+// Budget is a spend limit in USD shared by many Query and Client runs.
+//
+// Spend is tracked live: each assistant message's usage is priced with the
+// current model price table, and the CLI's reported TotalCostUSD replaces the
+// estimate when it is higher. Usage of models without a price is only counted
+// once the CLI reports it; Unpriced lists those models. Once spend reaches the
+// limit, every running session is interrupted and new runs and turns fail with
+// ErrBudgetExhausted.
+//
+// A Budget is safe for concurrent use.
+type Budget struct {
+	limit float64
+
+	mu         sync.Mutex
+	spent      float64
+	thresholds []*threshold
+	sessions   map[*Session]struct{}
+	unpriced   map[string]struct{}
+}
+
+// New creates a Budget with the given limit in USD.
+func New(limitUSD float64) *Budget {
+	return &Budget{
+		limit:    limitUSD,
+		sessions: make(map[*Session]struct{}, 8),
+		unpriced: make(map[string]struct{}),
+	}
+}
+
+// OnThreshold registers fn to run when spend reaches fraction of the limit,
+// e.g. 0.8 to switch running sessions to a cheaper model:
+//
+//	b.OnThreshold(0.8, func(ctx context.Context, s *budget.Session) {
+//	    _ = s.SetModel(ctx, "claude-haiku-4-5")
+//	})
+func (b *Budget) OnThreshold(fraction float64, fn ThresholdFunc) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t := &threshold{fraction: fraction, fn: fn}
+	b.thresholds = append(b.thresholds, t)
+	b.crossLocked()
+}
+
+// Limit returns the budget limit in USD.
+func (b *Budget) Limit() float64 { return b.limit }
+
+// Spent returns the spend recorded so far in USD.
+func (b *Budget) Spent() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.spent
+}
+
+// Remaining returns the spend left in USD, never below zero.
+func (b *Budget) Remaining() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return max(b.limit-b.spent, 0)
+}
+
+// Exhausted reports whether spend has reached the limit.
+func (b *Budget) Exhausted() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.spent >= b.limit
+}
+
+// Unpriced returns the models seen in assistant messages that the price
+// table has no price for, sorted. Their spend is not estimated live.
+func (b *Budget) Unpriced() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return slices.Sorted(maps.Keys(b.unpriced))
+}
+
+// Charge records spend made outside the SDK's sessions.
+func (b *Budget) Charge(usd float64) {
+	b.charge(usd)
+}
+
+// Start admits a new session. It returns ErrBudgetExhausted if no spend is
+// left. A nil Budget admits everything and returns a nil Session, whose
+// methods do nothing.
+func (b *Budget) Start() (*Session, error) {
+	if b == nil {
+		return nil, nil //nolint:nilnil // a nil Session is the no-op session
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.spent >= b.limit {
+		return nil, fmt.Errorf("start session: %w ($%.4f of $%.4f spent)", errors.ErrBudgetExhausted, b.spent, b.limit)
+	}
+
+	s := &Session{
+		budget: b,
+		fired:  make(map[*threshold]struct{}, len(b.thresholds)),
+		seen:   make(map[string]struct{}, 16),
+	}
+	b.sessions[s] = struct{}{}
+
+	return s, nil
+}
+
+// charge adds usd to the spend and fires thresholds and exhaustion.
+func (b *Budget) charge(usd float64) {
+	if usd <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	wasExhausted := b.spent >= b.limit
+	b.spent += usd
+
+	b.crossLocked()
+
+	if !wasExhausted && b.spent >= b.limit {
+		for s := range b.sessions {
+			go s.interrupt()
+		}
+	}
+}
+
+// crossLocked fires thresholds the spend has newly reached. Caller must hold b.mu.
+func (b *Budget) crossLocked() {
+	for _, t := range b.thresholds {
+		if t.crossed || b.spent < t.fraction*b.limit {
+			continue
+		}
+
+		t.crossed = true
+
+		for s := range b.sessions {
+			s.fire(t)
+		}
+	}
+}
+
+// markUnpriced records a model the price table has no price for.
+func (b *Budget) markUnpriced(model string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.unpriced[model] = struct{}{}
+}
+
+func (b *Budget) remove(s *Session) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.sessions, s)
+}
+
+// crossedThresholds returns the thresholds already crossed.
+func (b *Budget) crossedThresholds() []*threshold {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var crossed []*threshold
+
+	for _, t := range b.thresholds {
+		if t.crossed {
+			crossed = append(crossed, t)
+		}
+	}
+
+	return crossed
+}
+
+// Session is one run admitted by a Budget.
+type Session struct {
+	budget *Budget
+
+	mu       sync.Mutex
+	ctx      context.Context
+	controls Controls
+	fired    map[*threshold]struct{}
+	seen     map[string]struct{}
+	spent    float64
+	ended    bool
+}
+
+// Attach connects the session to the run's controls once it is running.
+// Threshold callbacks that fired before Attach run now. ctx is passed to
+// callbacks and interrupts.
+func (s *Session) Attach(ctx context.Context, controls Controls) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.ctx, s.controls = ctx, controls
+	s.mu.Unlock()
+
+	for _, t := range s.budget.crossedThresholds() {
+		s.fire(t)
+	}
+
+	if s.budget.Exhausted() {
+		go s.interrupt()
+	}
+}
+
+// Observe charges the usage of an assistant message. Assistant messages that
+// share an API message ID are charged once. A result message's TotalCostUSD,
+// the CLI's running total for the process, tops up the session's spend when
+// it exceeds the estimate.
+func (s *Session) Observe(msg message.Message) {
+	if s == nil {
+		return
+	}
+
+	var (
+		charge   float64
+		unpriced string
+	)
+
+	s.mu.Lock()
+
+	switch m := msg.(type) {
+	case *message.AssistantMessage:
+		if m.Usage == nil {
+			break
+		}
+
+		if m.ID != "" {
+			if _, dup := s.seen[m.ID]; dup {
+				break
+			}
+
+			s.seen[m.ID] = struct{}{}
+		}
+
+		cost, err := models.EstimateCost(m.Model, *m.Usage)
+		if err != nil {
+			unpriced = m.Model
+
+			break
+		}
+
+		charge = cost.Total()
+	case *message.ResultMessage:
+		if m.TotalCostUSD != nil && *m.TotalCostUSD > s.spent {
+			charge = *m.TotalCostUSD - s.spent
+		}
+	}
+
+	s.spent += charge
+	s.mu.Unlock()
+
+	// The budget's lock is taken after the session's is released, as
+	// threshold callbacks take them in the opposite order
+	if unpriced != "" {
+		s.budget.markUnpriced(unpriced)
+	}
+
+	s.budget.charge(charge)
+}
+
+// Check returns ErrBudgetExhausted if no spend is left. Runs call it before
+// starting each turn.
+func (s *Session) Check() error {
+	if s == nil || !s.budget.Exhausted() {
+		return nil
+	}
+
+	return fmt.Errorf("start turn: %w", errors.ErrBudgetExhausted)
+}
+
+// Spent returns the spend charged by this session in USD.
+func (s *Session) Spent() float64 {
+	if s == nil {
+		return 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.spent
+}
+
+// SetModel switches the session's model. It fails if the session is not
+// attached or its run cannot change models.
+func (s *Session) SetModel(ctx context.Context, model string) error {
+	controls, err := s.attached()
+	if err != nil {
+		return err
+	}
+
+	return controls.SetModel(ctx, &model)
+}
+
+// Interrupt stops the session's current turn.
+func (s *Session) Interrupt(ctx context.Context) error {
+	controls, err := s.attached()
+	if err != nil {
+		return err
+	}
+
+	return controls.Interrupt(ctx)
+}
+
+// End releases the session from the budget. It is safe to call more than once.
+func (s *Session) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.ended = true
+	s.mu.Unlock()
+
+	s.budget.remove(s)
+}
+
+func (s *Session) attached() (Controls, error) {
+	if s == nil {
+		return nil, fmt.Errorf("budget session: not started")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.controls == nil || s.ended {
+		return nil, fmt.Errorf("budget session: not running")
+	}
+
+	return s.controls, nil
+}
+
+// fire runs the callback of t for the session once. Sessions that are not
+// attached yet run it on Attach.
+func (s *Session) fire(t *threshold) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.controls == nil || s.ended {
+		return
+	}
+
+	if _, done := s.fired[t]; done {
+		return
+	}
+
+	s.fired[t] = struct{}{}
+
+	go t.fn(s.ctx, s)
+}
+
+// interrupt stops the session because the budget is exhausted.
+func (s *Session) interrupt() {
+	s.mu.Lock()
+	ctx, controls, ended := s.ctx, s.controls, s.ended
+	s.mu.Unlock()
+
+	if controls == nil || ended {
+		return
+	}
+
+	_ = controls.Interrupt(ctx)
+}
@@ -0,0 +1,155 @@
+package budget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/errors"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/message"
+)
+
+type fakeControls struct {
+	interrupts chan struct{}
+	models     chan string
+}
+
+func newFakeControls() *fakeControls {
+	return &fakeControls{interrupts: make(chan struct{}, 4), models: make(chan string, 4)}
+}
+
+func (f *fakeControls) Interrupt(context.Context) error {
+	f.interrupts <- struct{}{}
+
+	return nil
+}
+
+func (f *fakeControls) SetModel(_ context.Context, model *string) error {
+	f.models <- *model
+
+	return nil
+}
+
+// usage costs $1 on claude-haiku-4-5.
+func assistant(id string) *message.AssistantMessage {
+	return &message.AssistantMessage{
+		ID:    id,
+		Model: "claude-haiku-4-5",
+		Usage: &message.Usage{InputTokens: 1_000_000},
+	}
+}
+
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(time.Second):
+		require.FailNow(t, "timed out waiting for budget action")
+
+		var zero T
+
+		return zero
+	}
+}
+
+func TestBudget_ChargesAndDedupes(t *testing.T) {
+	b := New(10)
+
+	s, err := b.Start()
+	require.NoError(t, err)
+
+	defer s.End()
+
+	s.Observe(assistant("msg_1"))
+	s.Observe(assistant("msg_1"))
+	assert.InDelta(t, 1.0, b.Spent(), 1e-9, "split assistant messages are charged once")
+
+	s.Observe(&message.ResultMessage{TotalCostUSD: new(1.5)})
+	assert.InDelta(t, 1.5, s.Spent(), 1e-9, "reported cost tops up the estimate")
+
+	s.Observe(&message.ResultMessage{TotalCostUSD: new(1.2)})
+	assert.InDelta(t, 1.5, b.Spent(), 1e-9, "lower reported cost is ignored")
+	assert.InDelta(t, 8.5, b.Remaining(), 1e-9)
+	assert.Empty(t, b.Unpriced())
+
+	s.Observe(&message.AssistantMessage{ID: "msg_2", Model: "mystery", Usage: &message.Usage{InputTokens: 1}})
+	assert.Equal(t, []string{"mystery"}, b.Unpriced())
+}
+
+func TestBudget_ExhaustionInterruptsAndRejects(t *testing.T) {
+	b := New(2)
+	controls := newFakeControls()
+
+	s, err := b.Start()
+	require.NoError(t, err)
+
+	defer s.End()
+
+	s.Attach(t.Context(), controls)
+
+	s.Observe(assistant("msg_1"))
+	require.NoError(t, s.Check())
+
+	s.Observe(assistant("msg_2"))
+	receive(t, controls.interrupts)
+	assert.True(t, b.Exhausted())
+	require.ErrorIs(t, s.Check(), errors.ErrBudgetExhausted)
+
+	_, err = b.Start()
+	require.ErrorIs(t, err, errors.ErrBudgetExhausted)
+}
+
+func TestBudget_Thresholds(t *testing.T) {
+	b := New(10)
+	downgraded := make(chan *Session, 4)
+
+	b.OnThreshold(0.2, func(ctx context.Context, s *Session) {
+		assert.NoError(t, s.SetModel(ctx, "claude-haiku-4-5"))
+		downgraded <- s
+	})
+
+	running, err := b.Start()
+	require.NoError(t, err)
+
+	defer running.End()
+
+	controls := newFakeControls()
+	running.Attach(t.Context(), controls)
+
+	other, err := b.Start()
+	require.NoError(t, err)
+
+	defer other.End()
+
+	running.Observe(assistant("msg_1"))
+	running.Observe(assistant("msg_2"))
+	assert.Same(t, running, receive(t, downgraded))
+	assert.Equal(t, "claude-haiku-4-5", receive(t, controls.models))
+
+	running.Observe(assistant("msg_3"))
+	assert.Empty(t, downgraded, "thresholds fire once per session")
+
+	lateControls := newFakeControls()
+	other.Attach(t.Context(), lateControls)
+	assert.Same(t, other, receive(t, downgraded), "sessions attached after a threshold still see it")
+	assert.Equal(t, "claude-haiku-4-5", receive(t, lateControls.models))
+}
+
+func TestBudget_Nil(t *testing.T) {
+	var b *Budget
+
+	s, err := b.Start()
+	require.NoError(t, err)
+	require.Nil(t, s)
+
+	s.Attach(t.Context(), newFakeControls())
+	s.Observe(assistant("msg_1"))
+	require.NoError(t, s.Check())
+	require.Error(t, s.Interrupt(t.Context()))
+	s.End()
+}
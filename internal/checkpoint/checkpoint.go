@@ -0,0 +1,178 @@
+// Package checkpoint records a checkpoint for each prompt of a session so a
+// turn can be undone by rewinding files and forking the conversation.
+package checkpoint
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/message"
+)
+
+// Checkpoint is the state of a session just before a prompt was sent.
+type Checkpoint struct {
+	// UserMessageID is the UUID of the prompt. Rewinding files to it restores
+	// them to their state before the prompt.
+	UserMessageID string
+	// Prompt is the text of the prompt.
+	Prompt string
+	// Time is when the prompt was observed.
+	Time time.Time
+	// SessionID is the session the prompt was sent in.
+	SessionID string
+	// ResumeAt is the UUID of the last assistant message before the prompt,
+	// or empty for the first prompt of the session.
+	ResumeAt string
+}
+
+// Registry records checkpoints from a message stream. A nil *Registry
+// ignores all messages.
+type Registry struct {
+	mu            sync.Mutex
+	now           func() time.Time
+	sessionID     string
+	lastAssistant string
+	checkpoints   []Checkpoint
+}
+
+// New creates an empty registry.
+func New() *Registry {
+	return &Registry{now: time.Now}
+}
+
+// Observe records a checkpoint for each prompt and tracks the assistant
+// messages and session ID it resumes from.
+func (r *Registry) Observe(msg message.Message) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch m := msg.(type) {
+	case *message.SystemMessage:
+		if id, ok := m.Data["session_id"].(string); ok && m.Subtype == "init" {
+			r.sessionID = id
+		}
+	case *message.ResultMessage:
+		if m.SessionID != "" {
+			r.sessionID = m.SessionID
+		}
+	case *message.AssistantMessage:
+		if m.ParentToolUseID == nil && m.UUID != "" {
+			r.lastAssistant = m.UUID
+		}
+	case *message.UserMessage:
+		prompt, ok := promptText(m)
+		if !ok || r.index(*m.UUID) >= 0 {
+			return
+		}
+
+		r.checkpoints = append(r.checkpoints, Checkpoint{
+			UserMessageID: *m.UUID,
+			Prompt:        prompt,
+			Time:          r.now(),
+			SessionID:     r.sessionID,
+			ResumeAt:      r.lastAssistant,
+		})
+	}
+}
+
+// promptText returns the text of a top-level prompt. Tool results and
+// subagent messages are not prompts.
+func promptText(m *message.UserMessage) (string, bool) {
+	if m.UUID == nil || m.ParentToolUseID != nil {
+		return "", false
+	}
+
+	if m.Content.IsString() {
+		return m.Content.String(), true
+	}
+
+	var text []string
+
+	for _, block := range m.Content.Blocks() {
+		switch b := block.(type) {
+		case *message.ToolResultBlock:
+			return "", false
+		case *message.TextBlock:
+			text = append(text, b.Text)
+		}
+	}
+
+	return strings.Join(text, "\n"), true
+}
+
+// index returns the position of the checkpoint with the given ID, or -1.
+func (r *Registry) index(id string) int {
+	return slices.IndexFunc(r.checkpoints, func(cp Checkpoint) bool {
+		return cp.UserMessageID == id
+	})
+}
+
+// List returns all checkpoints, oldest first.
+func (r *Registry) List() []Checkpoint {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.checkpoints)
+}
+
+// Last returns the most recent checkpoint.
+func (r *Registry) Last() (Checkpoint, bool) {
+	if r == nil {
+		return Checkpoint{}, false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.checkpoints) == 0 {
+		return Checkpoint{}, false
+	}
+
+	return r.checkpoints[len(r.checkpoints)-1], true
+}
+
+// Get returns the checkpoint for a prompt UUID.
+func (r *Registry) Get(userMessageID string) (Checkpoint, bool) {
+	if r == nil {
+		return Checkpoint{}, false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.index(userMessageID)
+	if i < 0 {
+		return Checkpoint{}, false
+	}
+
+	return r.checkpoints[i], true
+}
+
+// Truncate removes the checkpoint for a prompt UUID and every later one,
+// after the conversation was rolled back to before that prompt.
+func (r *Registry) Truncate(userMessageID string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.index(userMessageID)
+	if i < 0 {
+		return
+	}
+
+	r.lastAssistant = r.checkpoints[i].ResumeAt
+	r.checkpoints = r.checkpoints[:i]
+}
@@ -0,0 +1,71 @@
+package checkpoint
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/message"
+)
+
+func prompt(uuid, text string) *message.UserMessage {
+	return &message.UserMessage{UUID: &uuid, Content: message.NewUserMessageContent(text)}
+}
+
+func TestRegistry(t *testing.T) {
+	r := New()
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	r.now = func() time.Time { return at }
+
+	r.Observe(&message.SystemMessage{Subtype: "init", Data: map[string]any{"session_id": "s1"}})
+	r.Observe(prompt("u1", "first"))
+	r.Observe(&message.AssistantMessage{UUID: "a1"})
+	r.Observe(&message.AssistantMessage{UUID: "sub", ParentToolUseID: new("toolu_1")})
+	r.Observe(&message.UserMessage{
+		UUID:    new("r1"),
+		Content: message.NewUserMessageContentBlocks([]message.ContentBlock{&message.ToolResultBlock{ToolUseID: "toolu_1"}}),
+	})
+	r.Observe(&message.AssistantMessage{UUID: "a2"})
+	r.Observe(&message.ResultMessage{SessionID: "s1"})
+	r.Observe(prompt("u2", "second"))
+	r.Observe(prompt("u2", "second"))
+	r.Observe(&message.UserMessage{Content: message.NewUserMessageContent("no uuid")})
+
+	list := r.List()
+	require.Len(t, list, 2)
+	assert.Equal(t, Checkpoint{UserMessageID: "u1", Prompt: "first", Time: at, SessionID: "s1"}, list[0])
+	assert.Equal(t, "a2", list[1].ResumeAt)
+
+	last, ok := r.Last()
+	require.True(t, ok)
+	assert.Equal(t, "u2", last.UserMessageID)
+
+	_, ok = r.Get("r1")
+	assert.False(t, ok)
+
+	r.Truncate("u2")
+	assert.Len(t, r.List(), 1)
+
+	r.Observe(prompt("u3", "retry"))
+
+	last, ok = r.Last()
+	require.True(t, ok)
+	assert.Equal(t, "a2", last.ResumeAt, "a truncated prompt resumes from the same point")
+
+	r.Truncate("u1")
+	_, ok = r.Last()
+	assert.False(t, ok)
+}
+
+func TestRegistryNil(t *testing.T) {
+	var r *Registry
+
+	r.Observe(prompt("u1", "x"))
+	r.Truncate("u1")
+	assert.Nil(t, r.List())
+
+	_, ok := r.Last()
+	assert.False(t, ok)
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/checkpoint"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/config"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/errors"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/mcp"
@@ -179,6 +180,31 @@ func TestBuildArgs_SessionContinuation(t *testing.T) {
 		require.Contains(t, args, "--resume")
 		require.Contains(t, args, "--fork-session")
 	})
+
+	t.Run("resume session at", func(t *testing.T) {
+		options := &config.Options{
+			Resume:          "session_xyz",
+			ForkSession:     true,
+			ResumeSessionAt: "msg-uuid",
+		}
+
+		args := BuildArgs("test", options, false)
+
+		idx := slices.Index(args, "--resume-session-at")
+		require.GreaterOrEqual(t, idx, 0)
+		require.Equal(t, "msg-uuid", args[idx+1])
+		require.NotContains(t, args, "--replay-user-messages")
+	})
+
+	t.Run("checkpoints replay user messages", func(t *testing.T) {
+		options := &config.Options{
+			Checkpoints: checkpoint.New(),
+		}
+
+		args := BuildArgs("test", options, false)
+
+		require.Contains(t, args, "--replay-user-messages")
+	})
 }
 
 // TestBuildArgs_WithSettingsFile tests settings file option.
@@ -368,7 +394,8 @@ func TestBuildEnvironment_EnvVarsPassedToSubprocess(t *testing.T) {
 		},
 	}
 
-	env := BuildEnvironment(options)
+	env, err := BuildEnvironment(options)
+	require.NoError(t, err)
 	require.NotNil(t, env)
 
 	require.True(t, slices.Contains(env, "CUSTOM_VAR=custom_value"),
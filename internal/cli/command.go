@@ -3,7 +3,9 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -46,7 +48,7 @@ func BuildArgs(
 	}
 
 	if options.Model != "" {
-		args = append(args, "--model", options.Model)
+		args = append(args, "--model", modelID(options, options.Model))
 	}
 
 	// System prompt - always set this flag (empty string when not provided)
@@ -104,9 +106,14 @@ func BuildArgs(
 		}
 	}
 
-	// Settings with sandbox merged
-	// Note: sandbox is merged into --settings JSON, not passed as separate --sandbox flag
-	settingsValue := buildSettingsValue(options)
+	// Settings with typed and sandbox settings merged
+	// Note: sandbox is merged into --settings JSON, not passed as separate --sandbox flag.
+	// Start reports settings errors; here they fall back to the raw value.
+	settingsValue, err := BuildSettings(options)
+	if err != nil {
+		settingsValue = options.Settings
+	}
+
 	if settingsValue != "" {
 		args = append(args, "--settings", settingsValue)
 	}
@@ -140,7 +147,7 @@ func BuildArgs(
 
 	// Fallback model
 	if options.FallbackModel != "" {
-		args = append(args, "--fallback-model", options.FallbackModel)
+		args = append(args, "--fallback-model", modelID(options, options.FallbackModel))
 	}
 
 	// Betas (comma-separated, single flag)
@@ -159,7 +166,7 @@ func BuildArgs(
 		args = append(args, "--permission-prompt-tool", options.PermissionPromptToolName)
 	}
 
-	// Note: --settings is handled earlier via buildSettingsValue which merges sandbox settings
+	// Note: --settings is handled earlier via BuildSettings which merges typed and sandbox settings
 
 	// Additional directories
 	for _, dir := range options.AddDirs {
@@ -181,6 +188,16 @@ func BuildArgs(
 		args = append(args, "--fork-session")
 	}
 
+	if options.ResumeSessionAt != "" {
+		args = append(args, "--resume-session-at", options.ResumeSessionAt)
+	}
+
+	// Checkpoints are keyed by prompt UUIDs, which the CLI only reports
+	// when it replays user messages
+	if options.Checkpoints != nil {
+		args = append(args, "--replay-user-messages")
+	}
+
 	// Note: Agents are sent via the initialize control request, not CLI flags.
 	// This avoids platform-specific ARG_MAX limits for large agent definitions.
 
@@ -234,46 +251,6 @@ func BuildArgs(
 	return args
 }
 
-// buildSettingsValue constructs the --settings CLI argument value.
-// It merges sandbox settings into the settings JSON object.
-func buildSettingsValue(options *config.Options) string {
-	hasSettings := options.Settings != ""
-	hasSandbox := options.SandboxSettings != nil
-
-	if !hasSettings && !hasSandbox {
-		return ""
-	}
-
-	// If only settings path and no sandbox, pass through as-is
-	if hasSettings && !hasSandbox {
-		return options.Settings
-	}
-
-	// If we have sandbox settings, merge into JSON object
-	settingsObj := make(map[string]any, 2)
-
-	if hasSettings {
-		// Check if settings is JSON string or file path
-		settingsStr := strings.TrimSpace(options.Settings)
-		if strings.HasPrefix(settingsStr, "{") && strings.HasSuffix(settingsStr, "}") {
-			// Parse existing JSON settings
-			_ = json.Unmarshal([]byte(settingsStr), &settingsObj)
-		}
-		// Note: file path reading would need to be added for file paths if needed
-	}
-
-	if hasSandbox {
-		settingsObj["sandbox"] = options.SandboxSettings
-	}
-
-	result, err := json.Marshal(settingsObj)
-	if err != nil {
-		return ""
-	}
-
-	return string(result)
-}
-
 // extractJSONSchema extracts the inner JSON schema from an OutputFormat map.
 // It supports two formats:
 //   - Wrapped: {"type": "json_schema", "schema": {...}} — returns the inner schema.
@@ -301,9 +278,12 @@ func extractJSONSchema(outputFormat map[string]any) map[string]any {
 }
 
 // BuildEnvironment constructs the environment variables for the CLI process.
-func BuildEnvironment(options *config.Options) []string {
-	// Start with current environment
-	env := os.Environ()
+// Host variables are filtered by options.EnvPolicy, options.Provider adds its
+// configuration, and the result is checked for missing required variables
+// and conflicting provider flags.
+func BuildEnvironment(options *config.Options) ([]string, error) {
+	// Start with the host environment the policy allows
+	env := hostEnvironment(options.EnvPolicy, os.Environ())
 
 	// Add SDK-specific environment variables
 	env = append(env, "CLAUDE_AGENT_SDK_VERSION=0.1.0")
@@ -314,10 +294,31 @@ func BuildEnvironment(options *config.Options) []string {
 		env = append(env, "CLAUDE_CODE_ENABLE_SDK_FILE_CHECKPOINTING=true")
 	}
 
+	// Configure the provider
+	if options.Provider != nil {
+		if err := options.Provider.Validate(); err != nil {
+			return nil, err
+		}
+
+		providerEnv := options.Provider.Environment()
+		for _, key := range slices.Sorted(maps.Keys(providerEnv)) {
+			env = append(env, key+"="+providerEnv[key])
+		}
+	}
+
 	// Add or override with user-provided environment variables
 	for key, value := range options.Env {
 		env = append(env, fmt.Sprintf("%s=%s", key, value))
 	}
 
-	return env
+	if err := validateEnvironment(options.EnvPolicy, env); err != nil {
+		return nil, err
+	}
+
+	return env, nil
+}
+
+// modelID maps a model to the configured provider's ID.
+func modelID(options *config.Options, model string) string {
+	return config.ModelID(options.Provider, model)
 }
@@ -30,5 +30,5 @@
 // The package provides functions to build CLI command arguments and environment:
 //
 //	args := cli.BuildArgs("prompt", options, isStreaming)
-//	env := cli.BuildEnvironment(options)
+//	env, err := cli.BuildEnvironment(options)
 package cli
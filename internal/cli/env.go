@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/config"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/errors"
+)
+
+// cliEnv are the CLI's own configuration variables. They are passed in
+// allowlist mode and never redacted.
+var cliEnv = []string{"ANTHROPIC_*", "CLAUDE_*"}
+
+// baseEnv are the host variables the CLI needs to run, passed in allowlist
+// mode.
+var baseEnv = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TMPDIR", "TEMP", "TMP",
+	"LANG", "LC_*", "TERM", "TZ", "SYSTEMROOT", "USERPROFILE", "APPDATA",
+}
+
+// foldNames reports whether variable names are case-insensitive, as they are
+// on Windows, which reports names such as Path and SystemRoot.
+var foldNames = runtime.GOOS == "windows"
+
+// providerFlags select the API provider; at most one may be enabled.
+var providerFlags = []string{
+	"CLAUDE_CODE_USE_BEDROCK",
+	"CLAUDE_CODE_USE_VERTEX",
+	"CLAUDE_CODE_USE_FOUNDRY",
+}
+
+// hostEnvironment returns the variables of environ the policy lets the CLI
+// inherit.
+func hostEnvironment(policy *config.EnvPolicy, environ []string) []string {
+	if policy == nil {
+		return environ
+	}
+
+	secrets := policy.SecretPatterns
+	if secrets == nil {
+		secrets = config.DefaultSecretPatterns
+	}
+
+	// Secret patterns match case-insensitively
+	secrets = slices.Clone(secrets)
+	for i, pattern := range secrets {
+		secrets[i] = strings.ToUpper(pattern)
+	}
+
+	env := make([]string, 0, len(environ))
+
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+
+		switch {
+		case policy.Mode == config.EnvClean:
+			continue
+		case policy.Mode == config.EnvAllowlist &&
+			!matchAny(cliEnv, name) && !matchAny(baseEnv, name) && !matchAny(policy.Allow, name):
+			continue
+		case policy.RedactSecrets && matchAny(secrets, strings.ToUpper(name)) && !matchAny(cliEnv, name) &&
+			!containsName(policy.Allow, name) && !containsName(policy.Required, name):
+			continue
+		}
+
+		env = append(env, kv)
+	}
+
+	return env
+}
+
+// validateEnvironment checks the final CLI environment for missing required
+// variables and conflicting settings.
+func validateEnvironment(policy *config.EnvPolicy, env []string) error {
+	// Later entries win, as they do for the subprocess
+	values := make(map[string]string, len(env))
+
+	for _, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+		values[envKey(name)] = value
+	}
+
+	if policy != nil {
+		var missing []string
+
+		for _, name := range policy.Required {
+			if values[envKey(name)] == "" {
+				missing = append(missing, name)
+			}
+		}
+
+		if len(missing) > 0 {
+			return fmt.Errorf("%w: missing required variables %s",
+				errors.ErrInvalidEnvironment, strings.Join(missing, ", "))
+		}
+	}
+
+	var providers []string
+
+	for _, name := range providerFlags {
+		if enabled(values[envKey(name)]) {
+			providers = append(providers, name)
+		}
+	}
+
+	if len(providers) > 1 {
+		return fmt.Errorf("%w: only one provider can be enabled, got %s",
+			errors.ErrInvalidEnvironment, strings.Join(providers, ", "))
+	}
+
+	return nil
+}
+
+// matchAny reports whether name matches any of the path.Match patterns.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(envKey(pattern), envKey(name)); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsName reports whether names contains name.
+func containsName(names []string, name string) bool {
+	return slices.ContainsFunc(names, func(n string) bool { return envKey(n) == envKey(name) })
+}
+
+// envKey returns the form of a variable name used to compare it, upper case
+// where names are case-insensitive.
+func envKey(name string) string {
+	if foldNames {
+		return strings.ToUpper(name)
+	}
+
+	return name
+}
+
+// enabled reports whether a flag variable is set to a true value.
+func enabled(value string) bool {
+	switch strings.ToLower(value) {
+	case "", "0", "false", "no", "off":
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/config"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/errors"
+)
+
+var hostEnv = []string{
+	"PATH=/usr/bin",
+	"HOME=/home/svc",
+	"LC_ALL=C",
+	"ANTHROPIC_API_KEY=sk-ant",
+	"CLAUDE_CODE_USE_BEDROCK=1",
+	"AWS_REGION=us-east-1",
+	"AWS_SECRET_ACCESS_KEY=aws-secret",
+	"DATABASE_URL=postgres://u:p@db/app",
+	"pg_password=hunter2",
+	"GITHUB_TOKEN=ghp",
+	"APP_MODE=prod",
+}
+
+func TestHostEnvironment(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *config.EnvPolicy
+		want   []string
+	}{
+		{
+			name: "nil policy inherits everything",
+			want: hostEnv,
+		},
+		{
+			name:   "clean",
+			policy: &config.EnvPolicy{Mode: config.EnvClean},
+			want:   []string{},
+		},
+		{
+			name:   "allowlist",
+			policy: &config.EnvPolicy{Mode: config.EnvAllowlist, Allow: []string{"AWS_*"}},
+			want: []string{
+				"PATH=/usr/bin", "HOME=/home/svc", "LC_ALL=C", "ANTHROPIC_API_KEY=sk-ant",
+				"CLAUDE_CODE_USE_BEDROCK=1", "AWS_REGION=us-east-1", "AWS_SECRET_ACCESS_KEY=aws-secret",
+			},
+		},
+		{
+			name:   "redaction keeps CLI and explicitly allowed variables",
+			policy: &config.EnvPolicy{RedactSecrets: true, Allow: []string{"GITHUB_TOKEN"}},
+			want: []string{
+				"PATH=/usr/bin", "HOME=/home/svc", "LC_ALL=C", "ANTHROPIC_API_KEY=sk-ant",
+				"CLAUDE_CODE_USE_BEDROCK=1", "AWS_REGION=us-east-1", "GITHUB_TOKEN=ghp", "APP_MODE=prod",
+			},
+		},
+		{
+			name: "allowlist with redaction",
+			policy: &config.EnvPolicy{
+				Mode: config.EnvAllowlist, Allow: []string{"AWS_*"}, RedactSecrets: true,
+			},
+			want: []string{
+				"PATH=/usr/bin", "HOME=/home/svc", "LC_ALL=C", "ANTHROPIC_API_KEY=sk-ant",
+				"CLAUDE_CODE_USE_BEDROCK=1", "AWS_REGION=us-east-1",
+			},
+		},
+		{
+			name:   "custom secret patterns",
+			policy: &config.EnvPolicy{RedactSecrets: true, SecretPatterns: []string{"app_*"}},
+			want:   hostEnv[:len(hostEnv)-1],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, hostEnvironment(tt.policy, hostEnv))
+		})
+	}
+}
+
+func TestHostEnvironment_FoldedNames(t *testing.T) {
+	foldNames = true
+
+	t.Cleanup(func() { foldNames = false })
+
+	environ := []string{"Path=C:\\Windows", "SystemRoot=C:\\Windows", "AppData=C:\\AppData", "Api_Token=x"}
+	policy := &config.EnvPolicy{Mode: config.EnvAllowlist, Allow: []string{"API_TOKEN"}, RedactSecrets: true}
+
+	assert.Equal(t, environ, hostEnvironment(policy, environ))
+	require.NoError(t, validateEnvironment(&config.EnvPolicy{Required: []string{"PATH"}}, environ))
+}
+
+func TestValidateEnvironment(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *config.EnvPolicy
+		env     []string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			env:  []string{"CLAUDE_CODE_USE_BEDROCK=1", "CLAUDE_CODE_USE_VERTEX=0"},
+		},
+		{
+			name:    "conflicting providers",
+			env:     []string{"CLAUDE_CODE_USE_BEDROCK=1", "CLAUDE_CODE_USE_VERTEX=true"},
+			wantErr: "only one provider can be enabled, got CLAUDE_CODE_USE_BEDROCK, CLAUDE_CODE_USE_VERTEX",
+		},
+		{
+			name: "later values win",
+			env:  []string{"CLAUDE_CODE_USE_BEDROCK=1", "CLAUDE_CODE_USE_VERTEX=1", "CLAUDE_CODE_USE_BEDROCK="},
+		},
+		{
+			name:    "missing required",
+			policy:  &config.EnvPolicy{Required: []string{"ANTHROPIC_API_KEY", "HOME", "PATH"}},
+			env:     []string{"HOME=/home/svc", "PATH="},
+			wantErr: "missing required variables ANTHROPIC_API_KEY, PATH",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateEnvironment(tt.policy, tt.env)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, errors.ErrInvalidEnvironment)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestBuildEnvironment_Policy(t *testing.T) {
+	t.Setenv("SERVICE_DB_PASSWORD", "hunter2")
+
+	env, err := BuildEnvironment(&config.Options{
+		EnvPolicy: &config.EnvPolicy{RedactSecrets: true, Required: []string{"AGENT_TOKEN"}},
+		Env:       map[string]string{"AGENT_TOKEN": "scoped"},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, env, "AGENT_TOKEN=scoped", "explicit variables are never redacted")
+	assert.NotContains(t, env, "SERVICE_DB_PASSWORD=hunter2")
+	assert.Contains(t, env, "CLAUDE_CODE_ENTRYPOINT=sdk-go")
+
+	_, err = BuildEnvironment(&config.Options{
+		EnvPolicy: &config.EnvPolicy{Mode: config.EnvClean, Required: []string{"PATH"}},
+	})
+	require.ErrorIs(t, err, errors.ErrInvalidEnvironment)
+}
+
+func TestBuildEnvironment_Provider(t *testing.T) {
+	t.Setenv("CLAUDE_CODE_USE_VERTEX", "1")
+
+	env, err := BuildEnvironment(&config.Options{
+		Provider: config.BedrockProvider{Region: "eu-west-1"},
+		Env:      map[string]string{"AWS_REGION": "eu-central-1"},
+	})
+	require.NoError(t, err, "the provider overrides inherited provider flags")
+	assert.Contains(t, env, "CLAUDE_CODE_USE_BEDROCK=1")
+	assert.Contains(t, env, "CLAUDE_CODE_USE_VERTEX=0")
+	assert.Equal(t, "AWS_REGION=eu-central-1", env[len(env)-1], "explicit variables win")
+
+	_, err = BuildEnvironment(&config.Options{Provider: config.VertexProvider{Region: "global"}})
+	require.ErrorIs(t, err, errors.ErrInvalidProvider)
+}
+
+func TestBuildArgs_ProviderModelIDs(t *testing.T) {
+	args := BuildArgs("test", &config.Options{
+		Provider:      config.BedrockProvider{Region: "us-east-1", InferenceProfile: "us"},
+		Model:         "claude-sonnet-4-5",
+		FallbackModel: "haiku",
+	}, false)
+
+	assert.Contains(t, args, "us.anthropic.claude-sonnet-4-5-20250929-v1:0")
+	assert.Contains(t, args, "us.anthropic.claude-haiku-4-5-20251001-v1:0")
+}
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/config"
+)
+
+// BuildSettings returns the value of the --settings flag.
+//
+// options.Settings is passed unchanged when it is the only source.
+// Otherwise the settings file or JSON, options.TypedSettings and
+// options.SandboxSettings are merged, in that order of precedence, into
+// one JSON object: objects are merged key by key, lists are concatenated
+// without duplicates, and other values are replaced.
+func BuildSettings(options *config.Options) (string, error) {
+	if options.TypedSettings == nil && options.SandboxSettings == nil {
+		return options.Settings, nil
+	}
+
+	settings := make(map[string]any)
+
+	if options.Settings != "" {
+		base, err := loadSettings(options)
+		if err != nil {
+			return "", err
+		}
+
+		mergeSettings(settings, base)
+	}
+
+	if typed := options.TypedSettings; typed != nil {
+		// Typed fields take precedence over Extra
+		extra, err := toSettingsObject(typed.Extra)
+		if err != nil {
+			return "", err
+		}
+
+		mergeSettings(settings, extra)
+
+		resolved := *typed
+		if resolved.Model != "" {
+			resolved.Model = modelID(options, resolved.Model)
+		}
+
+		obj, err := toSettingsObject(resolved)
+		if err != nil {
+			return "", err
+		}
+
+		mergeSettings(settings, obj)
+	}
+
+	if options.SandboxSettings != nil {
+		obj, err := toSettingsObject(map[string]any{"sandbox": options.SandboxSettings})
+		if err != nil {
+			return "", err
+		}
+
+		mergeSettings(settings, obj)
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return "", fmt.Errorf("encode settings: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// loadSettings parses options.Settings, which is settings JSON or the path
+// of a settings file.
+func loadSettings(options *config.Options) (map[string]any, error) {
+	data := []byte(strings.TrimSpace(options.Settings))
+
+	if !isJSONObject(string(data)) {
+		path := options.Settings
+		if !filepath.IsAbs(path) && options.Cwd != "" {
+			path = filepath.Join(options.Cwd, path)
+		}
+
+		var err error
+
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("read settings file: %w", err)
+		}
+	}
+
+	var settings map[string]any
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("parse settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+// isJSONObject reports whether s looks like a JSON object rather than a path.
+func isJSONObject(s string) bool {
+	return strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}")
+}
+
+// toSettingsObject converts v to its generic JSON object form.
+func toSettingsObject(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encode settings: %w", err)
+	}
+
+	var obj map[string]any
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("encode settings: %w", err)
+	}
+
+	return obj, nil
+}
+
+// mergeSettings merges src into dst: objects are merged recursively, lists
+// are concatenated without duplicates, and other values are replaced.
+func mergeSettings(dst, src map[string]any) {
+	for key, value := range src {
+		switch v := value.(type) {
+		case map[string]any:
+			if existing, ok := dst[key].(map[string]any); ok {
+				mergeSettings(existing, v)
+
+				continue
+			}
+		case []any:
+			if existing, ok := dst[key].([]any); ok {
+				for _, item := range v {
+					if !slices.ContainsFunc(existing, func(e any) bool { return reflect.DeepEqual(e, item) }) {
+						existing = append(existing, item)
+					}
+				}
+
+				dst[key] = existing
+
+				continue
+			}
+		}
+
+		dst[key] = value
+	}
+}
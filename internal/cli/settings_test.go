@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/config"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/hook"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/sandbox"
+)
+
+func TestBuildSettings_PassThrough(t *testing.T) {
+	value, err := BuildSettings(&config.Options{Settings: "missing.json"})
+	require.NoError(t, err, "a lone settings path is left to the CLI")
+	assert.Equal(t, "missing.json", value)
+
+	value, err = BuildSettings(&config.Options{})
+	require.NoError(t, err)
+	assert.Empty(t, value)
+}
+
+func TestBuildSettings_Merge(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "settings.json"), []byte(`{
+		"model": "claude-opus-4-1",
+		"permissions": {"allow": ["Bash(git diff:*)"], "deny": ["Read(./.env)"]},
+		"env": {"TEAM": "core", "LOG": "info"},
+		"sandbox": {"enabled": false, "excludedCommands": ["docker"]},
+		"includeCoAuthoredBy": false
+	}`), 0o600))
+
+	enabled := true
+	value, err := BuildSettings(&config.Options{
+		Cwd:      dir,
+		Settings: "settings.json",
+		Provider: config.VertexProvider{ProjectID: "p", Region: "global"},
+		TypedSettings: &config.Settings{
+			Model: "claude-sonnet-4-5",
+			Permissions: &config.PermissionSettings{
+				Deny: []string{"Read(./.env)", "Bash(curl:*)"},
+				Ask:  []string{"Bash(git push:*)"},
+			},
+			Env: map[string]string{"LOG": "debug"},
+			Hooks: map[hook.Event][]*config.CommandHookMatcher{
+				hook.EventPreToolUse: {{Matcher: "Bash", Hooks: []*config.CommandHook{{Command: "audit.sh", Timeout: 5}}}},
+			},
+			Sandbox: &sandbox.Settings{ExcludedCommands: []string{"git"}},
+			Extra:   map[string]any{"cleanupPeriodDays": 7, "model": "ignored"},
+		},
+		SandboxSettings: &sandbox.Settings{Enabled: &enabled},
+	})
+	require.NoError(t, err)
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal([]byte(value), &got))
+
+	assert.Equal(t, map[string]any{
+		"model": "claude-sonnet-4-5@20250929",
+		"permissions": map[string]any{
+			"allow": []any{"Bash(git diff:*)"},
+			"deny":  []any{"Read(./.env)", "Bash(curl:*)"},
+			"ask":   []any{"Bash(git push:*)"},
+		},
+		"env": map[string]any{"TEAM": "core", "LOG": "debug"},
+		"hooks": map[string]any{"PreToolUse": []any{map[string]any{
+			"matcher": "Bash",
+			"hooks":   []any{map[string]any{"type": "command", "command": "audit.sh", "timeout": float64(5)}},
+		}}},
+		"sandbox":             map[string]any{"enabled": true, "excludedCommands": []any{"docker", "git"}},
+		"includeCoAuthoredBy": false,
+		"cleanupPeriodDays":   float64(7),
+	}, got)
+}
+
+func TestBuildSettings_JSONWithSandbox(t *testing.T) {
+	enabled := true
+	value, err := BuildSettings(&config.Options{
+		Settings:        `{"permissions": {"deny": ["WebFetch"]}}`,
+		SandboxSettings: &sandbox.Settings{Enabled: &enabled},
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"permissions": {"deny": ["WebFetch"]}, "sandbox": {"enabled": true}}`, value)
+}
+
+func TestBuildSettings_Errors(t *testing.T) {
+	enabled := true
+	sandboxed := &sandbox.Settings{Enabled: &enabled}
+
+	_, err := BuildSettings(&config.Options{Settings: filepath.Join(t.TempDir(), "missing.json"), SandboxSettings: sandboxed})
+	require.ErrorContains(t, err, "read settings file")
+
+	_, err = BuildSettings(&config.Options{Settings: `{"permissions": }`, SandboxSettings: sandboxed})
+	require.ErrorContains(t, err, "parse settings")
+}
@@ -13,12 +13,18 @@ import (
 
 	"golang.org/x/sync/errgroup"
 
+	"github.com/wagiedev/claude-agent-sdk-go/internal/budget"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/checkpoint"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/config"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/errors"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/hook"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/mcp"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/message"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/protocol"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/ratelimit"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/retry"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/subprocess"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/telemetry"
 )
 
 const (
@@ -48,6 +54,9 @@ type Client struct {
 	controller *protocol.Controller
 	session    *protocol.Session
 	options    *config.Options
+	recorder   *telemetry.Recorder
+	spend      *budget.Session
+	permit     *ratelimit.Permit
 
 	// Message channel for data flow
 	messages chan message.Message
@@ -83,11 +92,18 @@ func (c *Client) setFatalError(err error) {
 		return
 	}
 
+	err = retry.Classify(err)
+
 	c.errMu.Lock()
 	defer c.errMu.Unlock()
 
 	if c.fatalErr == nil {
 		c.fatalErr = err
+		c.recorder.Error(err)
+
+		if c.options != nil {
+			c.options.CircuitBreaker.Error(err)
+		}
 	}
 }
 
@@ -110,7 +126,7 @@ func (c *Client) isConnected() bool {
 
 // initializeCore performs common client initialization.
 // Caller must hold c.mu lock. Lock is held on return.
-func (c *Client) initializeCore(ctx context.Context, options *config.Options) error {
+func (c *Client) initializeCore(ctx context.Context, options *config.Options) (err error) {
 	// Default to empty options if nil
 	if options == nil {
 		options = &config.Options{}
@@ -139,6 +155,44 @@ func (c *Client) initializeCore(ctx context.Context, options *config.Options) er
 	// Store options for callback handlers
 	c.options = options
 
+	// Trace the session from connect to Close
+	c.recorder = telemetry.New(options.TracerProvider, options.Metrics, options.Model)
+	ctx = c.recorder.StartSession(ctx, "client")
+
+	defer func() {
+		if err != nil {
+			c.recorder.Error(err)
+			c.recorder.EndSession()
+		}
+	}()
+
+	c.spend, err = options.Budget.Start()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			c.spend.End()
+		}
+	}()
+
+	c.permit, err = options.RateLimiter.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			options.CircuitBreaker.Error(err)
+			c.permit.Release()
+		}
+	}()
+
+	if err := options.CircuitBreaker.Allow(); err != nil {
+		return err
+	}
+
 	// Create or use injected transport
 	var transport config.Transport
 
@@ -156,9 +210,12 @@ func (c *Client) initializeCore(ctx context.Context, options *config.Options) er
 	}
 
 	c.transport = transport
+	c.recorder.ProcessStarted(options.Resume != "" || options.ContinueConversation)
 
 	// Create protocol controller for bidirectional communication
 	c.controller = protocol.NewController(c.log, transport)
+	c.controller.SetRecorder(c.recorder)
+
 	if err := c.controller.Start(ctx); err != nil {
 		transport.Close()
 
@@ -177,6 +234,8 @@ func (c *Client) initializeCore(ctx context.Context, options *config.Options) er
 		return fmt.Errorf("initialize session: %w", err)
 	}
 
+	c.spend.Attach(context.WithoutCancel(ctx), c)
+
 	return nil
 }
 
@@ -385,6 +444,16 @@ func (c *Client) readLoop(ctx context.Context) error {
 				return fmt.Errorf("parse message: %w", err)
 			}
 
+			c.recorder.Message(parsed)
+			c.spend.Observe(parsed)
+			c.permit.Observe(parsed)
+			c.options.CircuitBreaker.Observe(parsed)
+			c.options.Checkpoints.Observe(parsed)
+
+			if _, isResult := parsed.(*message.ResultMessage); isResult {
+				c.session.PermissionCache().EndTurn()
+			}
+
 			select {
 			case c.messages <- parsed:
 			case <-c.done:
@@ -446,6 +515,12 @@ func (c *Client) Query(ctx context.Context, prompt string, sessionID ...string)
 		return fmt.Errorf("marshal query: %w", err)
 	}
 
+	if err := c.spend.Check(); err != nil {
+		return err
+	}
+
+	c.recorder.StartTurn()
+
 	return c.transport.SendMessage(ctx, data)
 }
 
@@ -549,6 +624,92 @@ func (c *Client) RewindFiles(ctx context.Context, userMessageID string) error {
 	return nil
 }
 
+// UndoLastTurn rolls the session back to before its last prompt. It rewinds
+// files to the last checkpoint, then starts a new client that forks the
+// session at the last assistant message before that prompt. The caller takes
+// ownership of the new client and should close this one.
+//
+// ctx bounds the undo only: the new client's CLI process keeps running after
+// ctx is done, until the new client is closed. If the fork fails after files
+// were rewound, the error wraps ErrUndoIncomplete and the checkpoint is
+// returned; this client and its checkpoints are unchanged, so the undo can
+// be retried.
+func (c *Client) UndoLastTurn(ctx context.Context) (*Client, *checkpoint.Checkpoint, error) {
+	if !c.isConnected() {
+		return nil, nil, errors.ErrClientNotConnected
+	}
+
+	c.mu.Lock()
+	options := c.options
+	c.mu.Unlock()
+
+	if options.Checkpoints == nil {
+		return nil, nil, errors.ErrCheckpointsDisabled
+	}
+
+	cp, ok := options.Checkpoints.Last()
+	if !ok {
+		return nil, nil, errors.ErrNoCheckpoint
+	}
+
+	c.log.Info("Undoing last turn", "user_message_id", cp.UserMessageID, "resume_at", cp.ResumeAt)
+
+	if err := c.RewindFiles(ctx, cp.UserMessageID); err != nil {
+		return nil, nil, err
+	}
+
+	// The forked CLI process must outlive the undo call, so ctx only cancels
+	// the start while it is in progress
+	startCtx, stop := context.WithCancel(context.WithoutCancel(ctx))
+	unwatch := context.AfterFunc(ctx, stop)
+
+	next := New()
+	err := next.Start(startCtx, forkOptions(options, &cp))
+
+	if !unwatch() && err == nil {
+		// ctx ended as the start finished, which kills the new CLI process
+		_ = next.Close()
+		err = context.Cause(ctx)
+	}
+
+	if err != nil {
+		stop()
+
+		return nil, &cp, fmt.Errorf("%w to %s: fork session: %w", errors.ErrUndoIncomplete, cp.UserMessageID, err)
+	}
+
+	options.Checkpoints.Truncate(cp.UserMessageID)
+
+	return next, &cp, nil
+}
+
+// forkOptions returns a copy of options that forks the session of cp at the
+// assistant message before it. Without an earlier assistant message the
+// prompt was the first of this client, so the original resume point is kept.
+func forkOptions(options *config.Options, cp *checkpoint.Checkpoint) *config.Options {
+	fork := *options
+
+	// initializeCore set this for CanUseTool and rejects it when already set
+	if fork.CanUseTool != nil {
+		fork.PermissionPromptToolName = ""
+	}
+
+	// The continued session is no longer the most recent one
+	fork.ContinueConversation = false
+
+	if cp.ResumeAt == "" {
+		fork.ForkSession = fork.Resume != ""
+
+		return &fork
+	}
+
+	fork.Resume = cp.SessionID
+	fork.ForkSession = true
+	fork.ResumeSessionAt = cp.ResumeAt
+
+	return &fork
+}
+
 // SetPermissionMode changes the permission mode during conversation.
 // Valid modes: "default", "acceptEdits", "plan", "bypassPermissions".
 func (c *Client) SetPermissionMode(ctx context.Context, mode string) error {
@@ -572,7 +733,8 @@ func (c *Client) SetPermissionMode(ctx context.Context, mode string) error {
 	return nil
 }
 
-// SetModel changes the AI model during conversation.
+// SetModel changes the AI model during conversation. The model is mapped
+// to the configured provider's ID, as for the initial model.
 //
 // Pass nil to use the default model.
 func (c *Client) SetModel(ctx context.Context, model *string) error {
@@ -580,6 +742,10 @@ func (c *Client) SetModel(ctx context.Context, model *string) error {
 		return errors.ErrClientNotConnected
 	}
 
+	if model != nil {
+		model = new(config.ModelID(c.options.Provider, *model))
+	}
+
 	c.log.Info("Setting model", "model", model)
 
 	payload := map[string]any{
@@ -637,6 +803,57 @@ func (c *Client) GetMCPStatus(ctx context.Context) (*mcp.Status, error) {
 	return &status, nil
 }
 
+// AddHook registers a hook matcher for event on the running session and
+// returns an ID for RemoveHook. The client must have been started with a hook
+// registry that includes event.
+func (c *Client) AddHook(event hook.Event, matcher *hook.Matcher) (string, error) {
+	registry, err := c.hookRegistry()
+	if err != nil {
+		return "", err
+	}
+
+	id, err := registry.Add(event, matcher)
+	if err != nil {
+		return "", err
+	}
+
+	c.log.Debug("Added hook", "event", event, "hook_id", id)
+
+	return id, nil
+}
+
+// RemoveHook unregisters a hook added with AddHook.
+// It returns false if no hook with the ID is registered.
+func (c *Client) RemoveHook(id string) (bool, error) {
+	registry, err := c.hookRegistry()
+	if err != nil {
+		return false, err
+	}
+
+	removed := registry.Remove(id)
+	if removed {
+		c.log.Debug("Removed hook", "hook_id", id)
+	}
+
+	return removed, nil
+}
+
+// hookRegistry returns the hook registry of the running session.
+func (c *Client) hookRegistry() (*hook.Registry, error) {
+	if !c.isConnected() {
+		return nil, errors.ErrClientNotConnected
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.options == nil || c.options.HookRegistry == nil {
+		return nil, errors.ErrHookRegistryDisabled
+	}
+
+	return c.options.HookRegistry, nil
+}
+
 // GetServerInfo returns server initialization info including available commands.
 // Returns nil if not connected or not in streaming mode.
 func (c *Client) GetServerInfo() map[string]any {
@@ -687,6 +904,26 @@ func (c *Client) ReceiveResponse(ctx context.Context) iter.Seq2[message.Message,
 // After Close(), the client cannot be reused - create a new client with New().
 // This method is safe to call multiple times.
 func (c *Client) Close() error {
+	return c.close(func(transport config.Transport) error {
+		return transport.Close()
+	})
+}
+
+// CloseContext is like Close, but waits for the CLI process to exit on its
+// own until ctx is done before killing it. Transports that cannot wait are
+// closed with Close.
+func (c *Client) CloseContext(ctx context.Context) error {
+	return c.close(func(transport config.Transport) error {
+		if graceful, ok := transport.(config.GracefulCloser); ok {
+			return graceful.CloseContext(ctx)
+		}
+
+		return transport.Close()
+	})
+}
+
+// close shuts the client down, stopping the transport with closeTransport.
+func (c *Client) close(closeTransport func(config.Transport) error) error {
 	var closeErr error
 
 	c.closeOnce.Do(func() {
@@ -712,7 +949,7 @@ func (c *Client) Close() error {
 
 		// Close transport and capture error
 		if c.transport != nil {
-			closeErr = c.transport.Close()
+			closeErr = closeTransport(c.transport)
 		}
 
 		// Wait for errgroup goroutines to complete
@@ -722,6 +959,14 @@ func (c *Client) Close() error {
 			}
 		}
 
+		if c.session != nil {
+			c.session.PermissionCache().EndSession()
+		}
+
+		c.recorder.EndSession()
+		c.spend.End()
+		c.permit.Release()
+
 		c.log.Info("Client closed")
 	})
 
@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"encoding/json"
+	stderrors "errors"
 	"sync"
 	"testing"
 	"time"
@@ -10,17 +11,25 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/wagiedev/claude-agent-sdk-go/internal/checkpoint"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/config"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/errors"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/hook"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/message"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/permission"
 )
 
 // mockTransport implements config.Transport for testing.
-// It automatically responds to initialize control requests.
+// It automatically responds to initialize, set_model and rewind_files control
+// requests,
+// and records the requests it receives.
 type mockTransport struct {
 	mu       sync.Mutex
 	started  bool
 	closed   bool
 	messages chan map[string]any
 	errors   chan error
+	requests []map[string]any
 }
 
 func newMockTransport() *mockTransport {
@@ -59,6 +68,20 @@ func (m *mockTransport) SendMessage(_ context.Context, data []byte) error {
 
 		request, _ := msg["request"].(map[string]any)
 		subtype, _ := request["subtype"].(string)
+		m.requests = append(m.requests, request)
+
+		if subtype == "set_model" || subtype == "rewind_files" {
+			go func() {
+				m.mu.Lock()
+				defer m.mu.Unlock()
+
+				if !m.closed {
+					m.messages <- map[string]any{"type": "control_response", "response": map[string]any{
+						"request_id": requestID, "subtype": "success", "result": map[string]any{},
+					}}
+				}
+			}()
+		}
 
 		if subtype == "initialize" {
 			// Send the response asynchronously to avoid deadlock
@@ -288,3 +311,170 @@ func TestClient_ErrGroupDoesNotExitOnContextCancel(t *testing.T) {
 	err = client.Close()
 	require.NoError(t, err)
 }
+
+// TestClient_AddRemoveHook verifies runtime hook registration on a started client.
+func TestClient_AddRemoveHook(t *testing.T) {
+	client := New()
+
+	_, err := client.AddHook(hook.EventPreToolUse, &hook.Matcher{})
+	require.ErrorIs(t, err, errors.ErrClientNotConnected)
+
+	transport := newMockTransport()
+	registry := hook.NewRegistry(hook.EventPreToolUse)
+
+	err = client.Start(context.Background(), &config.Options{
+		Transport:    transport,
+		HookRegistry: registry,
+	})
+	require.NoError(t, err)
+
+	defer client.Close()
+
+	id, err := client.AddHook(hook.EventPreToolUse, &hook.Matcher{})
+	require.NoError(t, err)
+
+	_, err = client.AddHook(hook.EventStop, &hook.Matcher{})
+	require.ErrorIs(t, err, hook.ErrEventNotRegistered)
+
+	removed, err := client.RemoveHook(id)
+	require.NoError(t, err)
+	assert.True(t, removed)
+}
+
+// TestClient_SetModelMapsProviderID verifies runtime model switches use the
+// provider's model IDs, like the initial model.
+func TestClient_SetModelMapsProviderID(t *testing.T) {
+	client := New()
+	transport := newMockTransport()
+
+	err := client.Start(context.Background(), &config.Options{
+		Transport: transport,
+		Provider:  config.BedrockProvider{Region: "us-east-1", InferenceProfile: "us"},
+	})
+	require.NoError(t, err)
+
+	defer client.Close()
+
+	require.NoError(t, client.SetModel(context.Background(), new("claude-sonnet-4-5")))
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+
+	last := transport.requests[len(transport.requests)-1]
+	assert.Equal(t, "set_model", last["subtype"])
+	assert.Equal(t, "us.anthropic.claude-sonnet-4-5-20250929-v1:0", last["model"])
+}
+
+// TestClient_AddHookWithoutRegistry verifies AddHook fails when no registry is configured.
+func TestClient_AddHookWithoutRegistry(t *testing.T) {
+	client := New()
+
+	err := client.Start(context.Background(), &config.Options{Transport: newMockTransport()})
+	require.NoError(t, err)
+
+	defer client.Close()
+
+	_, err = client.AddHook(hook.EventPreToolUse, &hook.Matcher{})
+	require.ErrorIs(t, err, errors.ErrHookRegistryDisabled)
+}
+
+// TestClient_UndoLastTurnErrors verifies UndoLastTurn fails before rewinding
+// when checkpoints are disabled or empty.
+func TestClient_UndoLastTurnErrors(t *testing.T) {
+	client := New()
+
+	_, _, err := client.UndoLastTurn(context.Background())
+	require.ErrorIs(t, err, errors.ErrClientNotConnected)
+
+	err = client.Start(context.Background(), &config.Options{Transport: newMockTransport()})
+	require.NoError(t, err)
+
+	_, _, err = client.UndoLastTurn(context.Background())
+	require.ErrorIs(t, err, errors.ErrCheckpointsDisabled)
+	require.NoError(t, client.Close())
+
+	client = New()
+
+	err = client.Start(context.Background(), &config.Options{
+		Transport:   newMockTransport(),
+		Checkpoints: checkpoint.New(),
+	})
+	require.NoError(t, err)
+
+	defer client.Close()
+
+	_, _, err = client.UndoLastTurn(context.Background())
+	require.ErrorIs(t, err, errors.ErrNoCheckpoint)
+}
+
+// restartFailingTransport is a mockTransport that fails every start after
+// the first, as when the forked CLI process cannot be launched.
+type restartFailingTransport struct {
+	*mockTransport
+	starts int
+}
+
+func (r *restartFailingTransport) Start(ctx context.Context) error {
+	r.starts++
+	if r.starts > 1 {
+		return stderrors.New("launch failed")
+	}
+
+	return r.mockTransport.Start(ctx)
+}
+
+// TestClient_UndoLastTurnForkFails verifies a failed fork after the rewind
+// reports that files were rewound and keeps the checkpoint for a retry.
+func TestClient_UndoLastTurnForkFails(t *testing.T) {
+	checkpoints := checkpoint.New()
+	checkpoints.Observe(&message.UserMessage{UUID: new("u1"), Content: message.NewUserMessageContent("edit")})
+
+	transport := &restartFailingTransport{mockTransport: newMockTransport()}
+	client := New()
+
+	err := client.Start(context.Background(), &config.Options{Transport: transport, Checkpoints: checkpoints})
+	require.NoError(t, err)
+
+	defer client.Close()
+
+	next, cp, err := client.UndoLastTurn(context.Background())
+	require.ErrorIs(t, err, errors.ErrUndoIncomplete)
+	assert.Nil(t, next)
+	require.NotNil(t, cp)
+	assert.Equal(t, "u1", cp.UserMessageID)
+
+	_, ok := checkpoints.Last()
+	assert.True(t, ok, "the checkpoint is kept so the undo can be retried")
+}
+
+// TestForkOptions verifies the fork resumes the checkpoint's session at the
+// assistant message before the prompt.
+func TestForkOptions(t *testing.T) {
+	canUseTool := func(context.Context, string, map[string]any, *permission.Context) (permission.Result, error) {
+		return &permission.ResultAllow{}, nil
+	}
+
+	options := &config.Options{
+		Model:                    "sonnet",
+		ContinueConversation:     true,
+		CanUseTool:               canUseTool,
+		PermissionPromptToolName: "stdio",
+	}
+
+	fork := forkOptions(options, &checkpoint.Checkpoint{SessionID: "s1", ResumeAt: "a2"})
+	assert.Equal(t, "s1", fork.Resume)
+	assert.True(t, fork.ForkSession)
+	assert.Equal(t, "a2", fork.ResumeSessionAt)
+	assert.False(t, fork.ContinueConversation)
+	assert.Empty(t, fork.PermissionPromptToolName)
+	assert.Equal(t, "sonnet", fork.Model)
+	assert.True(t, options.ContinueConversation, "original options are unchanged")
+
+	fork = forkOptions(&config.Options{}, &checkpoint.Checkpoint{SessionID: "s1"})
+	assert.Empty(t, fork.Resume, "undoing the first prompt starts a new session")
+	assert.False(t, fork.ForkSession)
+
+	fork = forkOptions(&config.Options{Resume: "s0"}, &checkpoint.Checkpoint{SessionID: "s1"})
+	assert.Equal(t, "s0", fork.Resume)
+	assert.True(t, fork.ForkSession)
+}
@@ -0,0 +1,57 @@
+package config
+
+// EnvMode selects which variables of the host process the CLI inherits.
+type EnvMode string
+
+const (
+	// EnvInherit passes the whole host environment to the CLI. This is the
+	// default.
+	EnvInherit EnvMode = ""
+	// EnvAllowlist passes only the variables the CLI needs to run (PATH,
+	// HOME, locale and terminal settings, ANTHROPIC_* and CLAUDE_*) and the
+	// variables matching EnvPolicy.Allow.
+	EnvAllowlist EnvMode = "allowlist"
+	// EnvClean passes no host variables: the CLI sees only Options.Env and
+	// the variables the SDK sets. Set PATH and HOME through Options.Env.
+	EnvClean EnvMode = "clean"
+)
+
+// DefaultSecretPatterns are the variable names treated as secrets when
+// EnvPolicy.RedactSecrets is set. Patterns are matched case-insensitively
+// with path.Match.
+var DefaultSecretPatterns = []string{
+	"*SECRET*",
+	"*PASSWORD*",
+	"*PASSWD*",
+	"*TOKEN*",
+	"*API_KEY*",
+	"*APIKEY*",
+	"*ACCESS_KEY*",
+	"*PRIVATE_KEY*",
+	"*CREDENTIAL*",
+	"*DATABASE_URL*",
+	"*_DSN",
+}
+
+// EnvPolicy controls the environment of the CLI and the commands it runs.
+// Variables set through Options.Env are always passed.
+type EnvPolicy struct {
+	// Mode selects which host variables are inherited.
+	Mode EnvMode
+
+	// Allow lists the host variables passed in EnvAllowlist mode. Entries
+	// are matched with path.Match, so "AWS_*" passes every AWS_ variable.
+	Allow []string
+
+	// Required lists variables that must be set for the CLI; Start fails
+	// if any is missing or empty.
+	Required []string
+
+	// RedactSecrets drops host variables whose names match SecretPatterns,
+	// unless they are ANTHROPIC_* or CLAUDE_* variables or named exactly in
+	// Allow or Required.
+	RedactSecrets bool
+
+	// SecretPatterns replaces DefaultSecretPatterns.
+	SecretPatterns []string
+}
@@ -2,12 +2,20 @@ package config
 
 import (
 	"log/slog"
+	"os/exec"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/budget"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/checkpoint"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/hook"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/mcp"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/permission"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/ratelimit"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/retry"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/sandbox"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/telemetry"
 )
 
 // Effort controls thinking depth.
@@ -54,6 +62,30 @@ type Options struct {
 	// If nil, logging is disabled (silent operation).
 	Logger *slog.Logger
 
+	// TracerProvider supplies the tracer for session, turn, tool, permission,
+	// hook, MCP and control request spans. If nil, the global provider is used.
+	TracerProvider trace.TracerProvider
+
+	// Metrics receives counters and histograms for sessions, turns, tool
+	// calls, permission decisions, hooks, control timeouts, tokens and cost.
+	// If nil, no metrics are recorded.
+	Metrics telemetry.Metrics
+
+	// Budget is a spend limit shared with other runs. If nil, only
+	// MaxBudgetUSD limits spend.
+	Budget *budget.Budget
+
+	// RateLimiter paces run starts with limits shared with other runs. If nil,
+	// runs start at once.
+	RateLimiter *ratelimit.Limiter
+
+	// CircuitBreaker rejects runs while the API or CLI keeps failing. If nil,
+	// runs are never rejected.
+	CircuitBreaker *ratelimit.Breaker
+
+	// Retry retries Query after retryable failures. If nil, Query runs once.
+	Retry *retry.Policy
+
 	// SystemPrompt is the system message to send to Claude.
 	// Use this for a simple string system prompt.
 	SystemPrompt string
@@ -65,6 +97,10 @@ type Options struct {
 	// Model specifies which Claude model to use (e.g., "claude-3-5-sonnet-20241022")
 	Model string
 
+	// Provider selects the platform serving the model, such as Amazon
+	// Bedrock, and maps Model and FallbackModel to its model IDs.
+	Provider Provider
+
 	// PermissionMode controls how permissions are handled
 	// Valid values: "acceptEdits", "bypassPermissions", "default", "dontAsk", "plan"
 	// Legacy aliases are supported and normalized:
@@ -82,12 +118,38 @@ type Options struct {
 	// If empty, the CLI will be searched in PATH
 	CliPath string
 
+	// Wrapper is a command the CLI is launched through, such as
+	// []string{"nice", "-n", "10"} or a bwrap invocation. The CLI path and
+	// arguments are appended to it. Signals and resource limits go to the
+	// wrapper's process, so it should exec the CLI or exit along with it.
+	Wrapper []string
+
+	// Launcher is called with the CLI command before it is started, to
+	// change its credentials, cgroup, extra files or other attributes.
+	// Stdin, stdout and stderr are connected by the transport and must not
+	// be replaced. SysProcAttr may be replaced; on Linux the transport
+	// still runs the CLI in its own process group afterwards. Returning an
+	// error aborts the start.
+	Launcher func(cmd *exec.Cmd) error
+
+	// Process controls process groups, resource limits and graceful
+	// shutdown of the CLI subprocess.
+	Process *ProcessOptions
+
 	// Env provides additional environment variables for the CLI process
 	Env map[string]string
 
+	// EnvPolicy limits which host environment variables the CLI inherits.
+	// If nil, the whole host environment is passed.
+	EnvPolicy *EnvPolicy
+
 	// Hooks configures event hooks for tool interception
 	Hooks map[hook.Event][]*hook.Matcher
 
+	// HookRegistry holds hooks that can be added or removed after the session
+	// starts. Its events are registered with the CLI as catch-all matchers.
+	HookRegistry *hook.Registry
+
 	// Thinking controls extended thinking behavior.
 	Thinking ThinkingConfig
 
@@ -116,6 +178,12 @@ type Options struct {
 	// If nil, all tool uses are allowed.
 	CanUseTool permission.Callback
 
+	// PermissionCache remembers CanUseTool decisions by tool and normalized input.
+	// Turn-scoped entries are cleared after each result message and
+	// session-scoped entries when the query or client ends.
+	// Has no effect unless CanUseTool is set.
+	PermissionCache *permission.Cache
+
 	// SandboxSettings configures CLI sandbox behavior.
 	// If nil, sandbox is not enabled.
 	SandboxSettings *sandbox.Settings
@@ -145,9 +213,14 @@ type Options struct {
 	// PermissionPromptToolName specifies the tool name to use for permission prompts.
 	PermissionPromptToolName string
 
-	// Settings is the path to a settings file to load.
+	// Settings is the path to a settings file to load, or settings JSON.
+	// Relative paths are resolved against Cwd.
 	Settings string
 
+	// TypedSettings are settings given in code. They are merged with
+	// Settings and SandboxSettings; see Settings for the precedence.
+	TypedSettings *Settings
+
 	// AddDirs is a list of additional directories to make accessible.
 	AddDirs []string
 
@@ -174,6 +247,10 @@ type Options struct {
 	// ForkSession indicates whether to fork the resumed session to a new ID.
 	ForkSession bool
 
+	// ResumeSessionAt resumes the session only up to and including the
+	// assistant message with this UUID.
+	ResumeSessionAt string
+
 	// Agents defines custom agent configurations.
 	// Map key is the agent name.
 	Agents map[string]*AgentDefinition
@@ -193,6 +270,10 @@ type Options struct {
 	// EnableFileCheckpointing enables file change tracking and rewinding.
 	EnableFileCheckpointing bool
 
+	// Checkpoints records a checkpoint for each prompt so turns can be undone.
+	// Setting it makes the CLI echo prompts back with their UUIDs.
+	Checkpoints *checkpoint.Registry
+
 	// InitializeTimeout is the timeout for the initialize control request.
 	// If nil, defaults to 60 seconds. Can also be set via CLAUDE_CODE_STREAM_CLOSE_TIMEOUT env var.
 	InitializeTimeout *time.Duration
@@ -0,0 +1,41 @@
+package config
+
+import "time"
+
+// ProcessOptions controls how the CLI subprocess is run and stopped.
+//
+// On Linux the CLI runs in its own process group, so stopping it also stops
+// the commands it started, and it is killed if the SDK's process dies.
+// Resource limits are set by /bin/sh before it execs the CLI, or its
+// Wrapper, so no process escapes them.
+type ProcessOptions struct {
+	// GracePeriod is how long Close waits for the CLI to exit after SIGTERM
+	// before killing it. Zero kills it immediately.
+	GracePeriod time.Duration
+
+	// CPUTime limits the CPU time of the CLI and each process it starts
+	// (RLIMIT_CPU), rounded up to whole seconds. Zero means no limit. Linux
+	// only.
+	CPUTime time.Duration
+
+	// Memory limits the data segment of the CLI and each process it starts,
+	// in bytes rounded up to KiB (RLIMIT_DATA). Zero means no limit. Linux
+	// only.
+	//
+	// Since Linux 4.7 the data segment includes private writable memory
+	// mappings, where Node allocates most of its memory; on older kernels
+	// only brk memory counts and the limit is advisory. The limit bounds
+	// allocated address space, not resident memory. The address space limit
+	// (RLIMIT_AS) is not used because Node reserves far more address space
+	// than it uses.
+	Memory uint64
+
+	// OpenFiles limits the number of open files of the CLI and each process
+	// it starts (RLIMIT_NOFILE). Zero means no limit. Linux only.
+	OpenFiles uint64
+}
+
+// HasLimits reports whether any resource limit is set.
+func (p *ProcessOptions) HasLimits() bool {
+	return p != nil && (p.CPUTime > 0 || p.Memory > 0 || p.OpenFiles > 0)
+}
@@ -0,0 +1,315 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/errors"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/models"
+)
+
+// Provider configures the platform the CLI sends requests to. It replaces
+// hand-written environment variables and translates model IDs.
+type Provider interface {
+	// Environment returns the CLI environment variables for the provider.
+	Environment() map[string]string
+	// ModelID maps a catalog model ID or alias to the provider's model ID.
+	// Unknown models are returned unchanged.
+	ModelID(model string) string
+	// Validate checks that the required settings are present.
+	Validate() error
+}
+
+// Compile-time verification that the providers implement Provider.
+var (
+	_ Provider = AnthropicProvider{}
+	_ Provider = BedrockProvider{}
+	_ Provider = VertexProvider{}
+	_ Provider = GatewayProvider{}
+)
+
+// ModelID maps a model to provider's ID. It returns model unchanged when
+// provider is nil.
+func ModelID(provider Provider, model string) string {
+	if provider == nil {
+		return model
+	}
+
+	return provider.ModelID(model)
+}
+
+// aliasModels are the CLI's model aliases and the variables that choose
+// the model each resolves to.
+var aliasModels = map[string]string{
+	"opus":   "ANTHROPIC_DEFAULT_OPUS_MODEL",
+	"sonnet": "ANTHROPIC_DEFAULT_SONNET_MODEL",
+	"haiku":  "ANTHROPIC_DEFAULT_HAIKU_MODEL",
+}
+
+// AnthropicProvider sends requests to the Anthropic API. Empty fields leave
+// the CLI's own configuration, such as a login, in place.
+type AnthropicProvider struct {
+	// APIKey is sent as the x-api-key header (ANTHROPIC_API_KEY).
+	APIKey string
+	// AuthToken is sent as a bearer token (ANTHROPIC_AUTH_TOKEN).
+	AuthToken string
+	// BaseURL overrides the API endpoint (ANTHROPIC_BASE_URL).
+	BaseURL string
+}
+
+// Environment implements Provider.
+func (p AnthropicProvider) Environment() map[string]string {
+	env := useProvider("")
+	set(env, "ANTHROPIC_API_KEY", p.APIKey)
+	set(env, "ANTHROPIC_AUTH_TOKEN", p.AuthToken)
+	set(env, "ANTHROPIC_BASE_URL", p.BaseURL)
+
+	return env
+}
+
+// ModelID implements Provider. The CLI resolves aliases itself.
+func (AnthropicProvider) ModelID(model string) string {
+	return model
+}
+
+// Validate implements Provider.
+func (p AnthropicProvider) Validate() error {
+	return validateURL("anthropic", p.BaseURL, false)
+}
+
+// BedrockProvider sends requests to Amazon Bedrock. Credentials not set
+// here come from the AWS SDK's usual sources, such as ~/.aws.
+type BedrockProvider struct {
+	// Region is the AWS region (AWS_REGION). Required.
+	Region string
+	// Profile selects a profile from the AWS configuration (AWS_PROFILE).
+	Profile string
+	// AccessKeyID, SecretAccessKey and SessionToken are static credentials.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// BearerToken is a Bedrock API key (AWS_BEARER_TOKEN_BEDROCK).
+	BearerToken string
+	// InferenceProfile is the cross-region inference profile prefixed to
+	// model IDs, such as "us", "eu" or "global". Claude 4 models are only
+	// invoked through inference profiles; without one, the CLI's aliases
+	// keep its own defaults and catalog IDs map to base model IDs.
+	InferenceProfile string
+	// BaseURL overrides the Bedrock endpoint, for example an LLM gateway
+	// (ANTHROPIC_BEDROCK_BASE_URL).
+	BaseURL string
+	// SkipAuth disables request signing, for gateways that add their own
+	// credentials (CLAUDE_CODE_SKIP_BEDROCK_AUTH).
+	SkipAuth bool
+}
+
+// Environment implements Provider. When InferenceProfile is set, the CLI's
+// aliases resolve to the catalog's latest models through that profile.
+func (p BedrockProvider) Environment() map[string]string {
+	env := useProvider("CLAUDE_CODE_USE_BEDROCK")
+	set(env, "AWS_REGION", p.Region)
+	set(env, "AWS_PROFILE", p.Profile)
+	set(env, "AWS_ACCESS_KEY_ID", p.AccessKeyID)
+	set(env, "AWS_SECRET_ACCESS_KEY", p.SecretAccessKey)
+	set(env, "AWS_SESSION_TOKEN", p.SessionToken)
+	set(env, "AWS_BEARER_TOKEN_BEDROCK", p.BearerToken)
+	set(env, "ANTHROPIC_BEDROCK_BASE_URL", p.BaseURL)
+
+	if p.SkipAuth {
+		env["CLAUDE_CODE_SKIP_BEDROCK_AUTH"] = "1"
+	}
+
+	// Base model IDs fail on-demand invocation, so without a profile the
+	// CLI's own defaults are left in place
+	if p.InferenceProfile != "" {
+		setAliases(env, p)
+	}
+
+	return env
+}
+
+// ModelID implements Provider.
+func (p BedrockProvider) ModelID(model string) string {
+	id := models.ProviderID(models.ProviderBedrock, model)
+	if p.InferenceProfile != "" && strings.HasPrefix(id, "anthropic.") {
+		id = p.InferenceProfile + "." + id
+	}
+
+	return id
+}
+
+// Validate implements Provider.
+func (p BedrockProvider) Validate() error {
+	switch {
+	case p.Region == "":
+		return fmt.Errorf("%w: bedrock: region is required", errors.ErrInvalidProvider)
+	case (p.AccessKeyID == "") != (p.SecretAccessKey == ""):
+		return fmt.Errorf("%w: bedrock: access key ID and secret access key must be set together",
+			errors.ErrInvalidProvider)
+	case p.SessionToken != "" && p.AccessKeyID == "":
+		return fmt.Errorf("%w: bedrock: session token requires an access key", errors.ErrInvalidProvider)
+	}
+
+	return validateURL("bedrock", p.BaseURL, false)
+}
+
+// VertexProvider sends requests to Google Vertex AI. Credentials not set
+// here come from Application Default Credentials.
+type VertexProvider struct {
+	// ProjectID is the Google Cloud project (ANTHROPIC_VERTEX_PROJECT_ID).
+	// Required.
+	ProjectID string
+	// Region is the Vertex AI region, such as "us-east5" or "global"
+	// (CLOUD_ML_REGION). Required.
+	Region string
+	// Credentials is the path of a service account key file
+	// (GOOGLE_APPLICATION_CREDENTIALS).
+	Credentials string
+	// BaseURL overrides the Vertex AI endpoint, for example an LLM gateway
+	// (ANTHROPIC_VERTEX_BASE_URL).
+	BaseURL string
+	// SkipAuth disables Google authentication, for gateways that add their
+	// own credentials (CLAUDE_CODE_SKIP_VERTEX_AUTH).
+	SkipAuth bool
+}
+
+// Environment implements Provider. The CLI's aliases resolve to the
+// catalog's latest models on Vertex AI.
+func (p VertexProvider) Environment() map[string]string {
+	env := useProvider("CLAUDE_CODE_USE_VERTEX")
+	set(env, "ANTHROPIC_VERTEX_PROJECT_ID", p.ProjectID)
+	set(env, "CLOUD_ML_REGION", p.Region)
+	set(env, "GOOGLE_APPLICATION_CREDENTIALS", p.Credentials)
+	set(env, "ANTHROPIC_VERTEX_BASE_URL", p.BaseURL)
+
+	if p.SkipAuth {
+		env["CLAUDE_CODE_SKIP_VERTEX_AUTH"] = "1"
+	}
+
+	setAliases(env, p)
+
+	return env
+}
+
+// ModelID implements Provider.
+func (VertexProvider) ModelID(model string) string {
+	return models.ProviderID(models.ProviderVertex, model)
+}
+
+// Validate implements Provider.
+func (p VertexProvider) Validate() error {
+	switch {
+	case p.ProjectID == "":
+		return fmt.Errorf("%w: vertex: project ID is required", errors.ErrInvalidProvider)
+	case p.Region == "":
+		return fmt.Errorf("%w: vertex: region is required", errors.ErrInvalidProvider)
+	}
+
+	return validateURL("vertex", p.BaseURL, false)
+}
+
+// GatewayProvider sends requests to a custom endpoint that speaks the
+// Anthropic API, such as an LLM gateway or proxy.
+type GatewayProvider struct {
+	// BaseURL is the gateway endpoint (ANTHROPIC_BASE_URL). Required.
+	BaseURL string
+	// AuthToken is sent as a bearer token (ANTHROPIC_AUTH_TOKEN).
+	AuthToken string
+	// APIKey is sent as the x-api-key header (ANTHROPIC_API_KEY).
+	APIKey string
+	// Headers are added to every request (ANTHROPIC_CUSTOM_HEADERS).
+	Headers map[string]string
+	// Models maps catalog model IDs and aliases to the gateway's model
+	// names. Models not listed are passed unchanged.
+	Models map[string]string
+}
+
+// Environment implements Provider.
+func (p GatewayProvider) Environment() map[string]string {
+	env := useProvider("")
+	set(env, "ANTHROPIC_BASE_URL", p.BaseURL)
+	set(env, "ANTHROPIC_AUTH_TOKEN", p.AuthToken)
+	set(env, "ANTHROPIC_API_KEY", p.APIKey)
+
+	if len(p.Headers) > 0 {
+		headers := make([]string, 0, len(p.Headers))
+		for name, value := range p.Headers {
+			headers = append(headers, name+": "+value)
+		}
+
+		slices.Sort(headers)
+		env["ANTHROPIC_CUSTOM_HEADERS"] = strings.Join(headers, "\n")
+	}
+
+	for alias, name := range aliasModels {
+		set(env, name, p.Models[alias])
+	}
+
+	return env
+}
+
+// ModelID implements Provider.
+func (p GatewayProvider) ModelID(model string) string {
+	if id, ok := p.Models[model]; ok {
+		return id
+	}
+
+	return model
+}
+
+// Validate implements Provider.
+func (p GatewayProvider) Validate() error {
+	return validateURL("gateway", p.BaseURL, true)
+}
+
+// useProvider returns an environment enabling the provider flag, if any,
+// and disabling the others so inherited flags cannot conflict.
+func useProvider(flag string) map[string]string {
+	env := make(map[string]string)
+
+	for _, name := range []string{"CLAUDE_CODE_USE_BEDROCK", "CLAUDE_CODE_USE_VERTEX", "CLAUDE_CODE_USE_FOUNDRY"} {
+		env[name] = "0"
+		if name == flag {
+			env[name] = "1"
+		}
+	}
+
+	return env
+}
+
+// set adds a variable unless value is empty.
+func set(env map[string]string, name, value string) {
+	if value != "" {
+		env[name] = value
+	}
+}
+
+// setAliases points the CLI's model aliases at the provider's IDs for the
+// catalog models they name.
+func setAliases(env map[string]string, p Provider) {
+	for alias, name := range aliasModels {
+		if id := p.ModelID(alias); id != alias {
+			env[name] = id
+		}
+	}
+}
+
+// validateURL checks that raw is an absolute http or https URL.
+func validateURL(provider, raw string, required bool) error {
+	if raw == "" {
+		if required {
+			return fmt.Errorf("%w: %s: base URL is required", errors.ErrInvalidProvider, provider)
+		}
+
+		return nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: %s: base URL %q is not an http or https URL", errors.ErrInvalidProvider, provider, raw)
+	}
+
+	return nil
+}
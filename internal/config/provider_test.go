@@ -0,0 +1,127 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/errors"
+)
+
+func TestProviderEnvironment(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider Provider
+		want     map[string]string
+	}{
+		{
+			name:     "anthropic",
+			provider: AnthropicProvider{APIKey: "sk-ant"},
+			want: map[string]string{
+				"CLAUDE_CODE_USE_BEDROCK": "0", "CLAUDE_CODE_USE_VERTEX": "0", "CLAUDE_CODE_USE_FOUNDRY": "0",
+				"ANTHROPIC_API_KEY": "sk-ant",
+			},
+		},
+		{
+			name:     "bedrock",
+			provider: BedrockProvider{Region: "us-east-1", Profile: "agent", InferenceProfile: "us"},
+			want: map[string]string{
+				"CLAUDE_CODE_USE_BEDROCK": "1", "CLAUDE_CODE_USE_VERTEX": "0", "CLAUDE_CODE_USE_FOUNDRY": "0",
+				"AWS_REGION":                     "us-east-1",
+				"AWS_PROFILE":                    "agent",
+				"ANTHROPIC_DEFAULT_OPUS_MODEL":   "us.anthropic.claude-opus-4-6-v1",
+				"ANTHROPIC_DEFAULT_SONNET_MODEL": "us.anthropic.claude-sonnet-4-6",
+				"ANTHROPIC_DEFAULT_HAIKU_MODEL":  "us.anthropic.claude-haiku-4-5-20251001-v1:0",
+			},
+		},
+		{
+			name:     "bedrock without inference profile",
+			provider: BedrockProvider{Region: "us-east-1"},
+			want: map[string]string{
+				"CLAUDE_CODE_USE_BEDROCK": "1", "CLAUDE_CODE_USE_VERTEX": "0", "CLAUDE_CODE_USE_FOUNDRY": "0",
+				"AWS_REGION": "us-east-1",
+			},
+		},
+		{
+			name:     "vertex",
+			provider: VertexProvider{ProjectID: "proj", Region: "global", SkipAuth: true},
+			want: map[string]string{
+				"CLAUDE_CODE_USE_BEDROCK": "0", "CLAUDE_CODE_USE_VERTEX": "1", "CLAUDE_CODE_USE_FOUNDRY": "0",
+				"ANTHROPIC_VERTEX_PROJECT_ID":    "proj",
+				"CLOUD_ML_REGION":                "global",
+				"CLAUDE_CODE_SKIP_VERTEX_AUTH":   "1",
+				"ANTHROPIC_DEFAULT_OPUS_MODEL":   "claude-opus-4-6",
+				"ANTHROPIC_DEFAULT_SONNET_MODEL": "claude-sonnet-4-6",
+				"ANTHROPIC_DEFAULT_HAIKU_MODEL":  "claude-haiku-4-5@20251001",
+			},
+		},
+		{
+			name: "gateway",
+			provider: GatewayProvider{
+				BaseURL:   "https://llm.internal",
+				AuthToken: "tok",
+				Headers:   map[string]string{"X-Team": "agents", "X-Cost-Center": "42"},
+				Models:    map[string]string{"sonnet": "team-sonnet"},
+			},
+			want: map[string]string{
+				"CLAUDE_CODE_USE_BEDROCK": "0", "CLAUDE_CODE_USE_VERTEX": "0", "CLAUDE_CODE_USE_FOUNDRY": "0",
+				"ANTHROPIC_BASE_URL":             "https://llm.internal",
+				"ANTHROPIC_AUTH_TOKEN":           "tok",
+				"ANTHROPIC_CUSTOM_HEADERS":       "X-Cost-Center: 42\nX-Team: agents",
+				"ANTHROPIC_DEFAULT_SONNET_MODEL": "team-sonnet",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.provider.Validate())
+			assert.Equal(t, tt.want, tt.provider.Environment())
+		})
+	}
+}
+
+func TestProviderModelID(t *testing.T) {
+	assert.Equal(t, "sonnet", AnthropicProvider{}.ModelID("sonnet"))
+	assert.Equal(t, "anthropic.claude-sonnet-4-5-20250929-v1:0", BedrockProvider{}.ModelID("claude-sonnet-4-5"))
+	assert.Equal(t, "global.anthropic.claude-opus-4-1-20250805-v1:0",
+		BedrockProvider{InferenceProfile: "global"}.ModelID("claude-opus-4-1"))
+	assert.Equal(t, "eu.anthropic.claude-opus-4-1-20250805-v1:0",
+		BedrockProvider{InferenceProfile: "global"}.ModelID("eu.anthropic.claude-opus-4-1-20250805-v1:0"))
+	assert.Equal(t, "claude-sonnet-4-5@20250929", VertexProvider{}.ModelID("claude-sonnet-4-5"))
+	assert.Equal(t, "team-opus", GatewayProvider{Models: map[string]string{"opus": "team-opus"}}.ModelID("opus"))
+	assert.Equal(t, "claude-opus-4-6", GatewayProvider{}.ModelID("claude-opus-4-6"))
+}
+
+func TestProviderValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider Provider
+		wantErr  string
+	}{
+		{name: "anthropic bad URL", provider: AnthropicProvider{BaseURL: "llm.internal"}, wantErr: "is not an http or https URL"},
+		{name: "bedrock region", provider: BedrockProvider{}, wantErr: "bedrock: region is required"},
+		{
+			name:     "bedrock partial keys",
+			provider: BedrockProvider{Region: "us-east-1", AccessKeyID: "AKIA"},
+			wantErr:  "must be set together",
+		},
+		{
+			name:     "bedrock session token",
+			provider: BedrockProvider{Region: "us-east-1", SessionToken: "tok"},
+			wantErr:  "session token requires an access key",
+		},
+		{name: "vertex project", provider: VertexProvider{Region: "global"}, wantErr: "vertex: project ID is required"},
+		{name: "vertex region", provider: VertexProvider{ProjectID: "proj"}, wantErr: "vertex: region is required"},
+		{name: "gateway URL", provider: GatewayProvider{}, wantErr: "gateway: base URL is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.provider.Validate()
+			require.ErrorIs(t, err, errors.ErrInvalidProvider)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
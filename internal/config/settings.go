@@ -0,0 +1,80 @@
+package config
+
+import (
+	"encoding/json"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/hook"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/sandbox"
+)
+
+// Settings are CLI settings given in code, in the format of the CLI's
+// settings.json.
+//
+// They are merged with the settings file or JSON in Options.Settings and
+// with Options.SandboxSettings, in that order of increasing precedence:
+// objects are merged key by key, lists are concatenated without duplicates,
+// and other values are replaced by the later source. Permission rules from
+// every source therefore apply together.
+type Settings struct {
+	// Permissions are the permission rules.
+	Permissions *PermissionSettings `json:"permissions,omitempty"`
+
+	// Env sets environment variables for the CLI's sessions.
+	Env map[string]string `json:"env,omitempty"`
+
+	// Hooks are shell command hooks by event. Use Options.Hooks for hooks
+	// implemented in Go.
+	Hooks map[hook.Event][]*CommandHookMatcher `json:"hooks,omitempty"`
+
+	// Model is the default model. It is mapped to Options.Provider's model
+	// ID when a provider is set.
+	Model string `json:"model,omitempty"`
+
+	// Sandbox configures the CLI sandbox.
+	Sandbox *sandbox.Settings `json:"sandbox,omitempty"`
+
+	// Extra holds other settings by key. Typed fields take precedence.
+	Extra map[string]any `json:"-"`
+}
+
+// PermissionSettings are permission rules such as "Bash(git diff:*)",
+// "Read(./secrets/**)" or "WebFetch(domain:example.com)".
+type PermissionSettings struct {
+	// Allow lists the tool uses allowed without asking.
+	Allow []string `json:"allow,omitempty"`
+	// Deny lists the tool uses that are always denied.
+	Deny []string `json:"deny,omitempty"`
+	// Ask lists the tool uses that always ask for permission.
+	Ask []string `json:"ask,omitempty"`
+	// AdditionalDirectories are directories the CLI may access besides the
+	// working directory.
+	AdditionalDirectories []string `json:"additionalDirectories,omitempty"`
+	// DefaultMode is the permission mode, such as "acceptEdits".
+	DefaultMode string `json:"defaultMode,omitempty"`
+}
+
+// CommandHookMatcher runs shell command hooks for the tools it matches.
+type CommandHookMatcher struct {
+	// Matcher is a tool name or pipe-separated names; empty matches all.
+	Matcher string `json:"matcher,omitempty"`
+	// Hooks are the commands to run.
+	Hooks []*CommandHook `json:"hooks"`
+}
+
+// CommandHook is a shell command run by the CLI for a hook event. It
+// receives the event as JSON on stdin.
+type CommandHook struct {
+	// Command is the shell command.
+	Command string
+	// Timeout is the timeout in seconds; zero uses the CLI's default.
+	Timeout int
+}
+
+// MarshalJSON encodes the hook in the settings format.
+func (h *CommandHook) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type    string `json:"type"`
+		Command string `json:"command"`
+		Timeout int    `json:"timeout,omitempty"`
+	}{Type: "command", Command: h.Command, Timeout: h.Timeout})
+}
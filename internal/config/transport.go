@@ -36,3 +36,11 @@ type Transport interface {
 	// For process-based transports, this typically closes stdin.
 	EndInput() error
 }
+
+// GracefulCloser is implemented by transports that can wait for the CLI to
+// exit on its own before terminating it.
+type GracefulCloser interface {
+	// CloseContext asks the CLI to exit and waits until it has, or until ctx
+	// is done, when it is killed. It's safe to call multiple times.
+	CloseContext(ctx context.Context) error
+}
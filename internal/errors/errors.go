@@ -3,6 +3,7 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 // ClaudeSDKError is the base interface for all SDK errors.
@@ -49,6 +50,68 @@ var (
 	// ErrUnknownMessageType indicates the message type is not recognized by the SDK.
 	// Callers should skip these messages rather than treating them as fatal.
 	ErrUnknownMessageType = errors.New("unknown message type")
+
+	// ErrHookRegistryDisabled indicates hooks were added or removed on a client
+	// that was started without a hook registry.
+	ErrHookRegistryDisabled = errors.New("hook registry not configured: start the client with WithHookRegistry")
+
+	// ErrBudgetExhausted indicates a shared budget has no spend left, so the
+	// run was rejected or interrupted.
+	ErrBudgetExhausted = errors.New("budget exhausted")
+
+	// ErrCircuitOpen indicates a circuit breaker rejected the run after
+	// repeated rate limit, server or CLI process failures.
+	ErrCircuitOpen = errors.New("circuit breaker open")
+
+	// ErrUnknownTool indicates a tool input or result was decoded for a tool
+	// that is not a built-in tool, such as an MCP tool.
+	ErrUnknownTool = errors.New("unknown tool")
+
+	// ErrCheckpointsDisabled indicates a turn was undone on a client that was
+	// started without a checkpoint registry.
+	ErrCheckpointsDisabled = errors.New("checkpoints not configured: start the client with WithCheckpoints")
+
+	// ErrNoCheckpoint indicates there is no recorded turn to undo.
+	ErrNoCheckpoint = errors.New("no checkpoint")
+
+	// ErrUndoIncomplete indicates an undo rewound files but could not fork
+	// the session, so the conversation still includes the undone turn.
+	ErrUndoIncomplete = errors.New("undo incomplete: files were rewound but the session was not forked")
+
+	// ErrInvalidEnvironment indicates the CLI environment violates the
+	// environment policy or enables conflicting settings.
+	ErrInvalidEnvironment = errors.New("invalid CLI environment")
+
+	// ErrInvalidProvider indicates a provider configuration is missing
+	// required settings or has invalid ones.
+	ErrInvalidProvider = errors.New("invalid provider configuration")
+)
+
+// Failure classes. A ClassifiedError matches one of these with errors.Is.
+var (
+	// ErrRateLimited indicates the API rejected requests for exceeding a rate
+	// limit (HTTP 429). Retryable.
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrOverloaded indicates the API is overloaded or failing (HTTP 529 and
+	// other 5xx responses). Retryable.
+	ErrOverloaded = errors.New("api overloaded")
+
+	// ErrAuth indicates missing or invalid credentials.
+	ErrAuth = errors.New("authentication failed")
+
+	// ErrBilling indicates the account cannot be billed, e.g. no credit left.
+	ErrBilling = errors.New("billing error")
+
+	// ErrKilled indicates the CLI process was killed by SIGKILL or SIGTERM,
+	// e.g. by a resource limit, the OOM killer or a cancelled context.
+	ErrKilled = errors.New("cli process killed")
+
+	// ErrMaxTurns indicates the run stopped at its MaxTurns limit.
+	ErrMaxTurns = errors.New("max turns reached")
+
+	// ErrBudgetExceeded indicates the run stopped at its MaxBudgetUSD limit.
+	ErrBudgetExceeded = errors.New("max budget exceeded")
 )
 
 // CLINotFoundError indicates the Claude CLI binary was not found.
@@ -136,3 +199,43 @@ func (e *CLIJSONDecodeError) Unwrap() error {
 
 // IsClaudeSDKError implements ClaudeSDKError.
 func (e *CLIJSONDecodeError) IsClaudeSDKError() bool { return true }
+
+// ClassifiedError is a run failure sorted into a failure class such as
+// ErrRateLimited, with whether retrying may help. It matches its class and
+// its underlying error with errors.Is and errors.As.
+type ClassifiedError struct {
+	// Class is one of the failure class sentinels.
+	Class error
+	// Retryable reports whether the same request may succeed later.
+	Retryable bool
+	// RetryAfter is how long the API asked callers to wait, or zero if it did
+	// not say.
+	RetryAfter time.Duration
+	// Detail describes the failure as reported by the CLI.
+	Detail string
+	// Err is the underlying error, such as a *ProcessError, or nil when the
+	// failure was reported in a message.
+	Err error
+}
+
+func (e *ClassifiedError) Error() string {
+	switch {
+	case e.Err != nil:
+		return fmt.Sprintf("%v: %v", e.Class, e.Err)
+	case e.Detail != "":
+		return fmt.Sprintf("%v: %s", e.Class, e.Detail)
+	default:
+		return e.Class.Error()
+	}
+}
+
+func (e *ClassifiedError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Class}
+	}
+
+	return []error{e.Class, e.Err}
+}
+
+// IsClaudeSDKError implements ClaudeSDKError.
+func (e *ClassifiedError) IsClaudeSDKError() bool { return true }
@@ -0,0 +1,271 @@
+package hook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Chain composes callbacks into a single Callback that runs them in order.
+//
+// The first callback that denies, blocks or stops wins: its output is returned
+// immediately and later callbacks do not run. Otherwise outputs are merged:
+//   - updated tool inputs compose, each callback seeing the input produced by
+//     the ones before it, and the final input is reported as UpdatedInput;
+//   - a PreToolUse "ask" outranks "allow";
+//   - additional context and system messages are joined with newlines;
+//   - other fields take the last value set.
+//
+// Async outputs cannot be merged; a callback returning one fails the chain.
+func Chain(callbacks ...Callback) Callback {
+	return func(ctx context.Context, input Input, toolUseID *string, hookCtx *Context) (JSONOutput, error) {
+		var acc chainResult
+
+		current := input
+
+		for i, cb := range callbacks {
+			output, err := cb(ctx, current, toolUseID, hookCtx)
+			if err != nil {
+				return nil, err
+			}
+
+			var sync *SyncJSONOutput
+
+			switch o := output.(type) {
+			case nil:
+				continue
+			case *SyncJSONOutput:
+				if o == nil {
+					continue
+				}
+
+				sync = o
+			case *AsyncJSONOutput:
+				return nil, fmt.Errorf("hook chain: callback %d returned async output", i)
+			default:
+				return nil, fmt.Errorf("hook chain: callback %d returned unsupported output %T", i, output)
+			}
+
+			if isBlocking(sync) {
+				return sync, nil
+			}
+
+			current = acc.merge(sync, current)
+		}
+
+		return acc.output(input.GetHookEventName()), nil
+	}
+}
+
+// chainResult accumulates the merged outputs of a Chain.
+type chainResult struct {
+	out SyncJSONOutput
+
+	systemMessages    []string
+	additionalContext []string
+
+	permission       PermissionDecision
+	permissionReason *string
+	updatedInput     map[string]any
+	updatedMCPOutput any
+	other            SpecificOutput
+}
+
+// merge folds o into r and returns the input the next callback should see.
+func (r *chainResult) merge(o *SyncJSONOutput, current Input) Input {
+	if o.SuppressOutput != nil {
+		r.out.SuppressOutput = o.SuppressOutput
+	}
+
+	if o.SystemMessage != nil {
+		r.systemMessages = append(r.systemMessages, *o.SystemMessage)
+	}
+
+	if o.Decision != nil {
+		r.out.Decision = o.Decision
+	}
+
+	if o.Reason != nil {
+		r.out.Reason = o.Reason
+	}
+
+	switch s := o.HookSpecificOutput.(type) {
+	case nil:
+	case *PreToolUseSpecificOutput:
+		if s.PermissionDecision != nil {
+			decision := PermissionDecision(*s.PermissionDecision)
+			if decision == PermissionAsk || r.permission != PermissionAsk {
+				r.permission = decision
+				r.permissionReason = s.PermissionDecisionReason
+			}
+		}
+
+		r.addContext(s.AdditionalContext)
+
+		if s.UpdatedInput != nil {
+			r.updatedInput = s.UpdatedInput
+			current = withToolInput(current, s.UpdatedInput)
+		}
+	case *PermissionRequestSpecificOutput:
+		if updated, ok := s.Decision["updatedInput"].(map[string]any); ok {
+			r.updatedInput = updated
+			current = withToolInput(current, updated)
+		}
+
+		if behavior, ok := s.Decision["behavior"].(string); ok {
+			r.permission = PermissionDecision(behavior)
+		}
+	case *PostToolUseSpecificOutput:
+		r.addContext(s.AdditionalContext)
+
+		if s.UpdatedMCPToolOutput != nil {
+			r.updatedMCPOutput = s.UpdatedMCPToolOutput
+		}
+	case *PostToolUseFailureSpecificOutput:
+		r.addContext(s.AdditionalContext)
+	case *UserPromptSubmitSpecificOutput:
+		r.addContext(s.AdditionalContext)
+	case *NotificationSpecificOutput:
+		r.addContext(s.AdditionalContext)
+	case *SubagentStartSpecificOutput:
+		r.addContext(s.AdditionalContext)
+	case *SessionStartSpecificOutput:
+		r.addContext(s.AdditionalContext)
+	default:
+		r.other = s
+	}
+
+	return current
+}
+
+func (r *chainResult) addContext(s *string) {
+	if s != nil && *s != "" {
+		r.additionalContext = append(r.additionalContext, *s)
+	}
+}
+
+// output builds the merged output for event.
+func (r *chainResult) output(event Event) *SyncJSONOutput {
+	out := r.out
+
+	if len(r.systemMessages) > 0 {
+		out.SystemMessage = new(strings.Join(r.systemMessages, "\n"))
+	}
+
+	additionalContext := optional(strings.Join(r.additionalContext, "\n"))
+
+	switch event {
+	case EventPreToolUse:
+		if r.permission != PermissionDefault || r.updatedInput != nil || additionalContext != nil {
+			specific := &PreToolUseSpecificOutput{
+				HookEventName:            string(event),
+				PermissionDecisionReason: r.permissionReason,
+				UpdatedInput:             r.updatedInput,
+				AdditionalContext:        additionalContext,
+			}
+
+			if r.permission != PermissionDefault {
+				specific.PermissionDecision = new(string(r.permission))
+			}
+
+			out.HookSpecificOutput = specific
+		}
+	case EventPermissionRequest:
+		if r.permission == PermissionAllow {
+			decision := map[string]any{"behavior": string(PermissionAllow)}
+			if r.updatedInput != nil {
+				decision["updatedInput"] = r.updatedInput
+			}
+
+			out.HookSpecificOutput = &PermissionRequestSpecificOutput{
+				HookEventName: string(event),
+				Decision:      decision,
+			}
+		}
+	case EventPostToolUse:
+		if additionalContext != nil || r.updatedMCPOutput != nil {
+			out.HookSpecificOutput = &PostToolUseSpecificOutput{
+				HookEventName:        string(event),
+				AdditionalContext:    additionalContext,
+				UpdatedMCPToolOutput: r.updatedMCPOutput,
+			}
+		}
+	case EventPostToolUseFailure:
+		if additionalContext != nil {
+			out.HookSpecificOutput = &PostToolUseFailureSpecificOutput{
+				HookEventName: string(event), AdditionalContext: additionalContext,
+			}
+		}
+	case EventUserPromptSubmit:
+		if additionalContext != nil {
+			out.HookSpecificOutput = &UserPromptSubmitSpecificOutput{
+				HookEventName: string(event), AdditionalContext: additionalContext,
+			}
+		}
+	case EventNotification:
+		if additionalContext != nil {
+			out.HookSpecificOutput = &NotificationSpecificOutput{
+				HookEventName: string(event), AdditionalContext: additionalContext,
+			}
+		}
+	case EventSubagentStart:
+		if additionalContext != nil {
+			out.HookSpecificOutput = &SubagentStartSpecificOutput{
+				HookEventName: string(event), AdditionalContext: additionalContext,
+			}
+		}
+	case EventSessionStart:
+		if additionalContext != nil {
+			out.HookSpecificOutput = &SessionStartSpecificOutput{
+				HookEventName: string(event), AdditionalContext: additionalContext,
+			}
+		}
+	}
+
+	if out.HookSpecificOutput == nil && r.other != nil {
+		out.HookSpecificOutput = r.other
+	}
+
+	return &out
+}
+
+// isBlocking reports whether o denies, blocks or stops the event.
+func isBlocking(o *SyncJSONOutput) bool {
+	if o.Continue != nil && !*o.Continue {
+		return true
+	}
+
+	if o.Decision != nil && *o.Decision == decisionBlock {
+		return true
+	}
+
+	switch s := o.HookSpecificOutput.(type) {
+	case *PreToolUseSpecificOutput:
+		return s.PermissionDecision != nil && *s.PermissionDecision == string(PermissionDeny)
+	case *PermissionRequestSpecificOutput:
+		behavior, _ := s.Decision["behavior"].(string)
+
+		return behavior == string(PermissionDeny)
+	}
+
+	return false
+}
+
+// withToolInput returns a copy of input with its tool input replaced.
+// Inputs without a tool input are returned unchanged.
+func withToolInput(input Input, toolInput map[string]any) Input {
+	switch in := input.(type) {
+	case *PreToolUseInput:
+		c := *in
+		c.ToolInput = toolInput
+
+		return &c
+	case *PermissionRequestInput:
+		c := *in
+		c.ToolInput = toolInput
+
+		return &c
+	default:
+		return input
+	}
+}
@@ -1,7 +1,11 @@
 // Package hook provides hook types for intercepting Claude CLI events.
 package hook
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+	"maps"
+)
 
 // Event represents the type of event that triggers a hook.
 type Event string
@@ -27,6 +31,10 @@ const (
 	EventSubagentStart Event = "SubagentStart"
 	// EventPermissionRequest is triggered when a permission is requested.
 	EventPermissionRequest Event = "PermissionRequest"
+	// EventSessionStart is triggered when a session starts, resumes or is cleared.
+	EventSessionStart Event = "SessionStart"
+	// EventSessionEnd is triggered when a session ends.
+	EventSessionEnd Event = "SessionEnd"
 )
 
 // Input is the interface for all hook input types.
@@ -50,6 +58,9 @@ var (
 	_ Input = (*NotificationInput)(nil)
 	_ Input = (*SubagentStartInput)(nil)
 	_ Input = (*PermissionRequestInput)(nil)
+	_ Input = (*SessionStartInput)(nil)
+	_ Input = (*SessionEndInput)(nil)
+	_ Input = (*UnknownInput)(nil)
 )
 
 // BaseInput contains common fields for all hook inputs.
@@ -214,6 +225,44 @@ type PreCompactInput struct {
 // GetHookEventName implements Input.
 func (p *PreCompactInput) GetHookEventName() Event { return EventPreCompact }
 
+// SessionStartInput is the input for SessionStart hooks.
+//
+//nolint:tagliatelle // Claude CLI uses snake_case
+type SessionStartInput struct {
+	BaseInput
+	HookEventName string `json:"hook_event_name"`
+	Source        string `json:"source"` // "startup", "resume", "clear" or "compact"
+}
+
+// GetHookEventName implements Input.
+func (s *SessionStartInput) GetHookEventName() Event { return EventSessionStart }
+
+// SessionEndInput is the input for SessionEnd hooks.
+//
+//nolint:tagliatelle // Claude CLI uses snake_case
+type SessionEndInput struct {
+	BaseInput
+	HookEventName string `json:"hook_event_name"`
+	Reason        string `json:"reason"` // e.g. "clear", "logout", "prompt_input_exit", "other"
+}
+
+// GetHookEventName implements Input.
+func (s *SessionEndInput) GetHookEventName() Event { return EventSessionEnd }
+
+// UnknownInput is the input for hook events this SDK version does not know.
+// It lets callbacks registered for newer CLI events run instead of failing;
+// Raw holds the complete input as sent by the CLI.
+//
+//nolint:tagliatelle // Claude CLI uses snake_case
+type UnknownInput struct {
+	BaseInput
+	HookEventName string         `json:"hook_event_name"`
+	Raw           map[string]any `json:"-"`
+}
+
+// GetHookEventName implements Input.
+func (u *UnknownInput) GetHookEventName() Event { return Event(u.HookEventName) }
+
 // JSONOutput is the interface for hook output types.
 // This is a marker interface for type safety; use type switches to distinguish
 // between AsyncJSONOutput and SyncJSONOutput.
@@ -256,6 +305,8 @@ var (
 	_ SpecificOutput = (*NotificationSpecificOutput)(nil)
 	_ SpecificOutput = (*SubagentStartSpecificOutput)(nil)
 	_ SpecificOutput = (*PermissionRequestSpecificOutput)(nil)
+	_ SpecificOutput = (*SessionStartSpecificOutput)(nil)
+	_ SpecificOutput = (*RawSpecificOutput)(nil)
 )
 
 // PreToolUseSpecificOutput is the hook-specific output for PreToolUse.
@@ -329,6 +380,34 @@ type PermissionRequestSpecificOutput struct {
 // GetHookEventName implements SpecificOutput.
 func (p *PermissionRequestSpecificOutput) GetHookEventName() string { return "PermissionRequest" }
 
+// SessionStartSpecificOutput is the hook-specific output for SessionStart.
+type SessionStartSpecificOutput struct {
+	HookEventName     string  `json:"hookEventName"` // "SessionStart"
+	AdditionalContext *string `json:"additionalContext,omitempty"`
+}
+
+// GetHookEventName implements SpecificOutput.
+func (s *SessionStartSpecificOutput) GetHookEventName() string { return "SessionStart" }
+
+// RawSpecificOutput is a hook-specific output for events or fields without a
+// dedicated type. Fields are sent alongside hookEventName as-is.
+type RawSpecificOutput struct {
+	HookEventName string
+	Fields        map[string]any
+}
+
+// GetHookEventName implements SpecificOutput.
+func (r *RawSpecificOutput) GetHookEventName() string { return r.HookEventName }
+
+// MarshalJSON implements json.Marshaler, flattening Fields next to hookEventName.
+func (r *RawSpecificOutput) MarshalJSON() ([]byte, error) {
+	out := make(map[string]any, len(r.Fields)+1)
+	maps.Copy(out, r.Fields)
+	out["hookEventName"] = r.HookEventName
+
+	return json.Marshal(out)
+}
+
 // Context provides context for hook execution.
 type Context struct{}
 
@@ -348,4 +427,9 @@ type Matcher struct {
 	Matcher *string
 	Hooks   []Callback
 	Timeout *float64 // seconds (default 60)
+
+	// Predicate, when set, is evaluated on the SDK side before any of Hooks
+	// run. Inputs for which it returns false continue without output. Use
+	// MatchRegex or MatchGlob to build matchers with regex or glob patterns.
+	Predicate func(Input) bool
 }
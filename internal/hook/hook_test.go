@@ -0,0 +1,236 @@
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func preToolUse(toolName string, toolInput map[string]any) *PreToolUseInput {
+	return &PreToolUseInput{
+		HookEventName: string(EventPreToolUse),
+		ToolName:      toolName,
+		ToolInput:     toolInput,
+	}
+}
+
+func run(t *testing.T, cb Callback, input Input) *SyncJSONOutput {
+	t.Helper()
+
+	out, err := cb(context.Background(), input, nil, &Context{})
+	require.NoError(t, err)
+
+	sync, ok := out.(*SyncJSONOutput)
+	require.True(t, ok, "expected *SyncJSONOutput, got %T", out)
+
+	return sync
+}
+
+func TestOnPreToolUse(t *testing.T) {
+	cb := OnPreToolUse(func(_ context.Context, in *PreToolUseInput) (PreToolUseDecision, error) {
+		if in.ToolName == "Bash" {
+			return PreToolUseDecision{Permission: PermissionDeny, Reason: "no shell"}, nil
+		}
+
+		return PreToolUseDecision{}, nil
+	})
+
+	out := run(t, cb, preToolUse("Bash", nil))
+	specific, ok := out.HookSpecificOutput.(*PreToolUseSpecificOutput)
+	require.True(t, ok)
+	require.Equal(t, "PreToolUse", specific.HookEventName)
+	require.Equal(t, "deny", *specific.PermissionDecision)
+	require.Equal(t, "no shell", *specific.PermissionDecisionReason)
+
+	out = run(t, cb, preToolUse("Read", nil))
+	require.Equal(t, &SyncJSONOutput{}, out, "zero decision should continue without output")
+
+	out = run(t, cb, &StopInput{})
+	require.Equal(t, &SyncJSONOutput{}, out, "mismatched input types are ignored")
+}
+
+func TestOnPreToolUse_Error(t *testing.T) {
+	wantErr := errors.New("boom")
+	cb := OnPreToolUse(func(context.Context, *PreToolUseInput) (PreToolUseDecision, error) {
+		return PreToolUseDecision{}, wantErr
+	})
+
+	_, err := cb(context.Background(), preToolUse("Bash", nil), nil, &Context{})
+	require.ErrorIs(t, err, wantErr)
+}
+
+func TestTypedDecisionOutputs(t *testing.T) {
+	out := StopDecision{Block: true, Reason: "keep going", Outcome: Outcome{SystemMessage: "hi"}}.Output()
+	require.Equal(t, "block", *out.Decision)
+	require.Equal(t, "keep going", *out.Reason)
+	require.Equal(t, "hi", *out.SystemMessage)
+	require.Nil(t, out.Continue)
+
+	out = UserPromptSubmitDecision{Outcome: Outcome{Stop: true, StopReason: "done"}}.Output()
+	require.False(t, *out.Continue)
+	require.Equal(t, "done", *out.StopReason)
+	require.Nil(t, out.HookSpecificOutput)
+
+	out = PermissionRequestDecision{Permission: PermissionDeny, Message: "nope", Interrupt: true}.Output()
+	specific, ok := out.HookSpecificOutput.(*PermissionRequestSpecificOutput)
+	require.True(t, ok)
+	require.Equal(t, map[string]any{"behavior": "deny", "message": "nope", "interrupt": true}, specific.Decision)
+
+	cb := OnSubagentStart(func(context.Context, *SubagentStartInput) (ContextDecision, error) {
+		return ContextDecision{AdditionalContext: "be brief"}, nil
+	})
+	out = run(t, cb, &SubagentStartInput{})
+	require.Equal(t, &SubagentStartSpecificOutput{
+		HookEventName:     "SubagentStart",
+		AdditionalContext: new("be brief"),
+	}, out.HookSpecificOutput)
+}
+
+func TestMatchRegex(t *testing.T) {
+	m, err := MatchRegex("mcp__.*__write_.*")
+	require.NoError(t, err)
+	require.True(t, m.Predicate(preToolUse("mcp__fs__write_file", nil)))
+	require.False(t, m.Predicate(preToolUse("mcp__fs__read_file", nil)))
+	require.False(t, m.Predicate(preToolUse("xmcp__fs__write_file", nil)), "patterns are anchored")
+
+	_, err = MatchRegex("(")
+	require.Error(t, err)
+	require.Panics(t, func() { MustMatchRegex("(") })
+}
+
+func TestMatchGlob(t *testing.T) {
+	m, err := MatchGlob("Write|Edit|mcp__github__*")
+	require.NoError(t, err)
+	require.True(t, m.Predicate(preToolUse("Edit", nil)))
+	require.True(t, m.Predicate(preToolUse("mcp__github__create_issue", nil)))
+	require.False(t, m.Predicate(preToolUse("mcp__gitlab__create_issue", nil)))
+	require.False(t, m.Predicate(&PreCompactInput{Trigger: "manual"}))
+
+	_, err = MatchGlob("[")
+	require.Error(t, err)
+}
+
+func TestFilter(t *testing.T) {
+	var calls int
+
+	cb := Filter(func(in Input) bool { return Subject(in) == "Bash" },
+		func(context.Context, Input, *string, *Context) (JSONOutput, error) {
+			calls++
+
+			return PreToolUseDecision{Permission: PermissionAllow}.Output(), nil
+		})
+
+	require.Equal(t, &SyncJSONOutput{}, run(t, cb, preToolUse("Read", nil)))
+	require.Equal(t, 0, calls)
+
+	run(t, cb, preToolUse("Bash", nil))
+	require.Equal(t, 1, calls)
+}
+
+func TestChain_FirstDenyWins(t *testing.T) {
+	var ran []string
+
+	step := func(name string, d PreToolUseDecision) Callback {
+		return OnPreToolUse(func(context.Context, *PreToolUseInput) (PreToolUseDecision, error) {
+			ran = append(ran, name)
+
+			return d, nil
+		})
+	}
+
+	cb := Chain(
+		step("allow", PreToolUseDecision{Permission: PermissionAllow}),
+		step("deny", PreToolUseDecision{Permission: PermissionDeny, Reason: "first"}),
+		step("deny-again", PreToolUseDecision{Permission: PermissionDeny, Reason: "second"}),
+	)
+
+	out := run(t, cb, preToolUse("Bash", nil))
+	specific, ok := out.HookSpecificOutput.(*PreToolUseSpecificOutput)
+	require.True(t, ok)
+	require.Equal(t, "deny", *specific.PermissionDecision)
+	require.Equal(t, "first", *specific.PermissionDecisionReason)
+	require.Equal(t, []string{"allow", "deny"}, ran)
+}
+
+func TestChain_ComposesUpdatedInput(t *testing.T) {
+	addFlag := func(key string) Callback {
+		return OnPreToolUse(func(_ context.Context, in *PreToolUseInput) (PreToolUseDecision, error) {
+			updated := make(map[string]any, len(in.ToolInput)+1)
+			for k, v := range in.ToolInput {
+				updated[k] = v
+			}
+
+			updated[key] = true
+
+			return PreToolUseDecision{UpdatedInput: updated, AdditionalContext: "saw " + key}, nil
+		})
+	}
+
+	ask := OnPreToolUse(func(context.Context, *PreToolUseInput) (PreToolUseDecision, error) {
+		return PreToolUseDecision{Permission: PermissionAsk, Outcome: Outcome{SystemMessage: "confirm"}}, nil
+	})
+	allow := OnPreToolUse(func(context.Context, *PreToolUseInput) (PreToolUseDecision, error) {
+		return PreToolUseDecision{Permission: PermissionAllow, Outcome: Outcome{SystemMessage: "ok"}}, nil
+	})
+
+	original := map[string]any{"command": "ls"}
+	out := run(t, Chain(addFlag("a"), ask, addFlag("b"), allow), preToolUse("Bash", original))
+
+	specific, ok := out.HookSpecificOutput.(*PreToolUseSpecificOutput)
+	require.True(t, ok)
+	require.Equal(t, map[string]any{"command": "ls", "a": true, "b": true}, specific.UpdatedInput)
+	require.Equal(t, "ask", *specific.PermissionDecision, "ask outranks a later allow")
+	require.Equal(t, "saw a\nsaw b", *specific.AdditionalContext)
+	require.Equal(t, "confirm\nok", *out.SystemMessage)
+	require.Equal(t, map[string]any{"command": "ls"}, original, "original input is not mutated")
+}
+
+func TestChain_StopsOnBlockAndRejectsAsync(t *testing.T) {
+	block := OnStop(func(context.Context, *StopInput) (StopDecision, error) {
+		return StopDecision{Block: true, Reason: "not yet"}, nil
+	})
+
+	var called bool
+
+	after := func(context.Context, Input, *string, *Context) (JSONOutput, error) {
+		called = true
+
+		return &SyncJSONOutput{}, nil
+	}
+
+	out := run(t, Chain(block, after), &StopInput{})
+	require.Equal(t, "block", *out.Decision)
+	require.False(t, called)
+
+	async := func(context.Context, Input, *string, *Context) (JSONOutput, error) {
+		return &AsyncJSONOutput{Async: true}, nil
+	}
+
+	_, err := Chain(async)(context.Background(), &StopInput{}, nil, &Context{})
+	require.Error(t, err)
+}
+
+func TestOnSessionStartAndRawOutput(t *testing.T) {
+	cb := OnSessionStart(func(_ context.Context, in *SessionStartInput) (ContextDecision, error) {
+		return ContextDecision{AdditionalContext: "source " + in.Source}, nil
+	})
+
+	out := run(t, Chain(cb, cb), &SessionStartInput{Source: "compact"})
+	require.Equal(t, &SessionStartSpecificOutput{
+		HookEventName:     "SessionStart",
+		AdditionalContext: new("source compact\nsource compact"),
+	}, out.HookSpecificOutput)
+
+	data, err := json.Marshal(&RawSpecificOutput{
+		HookEventName: "FutureEvent",
+		Fields:        map[string]any{"hookEventName": "ignored", "extra": 1},
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"hookEventName":"FutureEvent","extra":1}`, string(data))
+
+	require.Equal(t, "compact", Subject(&SessionStartInput{Source: "compact"}))
+	require.Equal(t, Event("FutureEvent"), (&UnknownInput{HookEventName: "FutureEvent"}).GetHookEventName())
+}
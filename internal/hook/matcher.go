@@ -0,0 +1,114 @@
+package hook
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Subject returns the value a matcher pattern is tested against for input.
+// Tool events match on the tool name, PreCompact on the trigger, Notification
+// on the notification type, subagent events on the agent type, SessionStart on
+// the source and SessionEnd on the reason. Other events have no subject and
+// return "".
+func Subject(input Input) string {
+	switch in := input.(type) {
+	case *PreToolUseInput:
+		return in.ToolName
+	case *PostToolUseInput:
+		return in.ToolName
+	case *PostToolUseFailureInput:
+		return in.ToolName
+	case *PermissionRequestInput:
+		return in.ToolName
+	case *PreCompactInput:
+		return in.Trigger
+	case *NotificationInput:
+		return in.NotificationType
+	case *SubagentStartInput:
+		return in.AgentType
+	case *SubagentStopInput:
+		return in.AgentType
+	case *SessionStartInput:
+		return in.Source
+	case *SessionEndInput:
+		return in.Reason
+	default:
+		return ""
+	}
+}
+
+// MatchRegex returns a Matcher whose hooks only run when the input's Subject
+// fully matches the regular expression pattern, e.g. "mcp__.*__write_.*".
+// The pattern is evaluated by the SDK; the CLI sends every event to it.
+func MatchRegex(pattern string, hooks ...Callback) (*Matcher, error) {
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("compile hook matcher %q: %w", pattern, err)
+	}
+
+	return &Matcher{
+		Hooks: hooks,
+		Predicate: func(input Input) bool {
+			return re.MatchString(Subject(input))
+		},
+	}, nil
+}
+
+// MustMatchRegex is like MatchRegex but panics if pattern does not compile.
+func MustMatchRegex(pattern string, hooks ...Callback) *Matcher {
+	m, err := MatchRegex(pattern, hooks...)
+	if err != nil {
+		panic(err)
+	}
+
+	return m
+}
+
+// MatchGlob returns a Matcher whose hooks only run when the input's Subject
+// matches the glob pattern, e.g. "mcp__github__*". Pipe-separated
+// alternatives such as "Write|Edit|mcp__fs__*" are accepted. Pattern syntax
+// follows path.Match.
+func MatchGlob(pattern string, hooks ...Callback) (*Matcher, error) {
+	alternatives := strings.Split(pattern, "|")
+
+	for _, alt := range alternatives {
+		if _, err := path.Match(alt, ""); err != nil {
+			return nil, fmt.Errorf("compile hook matcher %q: %w", pattern, err)
+		}
+	}
+
+	return &Matcher{
+		Hooks: hooks,
+		Predicate: func(input Input) bool {
+			subject := Subject(input)
+
+			for _, alt := range alternatives {
+				if ok, _ := path.Match(alt, subject); ok {
+					return true
+				}
+			}
+
+			return false
+		},
+	}, nil
+}
+
+// Filter returns a Callback that runs cb only for inputs accepted by predicate.
+// Rejected inputs return an empty SyncJSONOutput, which the CLI treats as
+// "continue".
+func Filter(predicate func(Input) bool, cb Callback) Callback {
+	if predicate == nil {
+		return cb
+	}
+
+	return func(ctx context.Context, input Input, toolUseID *string, hookCtx *Context) (JSONOutput, error) {
+		if !predicate(input) {
+			return &SyncJSONOutput{}, nil
+		}
+
+		return cb(ctx, input, toolUseID, hookCtx)
+	}
+}
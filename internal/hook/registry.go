@@ -0,0 +1,162 @@
+package hook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrEventNotRegistered is returned by Registry.Add for events the registry
+// was not created with. The CLI only learns about hook events at initialize
+// time, so a running session cannot start forwarding a new event.
+var ErrEventNotRegistered = errors.New("hook event not registered with the registry")
+
+// Registry holds hooks that can be added and removed while a session runs.
+//
+// At initialize time the session registers one catch-all matcher per registry
+// event with the CLI; the registry then dispatches each invocation to the
+// matchers currently registered for that event. Because every invocation of a
+// registry event round-trips to the SDK, create the registry only with the
+// events you expect to hook.
+type Registry struct {
+	mu      sync.RWMutex
+	events  []Event
+	entries map[Event][]registryEntry
+	nextID  int
+}
+
+type registryEntry struct {
+	id      string
+	matcher *Matcher
+}
+
+// NewRegistry creates a Registry that dispatches the given events.
+func NewRegistry(events ...Event) *Registry {
+	r := &Registry{entries: make(map[Event][]registryEntry, len(events))}
+
+	for _, event := range events {
+		if !slices.Contains(r.events, event) {
+			r.events = append(r.events, event)
+		}
+	}
+
+	return r
+}
+
+// Events returns the events the registry dispatches.
+func (r *Registry) Events() []Event {
+	return slices.Clone(r.events)
+}
+
+// Add registers m for event and returns an ID for Remove. Matchers run in
+// the order they were added. It returns ErrEventNotRegistered if the registry
+// was not created with event.
+func (r *Registry) Add(event Event, m *Matcher) (string, error) {
+	if m == nil {
+		return "", fmt.Errorf("add %s hook: nil matcher", event)
+	}
+
+	if !slices.Contains(r.events, event) {
+		return "", fmt.Errorf("add %s hook: %w", event, ErrEventNotRegistered)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	id := fmt.Sprintf("dyn_%d", r.nextID)
+	r.entries[event] = append(r.entries[event], registryEntry{id: id, matcher: m})
+
+	return id, nil
+}
+
+// Remove unregisters the matcher with the given ID and reports whether it was
+// registered. Invocations already in flight are not affected.
+func (r *Registry) Remove(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for event, entries := range r.entries {
+		for i, entry := range entries {
+			if entry.id == id {
+				r.entries[event] = slices.Delete(slices.Clone(entries), i, i+1)
+
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Dispatcher returns the catch-all Callback registered with the CLI for event.
+//
+// Matching hooks run as a Chain, so the first deny wins and updated inputs
+// compose across matchers. A lone matching hook is called directly and may
+// return async output.
+func (r *Registry) Dispatcher(event Event) Callback {
+	return func(ctx context.Context, input Input, toolUseID *string, hookCtx *Context) (JSONOutput, error) {
+		r.mu.RLock()
+		entries := r.entries[event]
+		r.mu.RUnlock()
+
+		var callbacks []Callback
+
+		for _, entry := range entries {
+			if !entry.matcher.Matches(input) {
+				continue
+			}
+
+			for _, cb := range entry.matcher.Hooks {
+				callbacks = append(callbacks, withTimeout(cb, entry.matcher.Timeout))
+			}
+		}
+
+		switch len(callbacks) {
+		case 0:
+			return &SyncJSONOutput{}, nil
+		case 1:
+			return callbacks[0](ctx, input, toolUseID, hookCtx)
+		default:
+			return Chain(callbacks...)(ctx, input, toolUseID, hookCtx)
+		}
+	}
+}
+
+// Matches reports whether m applies to input. Matcher is compared the way the
+// CLI compares it: pipe-separated exact names, with nil, "" and "*" matching
+// everything. Predicate, when set, must also accept the input.
+func (m *Matcher) Matches(input Input) bool {
+	return MatchName(m.Matcher, input) && (m.Predicate == nil || m.Predicate(input))
+}
+
+// MatchName reports whether the CLI would route input to a matcher with the
+// given pattern: pipe-separated exact names compared against Subject, with
+// nil, "" and "*" matching everything.
+func MatchName(pattern *string, input Input) bool {
+	if pattern == nil || *pattern == "" || *pattern == "*" {
+		return true
+	}
+
+	return slices.Contains(strings.Split(*pattern, "|"), Subject(input))
+}
+
+// withTimeout bounds cb by the matcher timeout in seconds, if one is set.
+func withTimeout(cb Callback, seconds *float64) Callback {
+	if seconds == nil || *seconds <= 0 {
+		return cb
+	}
+
+	timeout := time.Duration(*seconds * float64(time.Second))
+
+	return func(ctx context.Context, input Input, toolUseID *string, hookCtx *Context) (JSONOutput, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		return cb(ctx, input, toolUseID, hookCtx)
+	}
+}
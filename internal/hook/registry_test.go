@@ -0,0 +1,92 @@
+package hook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_AddRemoveDispatch(t *testing.T) {
+	r := NewRegistry(EventPreToolUse, EventPreToolUse)
+	require.Equal(t, []Event{EventPreToolUse}, r.Events())
+
+	dispatch := r.Dispatcher(EventPreToolUse)
+
+	require.Equal(t, &SyncJSONOutput{}, run(t, dispatch, preToolUse("Bash", nil)),
+		"no hooks registered yet")
+
+	deny := OnPreToolUse(func(context.Context, *PreToolUseInput) (PreToolUseDecision, error) {
+		return PreToolUseDecision{Permission: PermissionDeny, Reason: "production"}, nil
+	})
+
+	id, err := r.Add(EventPreToolUse, &Matcher{Matcher: new("Bash|Write"), Hooks: []Callback{deny}})
+	require.NoError(t, err)
+
+	out := run(t, dispatch, preToolUse("Bash", nil))
+	specific, ok := out.HookSpecificOutput.(*PreToolUseSpecificOutput)
+	require.True(t, ok)
+	require.Equal(t, "deny", *specific.PermissionDecision)
+
+	require.Equal(t, &SyncJSONOutput{}, run(t, dispatch, preToolUse("Read", nil)),
+		"matcher names are exact")
+
+	require.True(t, r.Remove(id))
+	require.False(t, r.Remove(id))
+	require.Equal(t, &SyncJSONOutput{}, run(t, dispatch, preToolUse("Bash", nil)))
+}
+
+func TestRegistry_AddUnregisteredEvent(t *testing.T) {
+	r := NewRegistry(EventPreToolUse)
+
+	_, err := r.Add(EventStop, &Matcher{})
+	require.ErrorIs(t, err, ErrEventNotRegistered)
+
+	_, err = r.Add(EventPreToolUse, nil)
+	require.Error(t, err)
+}
+
+func TestRegistry_ChainsMatchersInOrder(t *testing.T) {
+	r := NewRegistry(EventPreToolUse)
+
+	setKey := func(key string) Callback {
+		return OnPreToolUse(func(_ context.Context, in *PreToolUseInput) (PreToolUseDecision, error) {
+			updated := map[string]any{key: true}
+			for k, v := range in.ToolInput {
+				updated[k] = v
+			}
+
+			return PreToolUseDecision{UpdatedInput: updated}, nil
+		})
+	}
+
+	_, err := r.Add(EventPreToolUse, &Matcher{Hooks: []Callback{setKey("a")}})
+	require.NoError(t, err)
+
+	_, err = r.Add(EventPreToolUse, &Matcher{Matcher: new("*"), Hooks: []Callback{setKey("b")}})
+	require.NoError(t, err)
+
+	out := run(t, r.Dispatcher(EventPreToolUse), preToolUse("Bash", map[string]any{"command": "ls"}))
+	specific, ok := out.HookSpecificOutput.(*PreToolUseSpecificOutput)
+	require.True(t, ok)
+	require.Equal(t, map[string]any{"command": "ls", "a": true, "b": true}, specific.UpdatedInput)
+}
+
+func TestRegistry_MatcherTimeout(t *testing.T) {
+	r := NewRegistry(EventStop)
+
+	var hasDeadline bool
+
+	_, err := r.Add(EventStop, &Matcher{
+		Timeout: new(1.5),
+		Hooks: []Callback{func(ctx context.Context, _ Input, _ *string, _ *Context) (JSONOutput, error) {
+			_, hasDeadline = ctx.Deadline()
+
+			return &SyncJSONOutput{}, nil
+		}},
+	})
+	require.NoError(t, err)
+
+	run(t, r.Dispatcher(EventStop), &StopInput{})
+	require.True(t, hasDeadline)
+}
@@ -0,0 +1,389 @@
+package hook
+
+import "context"
+
+// PermissionDecision is the permission outcome of a PreToolUse hook.
+type PermissionDecision string
+
+const (
+	// PermissionDefault leaves the decision to the normal permission flow.
+	PermissionDefault PermissionDecision = ""
+	// PermissionAllow approves the tool call without prompting.
+	PermissionAllow PermissionDecision = "allow"
+	// PermissionDeny rejects the tool call.
+	PermissionDeny PermissionDecision = "deny"
+	// PermissionAsk asks the user to confirm the tool call.
+	PermissionAsk PermissionDecision = "ask"
+)
+
+// decisionBlock is the value of SyncJSONOutput.Decision that blocks an event.
+const decisionBlock = "block"
+
+// Outcome holds the fields shared by every typed hook decision.
+type Outcome struct {
+	// Stop halts the session after the hook runs (continue: false).
+	Stop bool
+	// StopReason is shown to the user when Stop is set.
+	StopReason string
+	// SystemMessage is a warning shown to the user.
+	SystemMessage string
+	// SuppressOutput hides the hook's output from the transcript.
+	SuppressOutput bool
+}
+
+// apply copies the set fields of o onto out.
+func (o Outcome) apply(out *SyncJSONOutput) {
+	if o.Stop {
+		out.Continue = new(false)
+	}
+
+	if o.StopReason != "" {
+		out.StopReason = new(o.StopReason)
+	}
+
+	if o.SystemMessage != "" {
+		out.SystemMessage = new(o.SystemMessage)
+	}
+
+	if o.SuppressOutput {
+		out.SuppressOutput = new(true)
+	}
+}
+
+// PreToolUseDecision is the typed result of a PreToolUse hook.
+type PreToolUseDecision struct {
+	Outcome
+
+	// Permission approves, denies or escalates the tool call.
+	Permission PermissionDecision
+	// Reason explains Permission; for denials it is shown to Claude.
+	Reason string
+	// UpdatedInput replaces the tool input before the tool runs.
+	UpdatedInput map[string]any
+	// AdditionalContext is added to the conversation for Claude.
+	AdditionalContext string
+}
+
+// Output converts d to its wire representation.
+func (d PreToolUseDecision) Output() *SyncJSONOutput {
+	out := &SyncJSONOutput{}
+	d.apply(out)
+
+	if d.Permission != PermissionDefault || d.Reason != "" || d.UpdatedInput != nil || d.AdditionalContext != "" {
+		specific := &PreToolUseSpecificOutput{
+			HookEventName:     string(EventPreToolUse),
+			UpdatedInput:      d.UpdatedInput,
+			AdditionalContext: optional(d.AdditionalContext),
+		}
+
+		if d.Permission != PermissionDefault {
+			specific.PermissionDecision = new(string(d.Permission))
+		}
+
+		specific.PermissionDecisionReason = optional(d.Reason)
+		out.HookSpecificOutput = specific
+	}
+
+	return out
+}
+
+// PostToolUseDecision is the typed result of a PostToolUse hook.
+type PostToolUseDecision struct {
+	Outcome
+
+	// Block feeds Reason back to Claude as an error about the tool result.
+	Block bool
+	// Reason explains Block.
+	Reason string
+	// AdditionalContext is added to the conversation for Claude.
+	AdditionalContext string
+	// UpdatedMCPToolOutput replaces the output of an MCP tool.
+	UpdatedMCPToolOutput any
+}
+
+// Output converts d to its wire representation.
+func (d PostToolUseDecision) Output() *SyncJSONOutput {
+	out := blockOutput(d.Outcome, d.Block, d.Reason)
+
+	if d.AdditionalContext != "" || d.UpdatedMCPToolOutput != nil {
+		out.HookSpecificOutput = &PostToolUseSpecificOutput{
+			HookEventName:        string(EventPostToolUse),
+			AdditionalContext:    optional(d.AdditionalContext),
+			UpdatedMCPToolOutput: d.UpdatedMCPToolOutput,
+		}
+	}
+
+	return out
+}
+
+// PostToolUseFailureDecision is the typed result of a PostToolUseFailure hook.
+type PostToolUseFailureDecision struct {
+	Outcome
+
+	// AdditionalContext is added to the conversation for Claude.
+	AdditionalContext string
+}
+
+// Output converts d to its wire representation.
+func (d PostToolUseFailureDecision) Output() *SyncJSONOutput {
+	out := &SyncJSONOutput{}
+	d.apply(out)
+
+	if d.AdditionalContext != "" {
+		out.HookSpecificOutput = &PostToolUseFailureSpecificOutput{
+			HookEventName:     string(EventPostToolUseFailure),
+			AdditionalContext: new(d.AdditionalContext),
+		}
+	}
+
+	return out
+}
+
+// UserPromptSubmitDecision is the typed result of a UserPromptSubmit hook.
+type UserPromptSubmitDecision struct {
+	Outcome
+
+	// Block rejects the prompt; Reason is shown to the user.
+	Block bool
+	// Reason explains Block.
+	Reason string
+	// AdditionalContext is added to the conversation alongside the prompt.
+	AdditionalContext string
+}
+
+// Output converts d to its wire representation.
+func (d UserPromptSubmitDecision) Output() *SyncJSONOutput {
+	out := blockOutput(d.Outcome, d.Block, d.Reason)
+
+	if d.AdditionalContext != "" {
+		out.HookSpecificOutput = &UserPromptSubmitSpecificOutput{
+			HookEventName:     string(EventUserPromptSubmit),
+			AdditionalContext: new(d.AdditionalContext),
+		}
+	}
+
+	return out
+}
+
+// StopDecision is the typed result of a Stop or SubagentStop hook.
+type StopDecision struct {
+	Outcome
+
+	// Block prevents Claude from stopping; Reason tells it how to proceed.
+	Block bool
+	// Reason explains Block.
+	Reason string
+}
+
+// Output converts d to its wire representation.
+func (d StopDecision) Output() *SyncJSONOutput {
+	return blockOutput(d.Outcome, d.Block, d.Reason)
+}
+
+// ContextDecision is the typed result of hooks whose only event-specific
+// output is additional context: Notification, SubagentStart and SessionStart.
+type ContextDecision struct {
+	Outcome
+
+	// AdditionalContext is added to the conversation for Claude.
+	AdditionalContext string
+}
+
+// output converts d to its wire representation for event.
+func (d ContextDecision) output(event Event) *SyncJSONOutput {
+	out := &SyncJSONOutput{}
+	d.apply(out)
+
+	if d.AdditionalContext == "" {
+		return out
+	}
+
+	switch event {
+	case EventNotification:
+		out.HookSpecificOutput = &NotificationSpecificOutput{
+			HookEventName:     string(event),
+			AdditionalContext: new(d.AdditionalContext),
+		}
+	case EventSubagentStart:
+		out.HookSpecificOutput = &SubagentStartSpecificOutput{
+			HookEventName:     string(event),
+			AdditionalContext: new(d.AdditionalContext),
+		}
+	case EventSessionStart:
+		out.HookSpecificOutput = &SessionStartSpecificOutput{
+			HookEventName:     string(event),
+			AdditionalContext: new(d.AdditionalContext),
+		}
+	}
+
+	return out
+}
+
+// PermissionRequestDecision is the typed result of a PermissionRequest hook.
+type PermissionRequestDecision struct {
+	Outcome
+
+	// Permission is PermissionAllow or PermissionDeny; PermissionDefault
+	// shows the regular permission prompt.
+	Permission PermissionDecision
+	// UpdatedInput replaces the tool input when allowing.
+	UpdatedInput map[string]any
+	// Message explains a denial to Claude.
+	Message string
+	// Interrupt stops the current turn when denying.
+	Interrupt bool
+}
+
+// Output converts d to its wire representation.
+func (d PermissionRequestDecision) Output() *SyncJSONOutput {
+	out := &SyncJSONOutput{}
+	d.apply(out)
+
+	var decision map[string]any
+
+	switch d.Permission {
+	case PermissionAllow:
+		decision = map[string]any{"behavior": string(PermissionAllow)}
+		if d.UpdatedInput != nil {
+			decision["updatedInput"] = d.UpdatedInput
+		}
+	case PermissionDeny:
+		decision = map[string]any{"behavior": string(PermissionDeny)}
+		if d.Message != "" {
+			decision["message"] = d.Message
+		}
+
+		if d.Interrupt {
+			decision["interrupt"] = true
+		}
+	}
+
+	if decision != nil {
+		out.HookSpecificOutput = &PermissionRequestSpecificOutput{
+			HookEventName: string(EventPermissionRequest),
+			Decision:      decision,
+		}
+	}
+
+	return out
+}
+
+// OnPreToolUse adapts a typed PreToolUse handler to a Callback.
+func OnPreToolUse(fn func(context.Context, *PreToolUseInput) (PreToolUseDecision, error)) Callback {
+	return typed(fn, PreToolUseDecision.Output)
+}
+
+// OnPostToolUse adapts a typed PostToolUse handler to a Callback.
+func OnPostToolUse(fn func(context.Context, *PostToolUseInput) (PostToolUseDecision, error)) Callback {
+	return typed(fn, PostToolUseDecision.Output)
+}
+
+// OnPostToolUseFailure adapts a typed PostToolUseFailure handler to a Callback.
+func OnPostToolUseFailure(
+	fn func(context.Context, *PostToolUseFailureInput) (PostToolUseFailureDecision, error),
+) Callback {
+	return typed(fn, PostToolUseFailureDecision.Output)
+}
+
+// OnUserPromptSubmit adapts a typed UserPromptSubmit handler to a Callback.
+func OnUserPromptSubmit(
+	fn func(context.Context, *UserPromptSubmitInput) (UserPromptSubmitDecision, error),
+) Callback {
+	return typed(fn, UserPromptSubmitDecision.Output)
+}
+
+// OnStop adapts a typed Stop handler to a Callback.
+func OnStop(fn func(context.Context, *StopInput) (StopDecision, error)) Callback {
+	return typed(fn, StopDecision.Output)
+}
+
+// OnSubagentStop adapts a typed SubagentStop handler to a Callback.
+func OnSubagentStop(fn func(context.Context, *SubagentStopInput) (StopDecision, error)) Callback {
+	return typed(fn, StopDecision.Output)
+}
+
+// OnSubagentStart adapts a typed SubagentStart handler to a Callback.
+func OnSubagentStart(fn func(context.Context, *SubagentStartInput) (ContextDecision, error)) Callback {
+	return typed(fn, func(d ContextDecision) *SyncJSONOutput { return d.output(EventSubagentStart) })
+}
+
+// OnNotification adapts a typed Notification handler to a Callback.
+func OnNotification(fn func(context.Context, *NotificationInput) (ContextDecision, error)) Callback {
+	return typed(fn, func(d ContextDecision) *SyncJSONOutput { return d.output(EventNotification) })
+}
+
+// OnSessionStart adapts a typed SessionStart handler to a Callback.
+// AdditionalContext is injected into the new or resumed session.
+func OnSessionStart(fn func(context.Context, *SessionStartInput) (ContextDecision, error)) Callback {
+	return typed(fn, func(d ContextDecision) *SyncJSONOutput { return d.output(EventSessionStart) })
+}
+
+// OnSessionEnd adapts a typed SessionEnd handler to a Callback.
+func OnSessionEnd(fn func(context.Context, *SessionEndInput) (Outcome, error)) Callback {
+	return typed(fn, outcomeOutput)
+}
+
+// OnPreCompact adapts a typed PreCompact handler to a Callback.
+func OnPreCompact(fn func(context.Context, *PreCompactInput) (Outcome, error)) Callback {
+	return typed(fn, outcomeOutput)
+}
+
+// OnPermissionRequest adapts a typed PermissionRequest handler to a Callback.
+func OnPermissionRequest(
+	fn func(context.Context, *PermissionRequestInput) (PermissionRequestDecision, error),
+) Callback {
+	return typed(fn, PermissionRequestDecision.Output)
+}
+
+// typed builds a Callback that forwards inputs of type I to fn and converts
+// its decision with toOutput. Inputs of any other type continue untouched, so
+// a typed callback registered under the wrong event is a no-op.
+func typed[I Input, D any](
+	fn func(context.Context, I) (D, error),
+	toOutput func(D) *SyncJSONOutput,
+) Callback {
+	return func(ctx context.Context, input Input, _ *string, _ *Context) (JSONOutput, error) {
+		in, ok := input.(I)
+		if !ok {
+			return &SyncJSONOutput{}, nil
+		}
+
+		decision, err := fn(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+
+		return toOutput(decision), nil
+	}
+}
+
+// outcomeOutput builds the output for events without event-specific fields.
+func outcomeOutput(o Outcome) *SyncJSONOutput {
+	out := &SyncJSONOutput{}
+	o.apply(out)
+
+	return out
+}
+
+// blockOutput builds the output for events that support decision: "block".
+func blockOutput(o Outcome, block bool, reason string) *SyncJSONOutput {
+	out := &SyncJSONOutput{}
+	o.apply(out)
+
+	if block {
+		out.Decision = new(decisionBlock)
+	}
+
+	out.Reason = optional(reason)
+
+	return out
+}
+
+// optional returns a pointer to s, or nil when s is empty.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
@@ -0,0 +1,278 @@
+package hook
+
+import (
+	"context"
+	"fmt"
+)
+
+// Invoke runs cb for a hook_callback request the way the session does: it
+// parses inputData, calls cb and converts its output to the response map sent
+// back to the CLI.
+func Invoke(ctx context.Context, cb Callback, inputData map[string]any, toolUseID *string) (map[string]any, error) {
+	input, err := ParseInput(inputData)
+	if err != nil {
+		return nil, fmt.Errorf("parse hook input: %w", err)
+	}
+
+	output, err := cb(ctx, input, toolUseID, &Context{})
+	if err != nil {
+		return nil, fmt.Errorf("hook callback error: %w", err)
+	}
+
+	return ConvertOutput(output)
+}
+
+// ParseInput converts a hook input as sent by the CLI to the matching Input
+// type. Events unknown to this SDK version are returned as *UnknownInput.
+func ParseInput(inputData map[string]any) (Input, error) {
+	if inputData == nil {
+		return nil, fmt.Errorf("input data is nil")
+	}
+
+	hookEventName, _ := inputData["hook_event_name"].(string)
+	sessionID, _ := inputData["session_id"].(string)
+	transcriptPath, _ := inputData["transcript_path"].(string)
+	cwd, _ := inputData["cwd"].(string)
+
+	var permissionMode *string
+	if pm, ok := inputData["permission_mode"].(string); ok {
+		permissionMode = &pm
+	}
+
+	baseInput := BaseInput{
+		SessionID:      sessionID,
+		TranscriptPath: transcriptPath,
+		Cwd:            cwd,
+		PermissionMode: permissionMode,
+	}
+
+	switch hookEventName {
+	case string(EventPreToolUse):
+		toolName, _ := inputData["tool_name"].(string)
+		toolInput, _ := inputData["tool_input"].(map[string]any)
+		toolUseID, _ := inputData["tool_use_id"].(string)
+
+		return &PreToolUseInput{
+			BaseInput:     baseInput,
+			HookEventName: hookEventName,
+			ToolName:      toolName,
+			ToolInput:     toolInput,
+			ToolUseID:     toolUseID,
+		}, nil
+
+	case string(EventPostToolUse):
+		toolName, _ := inputData["tool_name"].(string)
+		toolInput, _ := inputData["tool_input"].(map[string]any)
+		toolUseID, _ := inputData["tool_use_id"].(string)
+		toolResponse := inputData["tool_response"]
+
+		return &PostToolUseInput{
+			BaseInput:     baseInput,
+			HookEventName: hookEventName,
+			ToolName:      toolName,
+			ToolInput:     toolInput,
+			ToolUseID:     toolUseID,
+			ToolResponse:  toolResponse,
+		}, nil
+
+	case string(EventUserPromptSubmit):
+		prompt, _ := inputData["prompt"].(string)
+
+		return &UserPromptSubmitInput{
+			BaseInput:     baseInput,
+			HookEventName: hookEventName,
+			Prompt:        prompt,
+		}, nil
+
+	case string(EventStop):
+		stopHookActive, _ := inputData["stop_hook_active"].(bool)
+
+		return &StopInput{
+			BaseInput:      baseInput,
+			HookEventName:  hookEventName,
+			StopHookActive: stopHookActive,
+		}, nil
+
+	case string(EventSubagentStop):
+		stopHookActive, _ := inputData["stop_hook_active"].(bool)
+		agentID, _ := inputData["agent_id"].(string)
+		agentTranscriptPath, _ := inputData["agent_transcript_path"].(string)
+		agentType, _ := inputData["agent_type"].(string)
+
+		return &SubagentStopInput{
+			BaseInput:           baseInput,
+			HookEventName:       hookEventName,
+			StopHookActive:      stopHookActive,
+			AgentID:             agentID,
+			AgentTranscriptPath: agentTranscriptPath,
+			AgentType:           agentType,
+		}, nil
+
+	case string(EventPreCompact):
+		trigger, _ := inputData["trigger"].(string)
+
+		var customInstructions *string
+		if ci, ok := inputData["custom_instructions"].(string); ok && ci != "" {
+			customInstructions = &ci
+		}
+
+		return &PreCompactInput{
+			BaseInput:          baseInput,
+			HookEventName:      hookEventName,
+			Trigger:            trigger,
+			CustomInstructions: customInstructions,
+		}, nil
+
+	case string(EventPostToolUseFailure):
+		toolName, _ := inputData["tool_name"].(string)
+		toolInput, _ := inputData["tool_input"].(map[string]any)
+		toolUseID, _ := inputData["tool_use_id"].(string)
+		toolError, _ := inputData["error"].(string)
+
+		var isInterrupt *bool
+		if v, ok := inputData["is_interrupt"].(bool); ok {
+			isInterrupt = &v
+		}
+
+		return &PostToolUseFailureInput{
+			BaseInput:     baseInput,
+			HookEventName: hookEventName,
+			ToolName:      toolName,
+			ToolInput:     toolInput,
+			ToolUseID:     toolUseID,
+			Error:         toolError,
+			IsInterrupt:   isInterrupt,
+		}, nil
+
+	case string(EventNotification):
+		msg, _ := inputData["message"].(string)
+		notificationType, _ := inputData["notification_type"].(string)
+
+		var title *string
+		if t, ok := inputData["title"].(string); ok && t != "" {
+			title = &t
+		}
+
+		return &NotificationInput{
+			BaseInput:        baseInput,
+			HookEventName:    hookEventName,
+			Message:          msg,
+			Title:            title,
+			NotificationType: notificationType,
+		}, nil
+
+	case string(EventSubagentStart):
+		agentID, _ := inputData["agent_id"].(string)
+		agentType, _ := inputData["agent_type"].(string)
+
+		return &SubagentStartInput{
+			BaseInput:     baseInput,
+			HookEventName: hookEventName,
+			AgentID:       agentID,
+			AgentType:     agentType,
+		}, nil
+
+	case string(EventPermissionRequest):
+		toolName, _ := inputData["tool_name"].(string)
+		toolInput, _ := inputData["tool_input"].(map[string]any)
+
+		var permissionSuggestions []any
+		if ps, ok := inputData["permission_suggestions"].([]any); ok {
+			permissionSuggestions = ps
+		}
+
+		return &PermissionRequestInput{
+			BaseInput:             baseInput,
+			HookEventName:         hookEventName,
+			ToolName:              toolName,
+			ToolInput:             toolInput,
+			PermissionSuggestions: permissionSuggestions,
+		}, nil
+
+	case string(EventSessionStart):
+		source, _ := inputData["source"].(string)
+
+		return &SessionStartInput{
+			BaseInput:     baseInput,
+			HookEventName: hookEventName,
+			Source:        source,
+		}, nil
+
+	case string(EventSessionEnd):
+		reason, _ := inputData["reason"].(string)
+
+		return &SessionEndInput{
+			BaseInput:     baseInput,
+			HookEventName: hookEventName,
+			Reason:        reason,
+		}, nil
+
+	default:
+		// Unknown event type (e.g. from a newer CLI) - pass the raw input through
+		// so callbacks can still inspect it.
+		return &UnknownInput{
+			BaseInput:     baseInput,
+			HookEventName: hookEventName,
+			Raw:           inputData,
+		}, nil
+	}
+}
+
+// ConvertOutput converts a callback's output to the response sent to the CLI.
+// A nil output continues with no special behavior.
+func ConvertOutput(output JSONOutput) (map[string]any, error) {
+	if output == nil {
+		// Default: continue with no special output
+		return map[string]any{
+			"continue": true,
+		}, nil
+	}
+
+	switch o := output.(type) {
+	case *SyncJSONOutput:
+		result := make(map[string]any, 8)
+
+		if o.Continue != nil {
+			result["continue"] = *o.Continue
+		} else {
+			result["continue"] = true
+		}
+
+		if o.SuppressOutput != nil {
+			result["suppressOutput"] = *o.SuppressOutput
+		}
+
+		if o.StopReason != nil {
+			result["stopReason"] = *o.StopReason
+		}
+
+		if o.Decision != nil {
+			result["decision"] = *o.Decision
+		}
+
+		if o.SystemMessage != nil {
+			result["systemMessage"] = *o.SystemMessage
+		}
+
+		if o.Reason != nil {
+			result["reason"] = *o.Reason
+		}
+
+		if o.HookSpecificOutput != nil {
+			result["hookSpecificOutput"] = o.HookSpecificOutput
+		}
+
+		return result, nil
+
+	case *AsyncJSONOutput:
+		return map[string]any{
+			"async":        o.Async,
+			"asyncTimeout": o.AsyncTimeout,
+		}, nil
+
+	default:
+		return map[string]any{
+			"continue": true,
+		}, nil
+	}
+}
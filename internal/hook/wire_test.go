@@ -0,0 +1,87 @@
+package hook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInput_SessionEvents(t *testing.T) {
+	input, err := ParseInput(map[string]any{
+		"hook_event_name": "SessionStart",
+		"session_id":      "abc",
+		"source":          "resume",
+	})
+	require.NoError(t, err)
+	require.Equal(t, &SessionStartInput{
+		BaseInput:     BaseInput{SessionID: "abc"},
+		HookEventName: "SessionStart",
+		Source:        "resume",
+	}, input)
+
+	input, err = ParseInput(map[string]any{
+		"hook_event_name": "SessionEnd",
+		"reason":          "logout",
+	})
+	require.NoError(t, err)
+
+	end, ok := input.(*SessionEndInput)
+	require.True(t, ok)
+	require.Equal(t, "logout", end.Reason)
+
+	raw := map[string]any{
+		"hook_event_name": "FutureEvent",
+		"cwd":             "/repo",
+		"payload":         map[string]any{"x": 1.0},
+	}
+
+	input, err = ParseInput(raw)
+	require.NoError(t, err)
+
+	unknown, ok := input.(*UnknownInput)
+	require.True(t, ok)
+	require.Equal(t, Event("FutureEvent"), unknown.GetHookEventName())
+	require.Equal(t, "/repo", unknown.GetCwd())
+	require.Equal(t, raw, unknown.Raw)
+}
+
+func TestParseInput_Nil(t *testing.T) {
+	_, err := ParseInput(nil)
+	require.Error(t, err)
+}
+
+func TestConvertOutput(t *testing.T) {
+	resp, err := ConvertOutput(nil)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"continue": true}, resp)
+
+	resp, err = ConvertOutput(StopDecision{Block: true, Reason: "wait", Outcome: Outcome{Stop: true}}.Output())
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"continue": false, "decision": "block", "reason": "wait"}, resp)
+
+	resp, err = ConvertOutput(&AsyncJSONOutput{Async: true})
+	require.NoError(t, err)
+	require.Equal(t, true, resp["async"])
+}
+
+func TestInvoke(t *testing.T) {
+	var gotToolUseID *string
+
+	cb := func(_ context.Context, in Input, toolUseID *string, _ *Context) (JSONOutput, error) {
+		gotToolUseID = toolUseID
+
+		return PreToolUseDecision{Permission: PermissionAllow}.Output(), nil
+	}
+
+	resp, err := Invoke(context.Background(), cb, map[string]any{
+		"hook_event_name": "PreToolUse",
+		"tool_name":       "Read",
+	}, new("toolu_1"))
+	require.NoError(t, err)
+	require.Equal(t, "toolu_1", *gotToolUseID)
+	require.Equal(t, true, resp["continue"])
+
+	_, err = Invoke(context.Background(), cb, nil, nil)
+	require.ErrorContains(t, err, "parse hook input")
+}
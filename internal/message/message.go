@@ -125,9 +125,15 @@ func (m *UserMessage) MessageType() string { return "user" }
 //
 //nolint:tagliatelle // Claude CLI uses snake_case
 type AssistantMessage struct {
-	Type            string                 `json:"type"`
-	Content         []ContentBlock         `json:"content"`
-	Model           string                 `json:"model"`
+	Type    string         `json:"type"`
+	Content []ContentBlock `json:"content"`
+	Model   string         `json:"model"`
+	// ID is the API message ID. The CLI may split one API message into several
+	// assistant messages that share the ID and repeat its Usage.
+	ID string `json:"id,omitempty"`
+	// UUID identifies the message in the session transcript.
+	UUID            string                 `json:"uuid,omitempty"`
+	Usage           *Usage                 `json:"usage,omitempty"`
 	ParentToolUseID *string                `json:"parent_tool_use_id,omitempty"`
 	Error           *AssistantMessageError `json:"error,omitempty"`
 }
@@ -178,6 +184,8 @@ type ResultMessage struct {
 	Usage            *Usage   `json:"usage,omitempty"`
 	Result           *string  `json:"result,omitempty"`
 	StructuredOutput any      `json:"structured_output,omitempty"`
+	// ModelUsage breaks usage and cost down by model, keyed by model ID.
+	ModelUsage map[string]ModelUsage `json:"modelUsage,omitempty"`
 }
 
 // MessageType implements the Message interface.
@@ -200,8 +208,21 @@ func (m *StreamEvent) MessageType() string { return "stream_event" }
 //
 //nolint:tagliatelle // Claude CLI uses snake_case
 type Usage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens,omitempty"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens,omitempty"`
+}
+
+// ModelUsage contains the usage and cost of one model in a result message.
+type ModelUsage struct {
+	InputTokens              int     `json:"inputTokens"`
+	OutputTokens             int     `json:"outputTokens"`
+	CacheReadInputTokens     int     `json:"cacheReadInputTokens"`
+	CacheCreationInputTokens int     `json:"cacheCreationInputTokens"`
+	WebSearchRequests        int     `json:"webSearchRequests"`
+	CostUSD                  float64 `json:"costUSD"`
+	ContextWindow            int     `json:"contextWindow,omitempty"`
 }
 
 // StreamingMessageContent represents the content of a streaming message.
@@ -134,11 +134,23 @@ func parseAssistantMessage(data map[string]any) (*AssistantMessage, error) {
 		msg.Model = model
 	}
 
+	if id, ok := messageData["id"].(string); ok {
+		msg.ID = id
+	}
+
+	if usageData, ok := messageData["usage"].(map[string]any); ok {
+		msg.Usage = parseUsage(usageData)
+	}
+
 	// Parse parent_tool_use_id from outer data (not messageData)
 	if parentToolUseID, ok := data["parent_tool_use_id"].(string); ok {
 		msg.ParentToolUseID = &parentToolUseID
 	}
 
+	if uuid, ok := data["uuid"].(string); ok {
+		msg.UUID = uuid
+	}
+
 	// Parse error from outer data (not messageData) — CLI puts error at top level
 	if errorVal, ok := data["error"].(string); ok {
 		errType := AssistantMessageError(errorVal)
@@ -148,6 +160,22 @@ func parseAssistantMessage(data map[string]any) (*AssistantMessage, error) {
 	return msg, nil
 }
 
+// parseUsage reads token counts from an API usage object.
+func parseUsage(data map[string]any) *Usage {
+	count := func(key string) int {
+		n, _ := data[key].(float64)
+
+		return int(n)
+	}
+
+	return &Usage{
+		InputTokens:              count("input_tokens"),
+		OutputTokens:             count("output_tokens"),
+		CacheCreationInputTokens: count("cache_creation_input_tokens"),
+		CacheReadInputTokens:     count("cache_read_input_tokens"),
+	}
+}
+
 // parseSystemMessage parses a SystemMessage from raw JSON.
 func parseSystemMessage(data map[string]any) (*SystemMessage, error) {
 	msg := &SystemMessage{
@@ -145,6 +145,57 @@ func TestParseAssistantMessage(t *testing.T) {
 	}
 }
 
+func TestParseUsage(t *testing.T) {
+	logger := slog.Default()
+
+	msg, err := Parse(logger, map[string]any{
+		"type": "assistant",
+		"uuid": "uuid-01",
+		"message": map[string]any{
+			"id":      "msg_01",
+			"model":   "claude-sonnet-4-6",
+			"content": []any{},
+			"usage": map[string]any{
+				"input_tokens":                float64(12),
+				"output_tokens":               float64(34),
+				"cache_creation_input_tokens": float64(56),
+				"cache_read_input_tokens":     float64(78),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	assistant, ok := msg.(*AssistantMessage)
+	require.True(t, ok)
+	require.Equal(t, "msg_01", assistant.ID)
+	require.Equal(t, "uuid-01", assistant.UUID)
+	require.Equal(t, &Usage{
+		InputTokens:              12,
+		OutputTokens:             34,
+		CacheCreationInputTokens: 56,
+		CacheReadInputTokens:     78,
+	}, assistant.Usage)
+
+	msg, err = Parse(logger, map[string]any{
+		"type":    "result",
+		"subtype": "success",
+		"usage":   map[string]any{"input_tokens": float64(1), "cache_read_input_tokens": float64(2)},
+		"modelUsage": map[string]any{
+			"claude-haiku-4-5": map[string]any{
+				"inputTokens":  float64(5),
+				"outputTokens": float64(6),
+				"costUSD":      0.01,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	result, ok := msg.(*ResultMessage)
+	require.True(t, ok)
+	require.Equal(t, 2, result.Usage.CacheReadInputTokens)
+	require.Equal(t, ModelUsage{InputTokens: 5, OutputTokens: 6, CostUSD: 0.01}, result.ModelUsage["claude-haiku-4-5"])
+}
+
 func TestParseUnknownMessageTypes(t *testing.T) {
 	logger := slog.Default()
 
@@ -0,0 +1,547 @@
+package message
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/errors"
+)
+
+// Built-in tool names.
+const (
+	ToolBash         = "Bash"
+	ToolRead         = "Read"
+	ToolWrite        = "Write"
+	ToolEdit         = "Edit"
+	ToolMultiEdit    = "MultiEdit"
+	ToolGlob         = "Glob"
+	ToolGrep         = "Grep"
+	ToolWebFetch     = "WebFetch"
+	ToolWebSearch    = "WebSearch"
+	ToolTask         = "Task"
+	ToolTodoWrite    = "TodoWrite"
+	ToolNotebookEdit = "NotebookEdit"
+	ToolExitPlanMode = "ExitPlanMode"
+)
+
+// ToolInput is the typed input of a built-in tool.
+type ToolInput interface {
+	ToolName() string
+}
+
+// ToolResult is the typed result of a built-in tool, as found in
+// UserMessage.ToolUseResult.
+type ToolResult interface {
+	ToolName() string
+}
+
+// Compile-time verification that all tool inputs and results implement
+// ToolInput and ToolResult.
+var (
+	_ ToolInput = (*BashInput)(nil)
+	_ ToolInput = (*ReadInput)(nil)
+	_ ToolInput = (*WriteInput)(nil)
+	_ ToolInput = (*EditInput)(nil)
+	_ ToolInput = (*MultiEditInput)(nil)
+	_ ToolInput = (*GlobInput)(nil)
+	_ ToolInput = (*GrepInput)(nil)
+	_ ToolInput = (*WebFetchInput)(nil)
+	_ ToolInput = (*WebSearchInput)(nil)
+	_ ToolInput = (*TaskInput)(nil)
+	_ ToolInput = (*TodoWriteInput)(nil)
+	_ ToolInput = (*NotebookEditInput)(nil)
+	_ ToolInput = (*ExitPlanModeInput)(nil)
+
+	_ ToolResult = (*BashResult)(nil)
+	_ ToolResult = (*ReadResult)(nil)
+	_ ToolResult = (*WriteResult)(nil)
+	_ ToolResult = (*EditResult)(nil)
+	_ ToolResult = (*MultiEditResult)(nil)
+	_ ToolResult = (*GlobResult)(nil)
+	_ ToolResult = (*GrepResult)(nil)
+	_ ToolResult = (*WebFetchResult)(nil)
+	_ ToolResult = (*WebSearchResult)(nil)
+	_ ToolResult = (*TaskResult)(nil)
+	_ ToolResult = (*TodoWriteResult)(nil)
+	_ ToolResult = (*NotebookEditResult)(nil)
+	_ ToolResult = (*ExitPlanModeResult)(nil)
+)
+
+// BashInput is the input of the Bash tool.
+//
+//nolint:tagliatelle // Claude CLI uses snake_case
+type BashInput struct {
+	Command     string `json:"command"`
+	Description string `json:"description,omitempty"`
+	// Timeout is in milliseconds.
+	Timeout                   int  `json:"timeout,omitempty"`
+	RunInBackground           bool `json:"run_in_background,omitempty"`
+	DangerouslyDisableSandbox bool `json:"dangerouslyDisableSandbox,omitempty"`
+}
+
+// ToolName implements the ToolInput interface.
+func (*BashInput) ToolName() string { return ToolBash }
+
+// ReadInput is the input of the Read tool.
+//
+//nolint:tagliatelle // Claude CLI uses snake_case
+type ReadInput struct {
+	FilePath string `json:"file_path"`
+	// Offset is the 1-based line to start reading at.
+	Offset int `json:"offset,omitempty"`
+	Limit  int `json:"limit,omitempty"`
+	// Pages selects PDF pages, e.g. "1-5".
+	Pages string `json:"pages,omitempty"`
+}
+
+// ToolName implements the ToolInput interface.
+func (*ReadInput) ToolName() string { return ToolRead }
+
+// WriteInput is the input of the Write tool.
+//
+//nolint:tagliatelle // Claude CLI uses snake_case
+type WriteInput struct {
+	FilePath string `json:"file_path"`
+	Content  string `json:"content"`
+}
+
+// ToolName implements the ToolInput interface.
+func (*WriteInput) ToolName() string { return ToolWrite }
+
+// EditInput is the input of the Edit tool.
+//
+//nolint:tagliatelle // Claude CLI uses snake_case
+type EditInput struct {
+	FilePath   string `json:"file_path"`
+	OldString  string `json:"old_string"`
+	NewString  string `json:"new_string"`
+	ReplaceAll bool   `json:"replace_all,omitempty"`
+}
+
+// ToolName implements the ToolInput interface.
+func (*EditInput) ToolName() string { return ToolEdit }
+
+// EditOperation is one replacement of a MultiEdit call.
+//
+//nolint:tagliatelle // Claude CLI uses snake_case
+type EditOperation struct {
+	OldString  string `json:"old_string"`
+	NewString  string `json:"new_string"`
+	ReplaceAll bool   `json:"replace_all,omitempty"`
+}
+
+// MultiEditInput is the input of the MultiEdit tool.
+//
+//nolint:tagliatelle // Claude CLI uses snake_case
+type MultiEditInput struct {
+	FilePath string          `json:"file_path"`
+	Edits    []EditOperation `json:"edits"`
+}
+
+// ToolName implements the ToolInput interface.
+func (*MultiEditInput) ToolName() string { return ToolMultiEdit }
+
+// GlobInput is the input of the Glob tool.
+type GlobInput struct {
+	Pattern string `json:"pattern"`
+	// Path is the directory to search; "" searches the working directory.
+	Path string `json:"path,omitempty"`
+}
+
+// ToolName implements the ToolInput interface.
+func (*GlobInput) ToolName() string { return ToolGlob }
+
+// GrepInput is the input of the Grep tool.
+//
+//nolint:tagliatelle // Claude CLI uses snake_case and ripgrep flag names
+type GrepInput struct {
+	Pattern string `json:"pattern"`
+	Path    string `json:"path,omitempty"`
+	Glob    string `json:"glob,omitempty"`
+	Type    string `json:"type,omitempty"`
+	// OutputMode is "content", "files_with_matches" or "count".
+	OutputMode      string `json:"output_mode,omitempty"`
+	CaseInsensitive bool   `json:"-i,omitempty"`
+	LineNumbers     bool   `json:"-n,omitempty"`
+	After           int    `json:"-A,omitempty"`
+	Before          int    `json:"-B,omitempty"`
+	Context         int    `json:"-C,omitempty"`
+	Multiline       bool   `json:"multiline,omitempty"`
+	HeadLimit       int    `json:"head_limit,omitempty"`
+	Offset          int    `json:"offset,omitempty"`
+}
+
+// ToolName implements the ToolInput interface.
+func (*GrepInput) ToolName() string { return ToolGrep }
+
+// WebFetchInput is the input of the WebFetch tool.
+type WebFetchInput struct {
+	URL    string `json:"url"`
+	Prompt string `json:"prompt"`
+}
+
+// ToolName implements the ToolInput interface.
+func (*WebFetchInput) ToolName() string { return ToolWebFetch }
+
+// WebSearchInput is the input of the WebSearch tool.
+//
+//nolint:tagliatelle // Claude CLI uses snake_case
+type WebSearchInput struct {
+	Query          string   `json:"query"`
+	AllowedDomains []string `json:"allowed_domains,omitempty"`
+	BlockedDomains []string `json:"blocked_domains,omitempty"`
+}
+
+// ToolName implements the ToolInput interface.
+func (*WebSearchInput) ToolName() string { return ToolWebSearch }
+
+// TaskInput is the input of the Task tool, which starts a subagent.
+//
+//nolint:tagliatelle // Claude CLI uses snake_case
+type TaskInput struct {
+	Description  string `json:"description"`
+	Prompt       string `json:"prompt"`
+	SubagentType string `json:"subagent_type"`
+	Model        string `json:"model,omitempty"`
+}
+
+// ToolName implements the ToolInput interface.
+func (*TaskInput) ToolName() string { return ToolTask }
+
+// Todo is an item of the todo list kept with TodoWrite.
+type Todo struct {
+	Content string `json:"content"`
+	// Status is "pending", "in_progress" or "completed".
+	Status string `json:"status"`
+	// ActiveForm is shown while the item is in progress.
+	ActiveForm string `json:"activeForm,omitempty"`
+}
+
+// TodoWriteInput is the input of the TodoWrite tool.
+type TodoWriteInput struct {
+	Todos []Todo `json:"todos"`
+}
+
+// ToolName implements the ToolInput interface.
+func (*TodoWriteInput) ToolName() string { return ToolTodoWrite }
+
+// NotebookEditInput is the input of the NotebookEdit tool.
+//
+//nolint:tagliatelle // Claude CLI uses snake_case
+type NotebookEditInput struct {
+	NotebookPath string `json:"notebook_path"`
+	CellID       string `json:"cell_id,omitempty"`
+	NewSource    string `json:"new_source"`
+	// CellType is "code" or "markdown".
+	CellType string `json:"cell_type,omitempty"`
+	// EditMode is "replace", "insert" or "delete".
+	EditMode string `json:"edit_mode,omitempty"`
+}
+
+// ToolName implements the ToolInput interface.
+func (*NotebookEditInput) ToolName() string { return ToolNotebookEdit }
+
+// ExitPlanModeInput is the input of the ExitPlanMode tool.
+type ExitPlanModeInput struct {
+	Plan string `json:"plan"`
+}
+
+// ToolName implements the ToolInput interface.
+func (*ExitPlanModeInput) ToolName() string { return ToolExitPlanMode }
+
+// Hunk is one hunk of a unified diff in a tool result.
+type Hunk struct {
+	OldStart int      `json:"oldStart"`
+	OldLines int      `json:"oldLines"`
+	NewStart int      `json:"newStart"`
+	NewLines int      `json:"newLines"`
+	Lines    []string `json:"lines"`
+}
+
+// BashResult is the result of the Bash tool.
+type BashResult struct {
+	Stdout      string `json:"stdout"`
+	Stderr      string `json:"stderr"`
+	Interrupted bool   `json:"interrupted"`
+	IsImage     bool   `json:"isImage,omitempty"`
+	// BackgroundTaskID is set for commands run in the background.
+	BackgroundTaskID         string `json:"backgroundTaskId,omitempty"`
+	ReturnCodeInterpretation string `json:"returnCodeInterpretation,omitempty"`
+}
+
+// ToolName implements the ToolResult interface.
+func (*BashResult) ToolName() string { return ToolBash }
+
+// ReadFile is the file content returned by the Read tool.
+type ReadFile struct {
+	FilePath   string `json:"filePath"`
+	Content    string `json:"content"`
+	NumLines   int    `json:"numLines"`
+	StartLine  int    `json:"startLine"`
+	TotalLines int    `json:"totalLines"`
+}
+
+// ReadResult is the result of the Read tool.
+type ReadResult struct {
+	// Type is "text" for text files; images, PDFs and notebooks have other
+	// types and leave File's line fields unset.
+	Type string    `json:"type"`
+	File *ReadFile `json:"file,omitempty"`
+}
+
+// ToolName implements the ToolResult interface.
+func (*ReadResult) ToolName() string { return ToolRead }
+
+// WriteResult is the result of the Write tool.
+type WriteResult struct {
+	// Type is "create" for new files and "update" for overwritten ones.
+	Type            string `json:"type"`
+	FilePath        string `json:"filePath"`
+	Content         string `json:"content"`
+	StructuredPatch []Hunk `json:"structuredPatch,omitempty"`
+	// OriginalFile is the previous content of an updated file.
+	OriginalFile *string `json:"originalFile,omitempty"`
+}
+
+// ToolName implements the ToolResult interface.
+func (*WriteResult) ToolName() string { return ToolWrite }
+
+// EditResult is the result of the Edit tool.
+type EditResult struct {
+	FilePath        string `json:"filePath"`
+	OldString       string `json:"oldString"`
+	NewString       string `json:"newString"`
+	OriginalFile    string `json:"originalFile"`
+	StructuredPatch []Hunk `json:"structuredPatch"`
+	// UserModified is true when the user changed the edit before accepting it.
+	UserModified bool `json:"userModified"`
+	ReplaceAll   bool `json:"replaceAll"`
+}
+
+// ToolName implements the ToolResult interface.
+func (*EditResult) ToolName() string { return ToolEdit }
+
+// MultiEditResult is the result of the MultiEdit tool.
+type MultiEditResult struct {
+	FilePath             string          `json:"filePath"`
+	Edits                []EditOperation `json:"edits"`
+	OriginalFileContents string          `json:"originalFileContents"`
+	StructuredPatch      []Hunk          `json:"structuredPatch"`
+	UserModified         bool            `json:"userModified"`
+}
+
+// ToolName implements the ToolResult interface.
+func (*MultiEditResult) ToolName() string { return ToolMultiEdit }
+
+// GlobResult is the result of the Glob tool.
+type GlobResult struct {
+	Filenames  []string `json:"filenames"`
+	NumFiles   int      `json:"numFiles"`
+	DurationMs int      `json:"durationMs"`
+	Truncated  bool     `json:"truncated"`
+}
+
+// ToolName implements the ToolResult interface.
+func (*GlobResult) ToolName() string { return ToolGlob }
+
+// GrepResult is the result of the Grep tool.
+type GrepResult struct {
+	// Mode is the output mode of the search.
+	Mode      string   `json:"mode"`
+	Filenames []string `json:"filenames"`
+	NumFiles  int      `json:"numFiles"`
+	// Content holds the matching lines in "content" mode.
+	Content      string `json:"content,omitempty"`
+	NumLines     int    `json:"numLines,omitempty"`
+	NumMatches   int    `json:"numMatches,omitempty"`
+	AppliedLimit int    `json:"appliedLimit,omitempty"`
+}
+
+// ToolName implements the ToolResult interface.
+func (*GrepResult) ToolName() string { return ToolGrep }
+
+// WebFetchResult is the result of the WebFetch tool.
+type WebFetchResult struct {
+	URL string `json:"url"`
+	// Code is the HTTP status code.
+	Code       int    `json:"code"`
+	CodeText   string `json:"codeText"`
+	Bytes      int    `json:"bytes"`
+	Result     string `json:"result"`
+	DurationMs int    `json:"durationMs"`
+}
+
+// ToolName implements the ToolResult interface.
+func (*WebFetchResult) ToolName() string { return ToolWebFetch }
+
+// WebSearchResult is the result of the WebSearch tool.
+type WebSearchResult struct {
+	Query string `json:"query"`
+	// Results mixes commentary strings with objects listing the pages found.
+	Results         []any   `json:"results"`
+	DurationSeconds float64 `json:"durationSeconds"`
+}
+
+// ToolName implements the ToolResult interface.
+func (*WebSearchResult) ToolName() string { return ToolWebSearch }
+
+// TaskResult is the result of the Task tool.
+type TaskResult struct {
+	Status  string `json:"status"`
+	Prompt  string `json:"prompt"`
+	AgentID string `json:"agentId"`
+	// Content is the subagent's final response.
+	Content           []TextBlock `json:"content"`
+	TotalDurationMs   int         `json:"totalDurationMs"`
+	TotalTokens       int         `json:"totalTokens"`
+	TotalToolUseCount int         `json:"totalToolUseCount"`
+	Usage             *Usage      `json:"usage,omitempty"`
+}
+
+// ToolName implements the ToolResult interface.
+func (*TaskResult) ToolName() string { return ToolTask }
+
+// TodoWriteResult is the result of the TodoWrite tool.
+type TodoWriteResult struct {
+	OldTodos []Todo `json:"oldTodos"`
+	NewTodos []Todo `json:"newTodos"`
+}
+
+// ToolName implements the ToolResult interface.
+func (*TodoWriteResult) ToolName() string { return ToolTodoWrite }
+
+// NotebookEditResult is the result of the NotebookEdit tool.
+//
+//nolint:tagliatelle // Claude CLI uses snake_case
+type NotebookEditResult struct {
+	NotebookPath string `json:"notebook_path"`
+	CellID       string `json:"cell_id"`
+	CellType     string `json:"cell_type"`
+	EditMode     string `json:"edit_mode"`
+	NewSource    string `json:"new_source"`
+	Language     string `json:"language"`
+	Error        string `json:"error,omitempty"`
+}
+
+// ToolName implements the ToolResult interface.
+func (*NotebookEditResult) ToolName() string { return ToolNotebookEdit }
+
+// ExitPlanModeResult is the result of the ExitPlanMode tool.
+type ExitPlanModeResult struct {
+	Plan    string `json:"plan"`
+	IsAgent bool   `json:"isAgent"`
+	// FilePath is where the plan was saved, if it was.
+	FilePath string `json:"filePath,omitempty"`
+}
+
+// ToolName implements the ToolResult interface.
+func (*ExitPlanModeResult) ToolName() string { return ToolExitPlanMode }
+
+// toolInputs creates an empty input for each built-in tool.
+var toolInputs = map[string]func() ToolInput{
+	ToolBash:         func() ToolInput { return &BashInput{} },
+	ToolRead:         func() ToolInput { return &ReadInput{} },
+	ToolWrite:        func() ToolInput { return &WriteInput{} },
+	ToolEdit:         func() ToolInput { return &EditInput{} },
+	ToolMultiEdit:    func() ToolInput { return &MultiEditInput{} },
+	ToolGlob:         func() ToolInput { return &GlobInput{} },
+	ToolGrep:         func() ToolInput { return &GrepInput{} },
+	ToolWebFetch:     func() ToolInput { return &WebFetchInput{} },
+	ToolWebSearch:    func() ToolInput { return &WebSearchInput{} },
+	ToolTask:         func() ToolInput { return &TaskInput{} },
+	ToolTodoWrite:    func() ToolInput { return &TodoWriteInput{} },
+	ToolNotebookEdit: func() ToolInput { return &NotebookEditInput{} },
+	ToolExitPlanMode: func() ToolInput { return &ExitPlanModeInput{} },
+}
+
+// toolResults creates an empty result for each built-in tool.
+var toolResults = map[string]func() ToolResult{
+	ToolBash:         func() ToolResult { return &BashResult{} },
+	ToolRead:         func() ToolResult { return &ReadResult{} },
+	ToolWrite:        func() ToolResult { return &WriteResult{} },
+	ToolEdit:         func() ToolResult { return &EditResult{} },
+	ToolMultiEdit:    func() ToolResult { return &MultiEditResult{} },
+	ToolGlob:         func() ToolResult { return &GlobResult{} },
+	ToolGrep:         func() ToolResult { return &GrepResult{} },
+	ToolWebFetch:     func() ToolResult { return &WebFetchResult{} },
+	ToolWebSearch:    func() ToolResult { return &WebSearchResult{} },
+	ToolTask:         func() ToolResult { return &TaskResult{} },
+	ToolTodoWrite:    func() ToolResult { return &TodoWriteResult{} },
+	ToolNotebookEdit: func() ToolResult { return &NotebookEditResult{} },
+	ToolExitPlanMode: func() ToolResult { return &ExitPlanModeResult{} },
+}
+
+// Decode returns the typed input of a built-in tool call, e.g. a *BashInput
+// for Bash. Other tools, such as MCP tools, return ErrUnknownTool.
+func (b *ToolUseBlock) Decode() (ToolInput, error) {
+	return DecodeToolInput(b.Name, b.Input)
+}
+
+// DecodeToolInput returns the typed input of a built-in tool, as passed to
+// permission callbacks and hooks. Other tools return ErrUnknownTool.
+func DecodeToolInput(toolName string, input map[string]any) (ToolInput, error) {
+	newInput, ok := toolInputs[toolName]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errors.ErrUnknownTool, toolName)
+	}
+
+	typed := newInput()
+	if err := decodeMap(input, typed); err != nil {
+		return nil, fmt.Errorf("decode %s input: %w", toolName, err)
+	}
+
+	return typed, nil
+}
+
+// DecodeToolResult returns the typed result of a built-in tool from a
+// UserMessage's ToolUseResult. The tool name is that of the ToolUseBlock the
+// result answers. Other tools return ErrUnknownTool.
+func DecodeToolResult(toolName string, result map[string]any) (ToolResult, error) {
+	newResult, ok := toolResults[toolName]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errors.ErrUnknownTool, toolName)
+	}
+
+	typed := newResult()
+	if err := decodeMap(result, typed); err != nil {
+		return nil, fmt.Errorf("decode %s result: %w", toolName, err)
+	}
+
+	return typed, nil
+}
+
+// DecodeToolUseResult returns the typed ToolUseResult of a message carrying
+// the result of the named built-in tool, or nil if it has none.
+func (m *UserMessage) DecodeToolUseResult(toolName string) (ToolResult, error) {
+	if m.ToolUseResult == nil {
+		return nil, nil //nolint:nilnil // a message without a tool result is not an error
+	}
+
+	return DecodeToolResult(toolName, m.ToolUseResult)
+}
+
+// EncodeToolInput converts a typed tool input back to the map form used for
+// a permission result's or hook's updated input.
+func EncodeToolInput(input ToolInput) (map[string]any, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s input: %w", input.ToolName(), err)
+	}
+
+	var out map[string]any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("encode %s input: %w", input.ToolName(), err)
+	}
+
+	return out, nil
+}
+
+// decodeMap decodes a JSON object held as a map into v.
+func decodeMap(m map[string]any, v any) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,115 @@
+package message
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/errors"
+)
+
+func decodeJSON(t *testing.T, s string) map[string]any {
+	t.Helper()
+
+	var m map[string]any
+	require.NoError(t, json.Unmarshal([]byte(s), &m))
+
+	return m
+}
+
+func TestDecodeToolInput(t *testing.T) {
+	block := &ToolUseBlock{Name: ToolEdit, Input: decodeJSON(t,
+		`{"file_path":"/src/a.go","old_string":"x","new_string":"y","replace_all":true}`)}
+
+	decoded, err := block.Decode()
+	require.NoError(t, err)
+
+	edit, ok := decoded.(*EditInput)
+	require.True(t, ok)
+	assert.Equal(t, EditInput{FilePath: "/src/a.go", OldString: "x", NewString: "y", ReplaceAll: true}, *edit)
+
+	decoded, err = DecodeToolInput(ToolGrep, decodeJSON(t, `{"pattern":"TODO","-i":true,"-C":2,"output_mode":"content"}`))
+	require.NoError(t, err)
+
+	grep, ok := decoded.(*GrepInput)
+	require.True(t, ok)
+	assert.True(t, grep.CaseInsensitive)
+	assert.Equal(t, 2, grep.Context)
+	assert.Equal(t, "content", grep.OutputMode)
+
+	decoded, err = DecodeToolInput(ToolTodoWrite, decodeJSON(t,
+		`{"todos":[{"content":"Fix it","status":"in_progress","activeForm":"Fixing it"}]}`))
+	require.NoError(t, err)
+
+	todos, ok := decoded.(*TodoWriteInput)
+	require.True(t, ok)
+	assert.Equal(t, []Todo{{Content: "Fix it", Status: "in_progress", ActiveForm: "Fixing it"}}, todos.Todos)
+
+	for name := range toolInputs {
+		decoded, err := DecodeToolInput(name, map[string]any{})
+		require.NoError(t, err, name)
+		assert.Equal(t, name, decoded.ToolName())
+	}
+
+	_, err = DecodeToolInput("mcp__db__query", map[string]any{})
+	require.ErrorIs(t, err, errors.ErrUnknownTool)
+
+	_, err = DecodeToolInput(ToolBash, map[string]any{"command": 42})
+	require.Error(t, err)
+}
+
+func TestEncodeToolInput(t *testing.T) {
+	out, err := EncodeToolInput(&BashInput{Command: "ls", Timeout: 1000})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"command": "ls", "timeout": float64(1000)}, out)
+}
+
+func TestDecodeToolResult(t *testing.T) {
+	msg := &UserMessage{ToolUseResult: decodeJSON(t, `{
+		"filePath":"/src/a.go","oldString":"x","newString":"y","originalFile":"x\n",
+		"structuredPatch":[{"oldStart":1,"oldLines":1,"newStart":1,"newLines":1,"lines":["-x","+y"]}],
+		"userModified":false,"replaceAll":false}`)}
+
+	decoded, err := msg.DecodeToolUseResult(ToolEdit)
+	require.NoError(t, err)
+
+	edit, ok := decoded.(*EditResult)
+	require.True(t, ok)
+	assert.Equal(t, "/src/a.go", edit.FilePath)
+	require.Len(t, edit.StructuredPatch, 1)
+	assert.Equal(t, []string{"-x", "+y"}, edit.StructuredPatch[0].Lines)
+
+	decoded, err = DecodeToolResult(ToolRead, decodeJSON(t,
+		`{"type":"text","file":{"filePath":"/a","content":"hi","numLines":1,"startLine":1,"totalLines":1}}`))
+	require.NoError(t, err)
+
+	read, ok := decoded.(*ReadResult)
+	require.True(t, ok)
+	require.NotNil(t, read.File)
+	assert.Equal(t, "hi", read.File.Content)
+
+	decoded, err = DecodeToolResult(ToolTask, decodeJSON(t,
+		`{"status":"completed","agentId":"ag1","content":[{"type":"text","text":"done"}],"totalTokens":42}`))
+	require.NoError(t, err)
+
+	task, ok := decoded.(*TaskResult)
+	require.True(t, ok)
+	assert.Equal(t, "ag1", task.AgentID)
+	require.Len(t, task.Content, 1)
+	assert.Equal(t, "done", task.Content[0].Text)
+
+	for name := range toolResults {
+		decoded, err := DecodeToolResult(name, map[string]any{})
+		require.NoError(t, err, name)
+		assert.Equal(t, name, decoded.ToolName())
+	}
+
+	none, err := (&UserMessage{}).DecodeToolUseResult(ToolBash)
+	require.NoError(t, err)
+	assert.Nil(t, none)
+
+	_, err = DecodeToolResult("mcp__db__query", map[string]any{})
+	require.ErrorIs(t, err, errors.ErrUnknownTool)
+}
@@ -39,8 +39,16 @@ type Model struct {
 	ID string
 	// Name is the human-readable display name.
 	Name string
+	// BedrockID is the Amazon Bedrock model ID, without a cross-region
+	// inference profile prefix.
+	BedrockID string
+	// VertexID is the Google Vertex AI model ID.
+	VertexID string
 	// Aliases are shorthand names accepted by the CLI (e.g. "opus").
 	Aliases []string
+	// SnapshotIDs are dated API identifiers that do not start with ID
+	// (e.g. "claude-opus-4-20250514" for "claude-opus-4-0").
+	SnapshotIDs []string
 	// CostTier is the relative cost tier for this model.
 	CostTier CostTier
 	// Capabilities lists what the model supports.
@@ -49,6 +57,9 @@ type Model struct {
 	ContextWindow int
 	// MaxOutputTokens is the maximum number of output tokens.
 	MaxOutputTokens int
+	// Pricing is the list price as of PricingVersion. Use PriceFor to honor
+	// overrides loaded with SetPriceTable.
+	Pricing Pricing
 }
 
 // HasCapability reports whether the model supports the given capability.
@@ -78,7 +89,9 @@ func All() []Model {
 // ByID looks up a model by its identifier. It checks in order:
 //  1. Exact match on ID
 //  2. Alias match
-//  3. Prefix match (for dated model IDs like "claude-opus-4-6-20260205")
+//  3. Provider ID match (Bedrock IDs with or without an inference profile
+//     prefix, and Vertex IDs)
+//  4. Prefix match (for dated model IDs like "claude-opus-4-6-20260205")
 //
 // Returns nil if no model is found.
 func ByID(id string) *Model {
@@ -91,9 +104,23 @@ func ByID(id string) *Model {
 		}
 	}
 
-	// Alias match.
+	// Alias or snapshot match.
 	for i := range registry {
-		if slices.Contains(registry[i].Aliases, id) {
+		if slices.Contains(registry[i].Aliases, id) || slices.Contains(registry[i].SnapshotIDs, id) {
+			m := registry[i]
+
+			return &m
+		}
+	}
+
+	// Provider ID match.
+	bedrockID := id
+	if i := strings.Index(id, ".anthropic."); i >= 0 {
+		bedrockID = id[i+1:]
+	}
+
+	for i := range registry {
+		if registry[i].BedrockID == bedrockID || registry[i].VertexID == id {
 			m := registry[i]
 
 			return &m
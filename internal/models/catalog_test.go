@@ -70,6 +70,31 @@ func TestByID(t *testing.T) {
 			input:  "claude-opus-4-6-20260205",
 			wantID: "claude-opus-4-6",
 		},
+		{
+			name:   "snapshot ID without the catalog prefix",
+			input:  "claude-sonnet-4-20250514",
+			wantID: "claude-sonnet-4-0",
+		},
+		{
+			name:   "older model dated ID",
+			input:  "claude-3-5-haiku-20241022",
+			wantID: "claude-3-5-haiku",
+		},
+		{
+			name:   "bedrock ID",
+			input:  "anthropic.claude-sonnet-4-5-20250929-v1:0",
+			wantID: "claude-sonnet-4-5",
+		},
+		{
+			name:   "bedrock inference profile",
+			input:  "global.anthropic.claude-haiku-4-5-20251001-v1:0",
+			wantID: "claude-haiku-4-5",
+		},
+		{
+			name:   "vertex ID",
+			input:  "claude-opus-4@20250514",
+			wantID: "claude-opus-4-0",
+		},
 		{
 			name:    "not found",
 			input:   "gpt-4",
@@ -0,0 +1,170 @@
+package models
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/message"
+)
+
+// CostEntry is the estimated cost of one API message.
+type CostEntry struct {
+	// Turn counts result messages seen before this message, starting at 0.
+	Turn int
+	// MessageID is the API message ID.
+	MessageID string
+	// Model is the model that produced the message.
+	Model string
+	// ParentToolUseID is the ID of the Task tool call that ran the subagent
+	// producing this message, or "" for the main agent.
+	ParentToolUseID string
+	Usage           message.Usage
+	Cost            Cost
+	// Priced is false when the price table has no price for Model; Cost is
+	// then zero.
+	Priced bool
+}
+
+// CostTracker estimates cost client-side from assistant message usage, so
+// spend can be attributed to models, turns and subagents. Feed it every
+// message with Observe. Assistant messages sharing an API message ID are
+// counted once.
+//
+// Estimates use list prices and may differ slightly from the CLI's
+// TotalCostUSD. Usage of models without a price is not counted as free:
+// check Unpriced to find it.
+type CostTracker struct {
+	table *PriceTable
+
+	mu      sync.Mutex
+	seen    map[string]struct{}
+	turn    int
+	entries []CostEntry
+}
+
+// NewCostTracker creates a CostTracker pricing usage with table, or with the
+// current price table if table is nil.
+func NewCostTracker(table *PriceTable) *CostTracker {
+	return &CostTracker{table: table, seen: make(map[string]struct{}, 16)}
+}
+
+// Observe records the usage of an assistant message; a result message ends
+// the current turn. Other messages are ignored.
+func (t *CostTracker) Observe(msg message.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch m := msg.(type) {
+	case *message.ResultMessage:
+		t.turn++
+	case *message.AssistantMessage:
+		if m.Usage == nil {
+			return
+		}
+
+		if m.ID != "" {
+			if _, dup := t.seen[m.ID]; dup {
+				return
+			}
+
+			t.seen[m.ID] = struct{}{}
+		}
+
+		table := t.table
+		if table == nil {
+			table = CurrentPriceTable()
+		}
+
+		entry := CostEntry{
+			Turn:      t.turn,
+			MessageID: m.ID,
+			Model:     m.Model,
+			Usage:     *m.Usage,
+		}
+
+		if m.ParentToolUseID != nil {
+			entry.ParentToolUseID = *m.ParentToolUseID
+		}
+
+		if p, ok := table.Lookup(m.Model); ok {
+			entry.Cost, entry.Priced = p.Cost(*m.Usage), true
+		}
+
+		t.entries = append(t.entries, entry)
+	}
+}
+
+// Entries returns every recorded entry in arrival order.
+func (t *CostTracker) Entries() []CostEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return slices.Clone(t.entries)
+}
+
+// Total returns the estimated cost of all recorded messages.
+func (t *CostTracker) Total() Cost {
+	var total Cost
+
+	for _, e := range t.Entries() {
+		total = total.Add(e.Cost)
+	}
+
+	return total
+}
+
+// Unpriced returns the models of recorded messages the price table has no
+// price for, sorted. Their usage is missing from every cost total.
+func (t *CostTracker) Unpriced() []string {
+	var out []string
+
+	for _, e := range t.Entries() {
+		if !e.Priced && !slices.Contains(out, e.Model) {
+			out = append(out, e.Model)
+		}
+	}
+
+	slices.Sort(out)
+
+	return out
+}
+
+// ByModel returns the estimated cost per model.
+func (t *CostTracker) ByModel() map[string]Cost {
+	return t.group(func(e CostEntry) string { return e.Model })
+}
+
+// BySubagent returns the estimated cost per subagent, keyed by the ID of the
+// Task tool call that ran it. The main agent's cost is keyed by "".
+func (t *CostTracker) BySubagent() map[string]Cost {
+	return t.group(func(e CostEntry) string { return e.ParentToolUseID })
+}
+
+// ByTurn returns the estimated cost of each turn, indexed by turn number.
+// The current turn is included once it has recorded usage.
+func (t *CostTracker) ByTurn() []Cost {
+	entries := t.Entries()
+
+	var turns []Cost
+
+	for _, e := range entries {
+		for len(turns) <= e.Turn {
+			turns = append(turns, Cost{})
+		}
+
+		turns[e.Turn] = turns[e.Turn].Add(e.Cost)
+	}
+
+	return turns
+}
+
+func (t *CostTracker) group(key func(CostEntry) string) map[string]Cost {
+	out := make(map[string]Cost)
+
+	for _, e := range t.Entries() {
+		k := key(e)
+		out[k] = out[k].Add(e.Cost)
+	}
+
+	return out
+}
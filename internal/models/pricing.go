@@ -0,0 +1,187 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/message"
+)
+
+// PricingVersion identifies the built-in price list.
+const PricingVersion = "2026-02"
+
+// ErrNoPricing is returned when a model has no price in the price table.
+var ErrNoPricing = errors.New("no pricing for model")
+
+// Pricing holds per-million-token prices in USD.
+//
+//nolint:tagliatelle // price files use snake_case
+type Pricing struct {
+	Input      float64 `json:"input"`
+	Output     float64 `json:"output"`
+	CacheWrite float64 `json:"cache_write"`
+	CacheRead  float64 `json:"cache_read"`
+}
+
+// Cost is an itemized cost in USD.
+type Cost struct {
+	Input      float64
+	Output     float64
+	CacheWrite float64
+	CacheRead  float64
+}
+
+// Total returns the sum of all cost items.
+func (c Cost) Total() float64 {
+	return c.Input + c.Output + c.CacheWrite + c.CacheRead
+}
+
+// Add returns the item-wise sum of c and o.
+func (c Cost) Add(o Cost) Cost {
+	return Cost{
+		Input:      c.Input + o.Input,
+		Output:     c.Output + o.Output,
+		CacheWrite: c.CacheWrite + o.CacheWrite,
+		CacheRead:  c.CacheRead + o.CacheRead,
+	}
+}
+
+// Cost prices usage.
+func (p Pricing) Cost(usage message.Usage) Cost {
+	const perToken = 1e-6
+
+	return Cost{
+		Input:      float64(usage.InputTokens) * p.Input * perToken,
+		Output:     float64(usage.OutputTokens) * p.Output * perToken,
+		CacheWrite: float64(usage.CacheCreationInputTokens) * p.CacheWrite * perToken,
+		CacheRead:  float64(usage.CacheReadInputTokens) * p.CacheRead * perToken,
+	}
+}
+
+// PriceTable maps model IDs to prices. It is the JSON format accepted by
+// LoadPriceTable:
+//
+//	{
+//	  "version": "2026-03-internal",
+//	  "models": {
+//	    "claude-sonnet-4-6": {"input": 3, "output": 15, "cache_write": 3.75, "cache_read": 0.3}
+//	  }
+//	}
+type PriceTable struct {
+	Version string             `json:"version"`
+	Models  map[string]Pricing `json:"models"`
+}
+
+// DefaultPriceTable returns the built-in price table.
+func DefaultPriceTable() *PriceTable {
+	t := &PriceTable{Version: PricingVersion, Models: make(map[string]Pricing, len(registry))}
+
+	for _, m := range registry {
+		t.Models[m.ID] = m.Pricing
+	}
+
+	return t
+}
+
+// LoadPriceTable reads a JSON price table from path and merges it over the
+// built-in table: listed models are replaced and others keep their built-in
+// price. The file's version, if set, replaces the built-in version.
+func LoadPriceTable(path string) (*PriceTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read price table: %w", err)
+	}
+
+	var override PriceTable
+	if err := json.Unmarshal(data, &override); err != nil {
+		return nil, fmt.Errorf("parse price table %s: %w", path, err)
+	}
+
+	t := DefaultPriceTable()
+	maps.Copy(t.Models, override.Models)
+
+	if override.Version != "" {
+		t.Version = override.Version
+	}
+
+	return t, nil
+}
+
+// Lookup returns the price for modelID. It checks, in order, an exact match,
+// the catalog ID of an alias or dated model ID, and the longest table entry
+// that prefixes modelID.
+func (t *PriceTable) Lookup(modelID string) (Pricing, bool) {
+	if p, ok := t.Models[modelID]; ok {
+		return p, true
+	}
+
+	if m := ByID(modelID); m != nil {
+		if p, ok := t.Models[m.ID]; ok {
+			return p, true
+		}
+	}
+
+	var (
+		best  string
+		found bool
+	)
+
+	for id := range t.Models {
+		if strings.HasPrefix(modelID, id) && len(id) > len(best) {
+			best, found = id, true
+		}
+	}
+
+	return t.Models[best], found
+}
+
+// EstimateCost prices usage of modelID. It returns ErrNoPricing if the table
+// has no price for the model.
+func (t *PriceTable) EstimateCost(modelID string, usage message.Usage) (Cost, error) {
+	p, ok := t.Lookup(modelID)
+	if !ok {
+		return Cost{}, fmt.Errorf("%w %q in price table %s", ErrNoPricing, modelID, t.Version)
+	}
+
+	return p.Cost(usage), nil
+}
+
+var (
+	priceTableMu sync.RWMutex
+	priceTable   = DefaultPriceTable()
+)
+
+// SetPriceTable replaces the price table used by EstimateCost and PriceFor.
+// A nil table restores the built-in one.
+func SetPriceTable(t *PriceTable) {
+	if t == nil {
+		t = DefaultPriceTable()
+	}
+
+	priceTableMu.Lock()
+	priceTable = t
+	priceTableMu.Unlock()
+}
+
+// CurrentPriceTable returns the price table used by EstimateCost.
+func CurrentPriceTable() *PriceTable {
+	priceTableMu.RLock()
+	defer priceTableMu.RUnlock()
+
+	return priceTable
+}
+
+// PriceFor returns the current price of modelID.
+func PriceFor(modelID string) (Pricing, bool) {
+	return CurrentPriceTable().Lookup(modelID)
+}
+
+// EstimateCost prices usage of modelID with the current price table.
+func EstimateCost(modelID string, usage message.Usage) (Cost, error) {
+	return CurrentPriceTable().EstimateCost(modelID, usage)
+}
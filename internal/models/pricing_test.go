@@ -0,0 +1,114 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/message"
+)
+
+func TestRegistryPricing(t *testing.T) {
+	for _, m := range All() {
+		assert.Positive(t, m.Pricing.Input, "model %s must have an input price", m.ID)
+		assert.Greater(t, m.Pricing.Output, m.Pricing.Input, "model %s output must cost more than input", m.ID)
+		assert.Less(t, m.Pricing.CacheRead, m.Pricing.Input, "model %s cache reads must be discounted", m.ID)
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	usage := message.Usage{
+		InputTokens:              1_000_000,
+		OutputTokens:             100_000,
+		CacheCreationInputTokens: 200_000,
+		CacheReadInputTokens:     2_000_000,
+	}
+
+	for _, id := range []string{"claude-sonnet-4-6", "sonnet", "claude-sonnet-4-6-20260217", "claude-sonnet-4-20250514"} {
+		cost, err := EstimateCost(id, usage)
+		require.NoError(t, err, id)
+		assert.InDelta(t, 3.0, cost.Input, 1e-9)
+		assert.InDelta(t, 1.5, cost.Output, 1e-9)
+		assert.InDelta(t, 0.75, cost.CacheWrite, 1e-9)
+		assert.InDelta(t, 0.6, cost.CacheRead, 1e-9)
+		assert.InDelta(t, 5.85, cost.Total(), 1e-9)
+	}
+
+	_, err := EstimateCost("gpt-5", usage)
+	require.ErrorIs(t, err, ErrNoPricing)
+}
+
+func TestLoadPriceTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"version": "negotiated-2026",
+		"models": {
+			"claude-sonnet-4-6": {"input": 2, "output": 10, "cache_write": 2.5, "cache_read": 0.2},
+			"claude-custom": {"input": 1, "output": 1}
+		}
+	}`), 0o600))
+
+	table, err := LoadPriceTable(path)
+	require.NoError(t, err)
+	require.Equal(t, "negotiated-2026", table.Version)
+
+	p, ok := table.Lookup("sonnet")
+	require.True(t, ok)
+	assert.InDelta(t, 2.0, p.Input, 0)
+
+	p, ok = table.Lookup("claude-custom-v2")
+	require.True(t, ok, "unknown IDs fall back to the longest prefix in the table")
+	assert.InDelta(t, 1.0, p.Output, 0)
+
+	p, ok = table.Lookup("claude-haiku-4-5")
+	require.True(t, ok, "models missing from the file keep built-in prices")
+	assert.Equal(t, haiku4_5Pricing, p)
+
+	SetPriceTable(table)
+	t.Cleanup(func() { SetPriceTable(nil) })
+
+	p, ok = PriceFor("claude-sonnet-4-6")
+	require.True(t, ok)
+	assert.InDelta(t, 2.0, p.Input, 0)
+
+	_, err = LoadPriceTable(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
+
+func TestCostTracker(t *testing.T) {
+	tracker := NewCostTracker(DefaultPriceTable())
+	usage := &message.Usage{InputTokens: 1_000_000}
+
+	tracker.Observe(&message.AssistantMessage{ID: "msg_1", Model: "claude-opus-4-6", Usage: usage})
+	tracker.Observe(&message.AssistantMessage{ID: "msg_1", Model: "claude-opus-4-6", Usage: usage})
+	tracker.Observe(&message.AssistantMessage{
+		ID: "msg_2", Model: "claude-haiku-4-5", Usage: usage, ParentToolUseID: new("toolu_task"),
+	})
+	tracker.Observe(&message.ResultMessage{})
+	tracker.Observe(&message.AssistantMessage{ID: "msg_3", Model: "claude-opus-4-6", Usage: usage})
+	tracker.Observe(&message.AssistantMessage{ID: "msg_4", Model: "mystery", Usage: usage})
+	tracker.Observe(&message.AssistantMessage{ID: "msg_5", Model: "claude-opus-4-6"})
+
+	entries := tracker.Entries()
+	require.Len(t, entries, 4, "duplicate IDs and messages without usage are skipped")
+	assert.False(t, entries[3].Priced)
+	assert.Equal(t, []string{"mystery"}, tracker.Unpriced())
+
+	assert.InDelta(t, 11.0, tracker.Total().Total(), 1e-9)
+
+	byModel := tracker.ByModel()
+	assert.InDelta(t, 10.0, byModel["claude-opus-4-6"].Total(), 1e-9)
+	assert.InDelta(t, 1.0, byModel["claude-haiku-4-5"].Total(), 1e-9)
+
+	bySubagent := tracker.BySubagent()
+	assert.InDelta(t, 10.0, bySubagent[""].Total(), 1e-9)
+	assert.InDelta(t, 1.0, bySubagent["toolu_task"].Total(), 1e-9)
+
+	byTurn := tracker.ByTurn()
+	require.Len(t, byTurn, 2)
+	assert.InDelta(t, 6.0, byTurn[0].Total(), 1e-9)
+	assert.InDelta(t, 5.0, byTurn[1].Total(), 1e-9)
+}
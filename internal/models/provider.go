@@ -0,0 +1,47 @@
+package models
+
+import "strings"
+
+// Provider identifies a platform serving Claude models.
+type Provider string
+
+const (
+	// ProviderAnthropic is the Anthropic API.
+	ProviderAnthropic Provider = "anthropic"
+	// ProviderBedrock is Amazon Bedrock.
+	ProviderBedrock Provider = "bedrock"
+	// ProviderVertex is Google Vertex AI.
+	ProviderVertex Provider = "vertex"
+)
+
+// IDFor returns the model's ID on provider, or "" if the catalog does not
+// know the model's ID there.
+func (m Model) IDFor(provider Provider) string {
+	switch provider {
+	case ProviderAnthropic:
+		return m.ID
+	case ProviderBedrock:
+		return m.BedrockID
+	case ProviderVertex:
+		return m.VertexID
+	default:
+		return ""
+	}
+}
+
+// ProviderID maps a catalog model ID or alias to its ID on provider.
+// Models the catalog does not know, and IDs already specific to provider
+// (including Bedrock inference profiles), are returned unchanged.
+func ProviderID(provider Provider, id string) string {
+	m := ByID(id)
+	if m == nil {
+		return id
+	}
+
+	providerID := m.IDFor(provider)
+	if providerID == "" || id == providerID || strings.HasSuffix(id, "."+providerID) {
+		return id
+	}
+
+	return providerID
+}
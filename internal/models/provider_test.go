@@ -0,0 +1,39 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderIDs(t *testing.T) {
+	for _, m := range registry {
+		assert.NotEmpty(t, m.BedrockID, "%s has no Bedrock ID", m.ID)
+		assert.NotEmpty(t, m.VertexID, "%s has no Vertex ID", m.ID)
+	}
+}
+
+func TestProviderID(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider Provider
+		id       string
+		want     string
+	}{
+		{name: "anthropic alias", provider: ProviderAnthropic, id: "opus", want: "claude-opus-4-6"},
+		{name: "bedrock", provider: ProviderBedrock, id: "claude-sonnet-4-5", want: "anthropic.claude-sonnet-4-5-20250929-v1:0"},
+		{name: "bedrock alias", provider: ProviderBedrock, id: "haiku", want: "anthropic.claude-haiku-4-5-20251001-v1:0"},
+		{name: "vertex", provider: ProviderVertex, id: "claude-opus-4-1", want: "claude-opus-4-1@20250805"},
+		{name: "vertex dated ID", provider: ProviderVertex, id: "claude-sonnet-4-0-20250514", want: "claude-sonnet-4@20250514"},
+		{name: "vertex from bedrock ID", provider: ProviderVertex, id: "anthropic.claude-opus-4-20250514-v1:0", want: "claude-opus-4@20250514"},
+		{name: "inference profile passes through", provider: ProviderBedrock, id: "eu.anthropic.claude-opus-4-1-20250805-v1:0", want: "eu.anthropic.claude-opus-4-1-20250805-v1:0"},
+		{name: "unknown model passes through", provider: ProviderBedrock, id: "custom-model", want: "custom-model"},
+		{name: "unknown provider", provider: Provider("azure"), id: "sonnet", want: "sonnet"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ProviderID(tt.provider, tt.id))
+		})
+	}
+}
@@ -8,74 +8,121 @@ var allCapabilities = []Capability{
 	CapStructuredOutput,
 }
 
+// Per-million-token prices in USD as of PricingVersion. Cache writes are
+// priced at the 5-minute TTL rate.
+var (
+	opus4_5Pricing  = Pricing{Input: 5, Output: 25, CacheWrite: 6.25, CacheRead: 0.50}
+	opus4Pricing    = Pricing{Input: 15, Output: 75, CacheWrite: 18.75, CacheRead: 1.50}
+	sonnetPricing   = Pricing{Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.30}
+	haiku4_5Pricing = Pricing{Input: 1, Output: 5, CacheWrite: 1.25, CacheRead: 0.10}
+	haiku3_5Pricing = Pricing{Input: 0.80, Output: 4, CacheWrite: 1, CacheRead: 0.08}
+)
+
 // registry is the internal list of all known Claude models.
 // Only the latest model per tier gets the short alias.
 var registry = []Model{
 	{
 		ID:              "claude-opus-4-6",
+		BedrockID:       "anthropic.claude-opus-4-6-v1",
+		VertexID:        "claude-opus-4-6",
 		Name:            "Claude Opus 4.6",
 		Aliases:         []string{"opus"},
 		CostTier:        CostTierHigh,
 		Capabilities:    allCapabilities,
 		ContextWindow:   200_000,
+		Pricing:         opus4_5Pricing,
 		MaxOutputTokens: 128_000,
 	},
 	{
 		ID:              "claude-sonnet-4-6",
+		BedrockID:       "anthropic.claude-sonnet-4-6",
+		VertexID:        "claude-sonnet-4-6",
 		Name:            "Claude Sonnet 4.6",
 		Aliases:         []string{"sonnet"},
 		CostTier:        CostTierMedium,
 		Capabilities:    allCapabilities,
 		ContextWindow:   200_000,
+		Pricing:         sonnetPricing,
 		MaxOutputTokens: 64_000,
 	},
 	{
 		ID:              "claude-haiku-4-5",
+		BedrockID:       "anthropic.claude-haiku-4-5-20251001-v1:0",
+		VertexID:        "claude-haiku-4-5@20251001",
 		Name:            "Claude Haiku 4.5",
 		Aliases:         []string{"haiku"},
 		CostTier:        CostTierLow,
 		Capabilities:    allCapabilities,
 		ContextWindow:   200_000,
+		Pricing:         haiku4_5Pricing,
 		MaxOutputTokens: 64_000,
 	},
 	{
 		ID:              "claude-opus-4-5",
+		BedrockID:       "anthropic.claude-opus-4-5-20251101-v1:0",
+		VertexID:        "claude-opus-4-5@20251101",
 		Name:            "Claude Opus 4.5",
 		CostTier:        CostTierHigh,
 		Capabilities:    allCapabilities,
 		ContextWindow:   200_000,
+		Pricing:         opus4_5Pricing,
 		MaxOutputTokens: 64_000,
 	},
 	{
 		ID:              "claude-sonnet-4-5",
+		BedrockID:       "anthropic.claude-sonnet-4-5-20250929-v1:0",
+		VertexID:        "claude-sonnet-4-5@20250929",
 		Name:            "Claude Sonnet 4.5",
 		CostTier:        CostTierMedium,
 		Capabilities:    allCapabilities,
 		ContextWindow:   200_000,
+		Pricing:         sonnetPricing,
 		MaxOutputTokens: 64_000,
 	},
 	{
 		ID:              "claude-opus-4-1",
+		BedrockID:       "anthropic.claude-opus-4-1-20250805-v1:0",
+		VertexID:        "claude-opus-4-1@20250805",
 		Name:            "Claude Opus 4.1",
 		CostTier:        CostTierHigh,
 		Capabilities:    allCapabilities,
 		ContextWindow:   200_000,
+		Pricing:         opus4Pricing,
 		MaxOutputTokens: 32_000,
 	},
 	{
 		ID:              "claude-opus-4-0",
+		BedrockID:       "anthropic.claude-opus-4-20250514-v1:0",
+		VertexID:        "claude-opus-4@20250514",
+		SnapshotIDs:     []string{"claude-opus-4-20250514"},
 		Name:            "Claude Opus 4",
 		CostTier:        CostTierHigh,
 		Capabilities:    allCapabilities,
 		ContextWindow:   200_000,
+		Pricing:         opus4Pricing,
 		MaxOutputTokens: 32_000,
 	},
 	{
 		ID:              "claude-sonnet-4-0",
+		BedrockID:       "anthropic.claude-sonnet-4-20250514-v1:0",
+		VertexID:        "claude-sonnet-4@20250514",
+		SnapshotIDs:     []string{"claude-sonnet-4-20250514"},
 		Name:            "Claude Sonnet 4",
 		CostTier:        CostTierMedium,
 		Capabilities:    allCapabilities,
 		ContextWindow:   200_000,
+		Pricing:         sonnetPricing,
 		MaxOutputTokens: 64_000,
 	},
+	{
+		ID:              "claude-3-5-haiku",
+		BedrockID:       "anthropic.claude-3-5-haiku-20241022-v1:0",
+		VertexID:        "claude-3-5-haiku@20241022",
+		Name:            "Claude Haiku 3.5",
+		CostTier:        CostTierLow,
+		Capabilities:    []Capability{CapVision, CapToolUse},
+		ContextWindow:   200_000,
+		Pricing:         haiku3_5Pricing,
+		MaxOutputTokens: 8_192,
+	},
 }
@@ -0,0 +1,657 @@
+package permission
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scope controls how long a cached permission decision is remembered.
+type Scope string
+
+const (
+	// ScopeOnce applies a decision to the current request only; it is not cached.
+	ScopeOnce Scope = "once"
+	// ScopeTurn remembers a decision until the current turn ends (the next result message).
+	ScopeTurn Scope = "turn"
+	// ScopeSession remembers a decision until the session ends.
+	ScopeSession Scope = "session"
+	// ScopeForever remembers a decision across sessions using the cache's Store.
+	ScopeForever Scope = "forever"
+)
+
+// KeyFunc normalizes a tool input into a cache key.
+//
+// Keys are matched by prefix: an entry with key "/src/" covers a lookup
+// with key "/src/pkg/", and an entry with key "git log" covers "git log main".
+// Keys ending in ExactKeySuffix match only themselves, in either role.
+// Return an empty string when the input must not be cached; empty keys are
+// never stored or matched.
+type KeyFunc func(toolName string, input map[string]any) string
+
+// ExactKeySuffix marks a key that matches only an identical key, never as or
+// against a prefix. DefaultKey uses it for whole Bash command lines.
+const ExactKeySuffix = "$"
+
+// errEmptyKey is returned when an explicit cache key is empty.
+var errEmptyKey = errors.New("permission cache key is empty")
+
+// CacheEntry is a remembered permission decision.
+type CacheEntry struct {
+	ToolName  string    `json:"toolName"`
+	Key       string    `json:"key"`
+	Behavior  Behavior  `json:"behavior"`
+	Message   string    `json:"message,omitempty"`
+	Scope     Scope     `json:"scope"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt,omitzero"`
+
+	// session identifies the CacheSession that recorded a turn- or
+	// session-scoped entry; zero for entries recorded on the Cache itself.
+	session uint64
+}
+
+// expired reports whether the entry's TTL has elapsed at now.
+func (e *CacheEntry) expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt)
+}
+
+// Store persists ScopeForever cache entries.
+type Store interface {
+	// Load returns all persisted entries.
+	Load() ([]CacheEntry, error)
+	// Save replaces all persisted entries.
+	Save(entries []CacheEntry) error
+}
+
+// CacheOptions configures a Cache.
+type CacheOptions struct {
+	// Scope is the default scope for decisions returned by the wrapped callback.
+	// Defaults to ScopeSession.
+	Scope Scope
+
+	// TTL bounds how long an entry is remembered. Zero means no expiry
+	// beyond the entry's scope.
+	TTL time.Duration
+
+	// CacheDenials remembers deny decisions as well as allow decisions.
+	// Denials that interrupt the session are never cached.
+	CacheDenials bool
+
+	// KeyFunc normalizes tool inputs into cache keys. Defaults to DefaultKey.
+	KeyFunc KeyFunc
+
+	// Store persists ScopeForever entries. If nil, forever entries live
+	// only as long as the Cache.
+	Store Store
+}
+
+// Remembered wraps a Result with an explicit cache scope.
+//
+// Return it from a callback wrapped by a Cache to override the default scope,
+// for example when the user picks "always allow" (ScopeForever) or
+// "allow once" (ScopeOnce). Without a Cache, the wrapped Result is used as-is.
+type Remembered struct {
+	Result Result
+	Scope  Scope
+	TTL    time.Duration // overrides CacheOptions.TTL when non-zero
+}
+
+// GetBehavior implements Result.
+func (r *Remembered) GetBehavior() string { return r.Result.GetBehavior() }
+
+// Remember wraps result so a Cache stores it with the given scope.
+func Remember(result Result, scope Scope) *Remembered {
+	return &Remembered{Result: result, Scope: scope}
+}
+
+// Unwrap returns the Result wrapped by a Remembered, or result itself.
+func Unwrap(result Result) Result {
+	if r, ok := result.(*Remembered); ok {
+		return r.Result
+	}
+
+	return result
+}
+
+// Cache remembers permission decisions by tool name and normalized input key.
+//
+// A Cache wraps a Callback: lookups that hit a remembered decision skip the
+// callback entirely. Turn- and session-scoped entries are cleared by EndTurn
+// and EndSession. When the cache is passed via Options.PermissionCache, the
+// SDK gives every query or client its own CacheSession, so sessions sharing
+// a Cache share forever-scoped decisions but not each other's turn- or
+// session-scoped ones. Cache is safe for concurrent use.
+type Cache struct {
+	opts CacheOptions
+	now  func() time.Time
+
+	mu          sync.Mutex
+	entries     []*CacheEntry
+	lastSession uint64
+}
+
+// CacheSession is a view of a Cache for a single session.
+//
+// Turn- and session-scoped decisions remembered through a CacheSession are
+// visible only to it and are cleared by its EndTurn and EndSession. Forever
+// entries and entries recorded on the Cache itself are shared.
+type CacheSession struct {
+	cache *Cache
+	id    uint64
+}
+
+// NewCache creates a Cache, loading persisted entries from opts.Store if set.
+func NewCache(opts *CacheOptions) (*Cache, error) {
+	c := &Cache{now: time.Now}
+
+	if opts != nil {
+		c.opts = *opts
+	}
+
+	if c.opts.Scope == "" {
+		c.opts.Scope = ScopeSession
+	}
+
+	if c.opts.KeyFunc == nil {
+		c.opts.KeyFunc = DefaultKey
+	}
+
+	if c.opts.Store != nil {
+		persisted, err := c.opts.Store.Load()
+		if err != nil {
+			return nil, fmt.Errorf("load permission cache: %w", err)
+		}
+
+		now := c.now()
+
+		for i := range persisted {
+			entry := persisted[i]
+			if entry.expired(now) {
+				continue
+			}
+
+			entry.Scope = ScopeForever
+			c.entries = append(c.entries, &entry)
+		}
+	}
+
+	return c, nil
+}
+
+// Session returns a new CacheSession backed by c. It returns nil if c is nil.
+func (c *Cache) Session() *CacheSession {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lastSession++
+
+	return &CacheSession{cache: c, id: c.lastSession}
+}
+
+// Wrap returns a Callback that consults the cache before invoking callback
+// and remembers the decisions it returns.
+//
+// Cached allow decisions do not replay UpdatedInput or UpdatedPermissions,
+// since those are specific to the original request.
+func (c *Cache) Wrap(callback Callback) Callback {
+	return c.wrap(0, callback)
+}
+
+// Wrap is like Cache.Wrap, but turn- and session-scoped decisions are
+// remembered for this session only. It returns callback unchanged if s is nil.
+func (s *CacheSession) Wrap(callback Callback) Callback {
+	if s == nil {
+		return callback
+	}
+
+	return s.cache.wrap(s.id, callback)
+}
+
+// Lookup returns the remembered decision for a tool use visible to this session.
+func (s *CacheSession) Lookup(toolName string, input map[string]any) (Result, bool) {
+	return s.cache.lookup(s.id, toolName, input)
+}
+
+// EndTurn drops turn-scoped entries recorded by this session.
+func (s *CacheSession) EndTurn() {
+	if s == nil {
+		return
+	}
+
+	_, _ = s.cache.remove(func(e *CacheEntry) bool {
+		return e.session == s.id && e.Scope == ScopeTurn
+	})
+}
+
+// EndSession drops turn- and session-scoped entries recorded by this session.
+func (s *CacheSession) EndSession() {
+	if s == nil {
+		return
+	}
+
+	_, _ = s.cache.remove(func(e *CacheEntry) bool {
+		return e.session == s.id && (e.Scope == ScopeTurn || e.Scope == ScopeSession)
+	})
+}
+
+// wrap implements Wrap for the given session.
+func (c *Cache) wrap(session uint64, callback Callback) Callback {
+	return func(
+		ctx context.Context,
+		toolName string,
+		input map[string]any,
+		permCtx *Context,
+	) (Result, error) {
+		if cached, ok := c.lookup(session, toolName, input); ok {
+			return cached, nil
+		}
+
+		if callback == nil {
+			return &ResultAllow{Behavior: "allow"}, nil
+		}
+
+		result, err := callback(ctx, toolName, input, permCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		scope, ttl, explicit := c.opts.Scope, c.opts.TTL, false
+
+		if r, ok := result.(*Remembered); ok {
+			explicit = true
+			scope = r.Scope
+
+			if r.TTL > 0 {
+				ttl = r.TTL
+			}
+
+			result = r.Result
+		}
+
+		if deny, ok := result.(*ResultDeny); ok {
+			if deny.Interrupt || (!c.opts.CacheDenials && !explicit) {
+				return result, nil
+			}
+		}
+
+		key := c.opts.KeyFunc(toolName, input)
+		if key == "" {
+			return result, nil
+		}
+
+		if err := c.store(session, toolName, key, result, scope, ttl); err != nil {
+			return nil, err
+		}
+
+		return result, nil
+	}
+}
+
+// Lookup returns the remembered decision for a tool use, if any.
+// Entries recorded by a CacheSession are not visible to it.
+func (c *Cache) Lookup(toolName string, input map[string]any) (Result, bool) {
+	return c.lookup(0, toolName, input)
+}
+
+// lookup returns the remembered decision visible to session.
+func (c *Cache) lookup(session uint64, toolName string, input map[string]any) (Result, bool) {
+	key := c.opts.KeyFunc(toolName, input)
+	if key == "" {
+		return nil, false
+	}
+
+	now := c.now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var match *CacheEntry
+
+	for _, e := range c.entries {
+		if e.ToolName != toolName || e.expired(now) || !keyMatches(e.Key, key) ||
+			(e.session != 0 && e.session != session) {
+			continue
+		}
+
+		// Prefer the most specific entry, and deny over allow at equal specificity.
+		if match == nil || len(e.Key) > len(match.Key) ||
+			(len(e.Key) == len(match.Key) && e.Behavior == BehaviorDeny) {
+			match = e
+		}
+	}
+
+	if match == nil {
+		return nil, false
+	}
+
+	if match.Behavior == BehaviorDeny {
+		return &ResultDeny{Behavior: "deny", Message: match.Message}, true
+	}
+
+	return &ResultAllow{Behavior: "allow"}, true
+}
+
+// Remember records a decision for a tool use under the given scope,
+// computing the key from input with the cache's KeyFunc. Inputs with an
+// empty key are not cached.
+func (c *Cache) Remember(toolName string, input map[string]any, result Result, scope Scope) error {
+	key := c.opts.KeyFunc(toolName, input)
+	if key == "" {
+		return nil
+	}
+
+	return c.store(0, toolName, key, Unwrap(result), scope, c.opts.TTL)
+}
+
+// Allow records an allow decision for an explicit key, such as
+// Allow("Read", "/repo/src/", ScopeSession). The key must not be empty.
+func (c *Cache) Allow(toolName, key string, scope Scope) error {
+	if key == "" {
+		return errEmptyKey
+	}
+
+	return c.store(0, toolName, key, &ResultAllow{Behavior: "allow"}, scope, c.opts.TTL)
+}
+
+// Deny records a deny decision for an explicit key. The key must not be empty.
+func (c *Cache) Deny(toolName, key, message string, scope Scope) error {
+	if key == "" {
+		return errEmptyKey
+	}
+
+	return c.store(0, toolName, key, &ResultDeny{Behavior: "deny", Message: message}, scope, c.opts.TTL)
+}
+
+// Revoke removes entries for a tool with exactly the given key.
+// It returns the number of entries removed.
+func (c *Cache) Revoke(toolName, key string) (int, error) {
+	return c.remove(func(e *CacheEntry) bool {
+		return e.ToolName == toolName && e.Key == key
+	})
+}
+
+// RevokeTool removes every entry for a tool.
+// It returns the number of entries removed.
+func (c *Cache) RevokeTool(toolName string) (int, error) {
+	return c.remove(func(e *CacheEntry) bool {
+		return e.ToolName == toolName
+	})
+}
+
+// RevokeAll removes every entry, including persisted ones.
+func (c *Cache) RevokeAll() error {
+	_, err := c.remove(func(*CacheEntry) bool { return true })
+
+	return err
+}
+
+// EndTurn drops turn-scoped entries recorded on the Cache itself.
+// Entries recorded by a CacheSession are cleared by its own EndTurn.
+func (c *Cache) EndTurn() {
+	_, _ = c.remove(func(e *CacheEntry) bool { return e.session == 0 && e.Scope == ScopeTurn })
+}
+
+// EndSession drops turn- and session-scoped entries recorded on the Cache
+// itself. Entries recorded by a CacheSession are cleared by its own EndSession.
+func (c *Cache) EndSession() {
+	_, _ = c.remove(func(e *CacheEntry) bool {
+		return e.session == 0 && (e.Scope == ScopeTurn || e.Scope == ScopeSession)
+	})
+}
+
+// Entries returns a snapshot of all unexpired entries.
+func (c *Cache) Entries() []CacheEntry {
+	now := c.now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := make([]CacheEntry, 0, len(c.entries))
+
+	for _, e := range c.entries {
+		if !e.expired(now) {
+			out = append(out, *e)
+		}
+	}
+
+	return out
+}
+
+// store adds or replaces the entry for toolName and key in session.
+// Forever entries are always shared across sessions.
+func (c *Cache) store(
+	session uint64,
+	toolName, key string,
+	result Result,
+	scope Scope,
+	ttl time.Duration,
+) error {
+	if scope == ScopeOnce {
+		return nil
+	}
+
+	if scope == ScopeForever {
+		session = 0
+	}
+
+	now := c.now()
+	entry := &CacheEntry{
+		ToolName:  toolName,
+		Key:       key,
+		Behavior:  Behavior(result.GetBehavior()),
+		Scope:     scope,
+		CreatedAt: now,
+		session:   session,
+	}
+
+	if deny, ok := result.(*ResultDeny); ok {
+		entry.Message = deny.Message
+	}
+
+	if ttl > 0 {
+		entry.ExpiresAt = now.Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = slices.DeleteFunc(c.entries, func(e *CacheEntry) bool {
+		return e.ToolName == toolName && e.Key == key && e.session == session
+	})
+	c.entries = append(c.entries, entry)
+
+	return c.persistLocked(scope)
+}
+
+// remove deletes entries matching fn, persisting if any forever entry was removed.
+func (c *Cache) remove(fn func(*CacheEntry) bool) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed, persisted := 0, false
+
+	c.entries = slices.DeleteFunc(c.entries, func(e *CacheEntry) bool {
+		if !fn(e) {
+			return false
+		}
+
+		removed++
+		persisted = persisted || e.Scope == ScopeForever
+
+		return true
+	})
+
+	if !persisted {
+		return removed, nil
+	}
+
+	return removed, c.persistLocked(ScopeForever)
+}
+
+// persistLocked saves forever entries to the store. Caller must hold c.mu.
+func (c *Cache) persistLocked(scope Scope) error {
+	if scope != ScopeForever || c.opts.Store == nil {
+		return nil
+	}
+
+	now := c.now()
+	forever := make([]CacheEntry, 0, len(c.entries))
+
+	for _, e := range c.entries {
+		if e.Scope == ScopeForever && !e.expired(now) {
+			forever = append(forever, *e)
+		}
+	}
+
+	if err := c.opts.Store.Save(forever); err != nil {
+		return fmt.Errorf("save permission cache: %w", err)
+	}
+
+	return nil
+}
+
+// keyMatches reports whether an entry key covers a lookup key.
+// The entry key must equal the lookup key or be a prefix ending at a
+// path or word boundary. Exact keys match only when equal, and empty keys
+// never match.
+func keyMatches(entryKey, key string) bool {
+	if entryKey == "" || key == "" {
+		return false
+	}
+
+	if entryKey == key {
+		return true
+	}
+
+	if strings.HasSuffix(entryKey, ExactKeySuffix) || strings.HasSuffix(key, ExactKeySuffix) {
+		return false
+	}
+
+	if !strings.HasPrefix(key, entryKey) {
+		return false
+	}
+
+	if strings.HasSuffix(entryKey, "/") {
+		return true
+	}
+
+	next := key[len(entryKey)]
+
+	return next == ' ' || next == '/'
+}
+
+// DefaultKey is the default KeyFunc. It normalizes inputs as follows:
+//   - Read, Write, Edit, MultiEdit, NotebookEdit: the directory of the file, with a trailing slash;
+//     not cached for files directly in "/", "." or a parent directory
+//   - Glob, Grep, LS: the search path, with a trailing slash ("." when searching the cwd);
+//     not cached for "/"
+//   - Bash: the command name plus its subcommand, e.g. "git log", when the
+//     other arguments are not flags; otherwise the whole command line, which
+//     matches exactly; not cached for bare command names, wrapper commands
+//     such as sudo or env, or commands with shell metacharacters
+//   - WebFetch: the URL scheme and host, e.g. "https://example.com/"
+//   - Any other tool: the JSON encoding of the full input
+func DefaultKey(toolName string, input map[string]any) string {
+	switch toolName {
+	case "Read", "Write", "Edit", "MultiEdit":
+		return dirKey(stringField(input, "file_path"))
+	case "NotebookEdit":
+		return dirKey(stringField(input, "notebook_path"))
+	case "Glob", "Grep", "LS":
+		if p := stringField(input, "path"); p != "" {
+			if path.Clean(p) == "/" {
+				return ""
+			}
+
+			return strings.TrimSuffix(path.Clean(p), "/") + "/"
+		}
+
+		return "."
+	case "Bash":
+		return commandPrefix(stringField(input, "command"))
+	case "WebFetch":
+		u, err := url.Parse(stringField(input, "url"))
+		if err != nil || u.Host == "" {
+			return stringField(input, "url")
+		}
+
+		return u.Scheme + "://" + u.Host + "/"
+	default:
+		data, err := json.Marshal(input)
+		if err != nil {
+			return ""
+		}
+
+		return string(data)
+	}
+}
+
+// dirKey returns the directory containing filePath with a trailing slash.
+// It returns "" (not cacheable) when the directory is "/", "." or a parent
+// such as "..", whose key would cover every other directory.
+func dirKey(filePath string) string {
+	if filePath == "" {
+		return ""
+	}
+
+	dir := path.Dir(path.Clean(filePath))
+	if dir == "/" || dir == "." || dir == ".." || strings.HasSuffix(dir, "/..") {
+		return ""
+	}
+
+	return dir + "/"
+}
+
+// shellMetachars are characters that chain, substitute or redirect commands.
+// A cached decision for one command must never cover a command containing them.
+const shellMetachars = ";&|`$<>()\n\r\\"
+
+// wrapperCommands run the command given in their arguments, so a key built
+// from their first words says nothing about what runs.
+var wrapperCommands = []string{
+	"sudo", "doas", "su", "env", "xargs", "nice", "ionice", "timeout", "nohup",
+	"exec", "command", "time", "sh", "bash", "zsh", "dash", "ksh",
+}
+
+// commandPrefix returns the command name and its subcommand when the other
+// arguments are not flags, or the whole normalized command line marked with
+// ExactKeySuffix. It returns "" (not cacheable) for commands containing
+// shell metacharacters, for wrapper commands and variable assignments that
+// run another command, and for bare command names, whose key would cover
+// every invocation of the command.
+func commandPrefix(command string) string {
+	if strings.ContainsAny(command, shellMetachars) {
+		return ""
+	}
+
+	fields := strings.Fields(command)
+	if len(fields) < 2 || strings.Contains(fields[0], "=") || slices.Contains(wrapperCommands, path.Base(fields[0])) {
+		return ""
+	}
+
+	flags := slices.ContainsFunc(fields[1:], func(f string) bool { return strings.HasPrefix(f, "-") })
+	if !flags && !strings.ContainsAny(fields[1], "/.") {
+		return fields[0] + " " + fields[1]
+	}
+
+	return strings.Join(fields, " ") + ExactKeySuffix
+}
+
+// stringField extracts a string value from a tool input map.
+func stringField(input map[string]any, key string) string {
+	s, _ := input[key].(string)
+
+	return s
+}
@@ -0,0 +1,414 @@
+package permission
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// countingCallback returns a callback that records invocations and returns result.
+func countingCallback(calls *int, result Result) Callback {
+	return func(_ context.Context, _ string, _ map[string]any, _ *Context) (Result, error) {
+		*calls++
+
+		return result, nil
+	}
+}
+
+func TestDefaultKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		toolName string
+		input    map[string]any
+		want     string
+	}{
+		{"read file", "Read", map[string]any{"file_path": "/repo/src/main.go"}, "/repo/src/"},
+		{"edit file", "Edit", map[string]any{"file_path": "/repo/a/../b/x.go"}, "/repo/b/"},
+		{"glob with path", "Glob", map[string]any{"pattern": "*.go", "path": "/repo/src/"}, "/repo/src/"},
+		{"grep without path", "Grep", map[string]any{"pattern": "TODO"}, "."},
+		{"root file", "Write", map[string]any{"file_path": "/foo"}, ""},
+		{"cwd file", "Write", map[string]any{"file_path": "foo"}, ""},
+		{"parent file", "Write", map[string]any{"file_path": "../foo"}, ""},
+		{"grandparent file", "Write", map[string]any{"file_path": "../../foo"}, ""},
+		{"relative file", "Write", map[string]any{"file_path": "src/foo.go"}, "src/"},
+		{"grep root", "Grep", map[string]any{"pattern": "x", "path": "/"}, ""},
+		{"bash subcommand", "Bash", map[string]any{"command": "git log main"}, "git log"},
+		{"bash subcommand flag", "Bash", map[string]any{"command": "git status --short"}, "git status --short$"},
+		{"bash flag", "Bash", map[string]any{"command": "ls  -la /tmp"}, "ls -la /tmp$"},
+		{"bash flag only", "Bash", map[string]any{"command": "git --version"}, "git --version$"},
+		{"bash path argument", "Bash", map[string]any{"command": "cat ./README.md"}, "cat ./README.md$"},
+		{"bash sudo", "Bash", map[string]any{"command": "sudo rm x"}, ""},
+		{"bash doas", "Bash", map[string]any{"command": "doas rm x"}, ""},
+		{"bash env", "Bash", map[string]any{"command": "env FOO=1 rm x"}, ""},
+		{"bash assignment", "Bash", map[string]any{"command": "FOO=1 rm x"}, ""},
+		{"bash xargs", "Bash", map[string]any{"command": "xargs rm"}, ""},
+		{"bash sh -c", "Bash", map[string]any{"command": "sh -c 'rm x'"}, ""},
+		{"bash bash -c", "Bash", map[string]any{"command": "/bin/bash -c 'rm x'"}, ""},
+		{"bash nice", "Bash", map[string]any{"command": "nice rm x"}, ""},
+		{"bash timeout", "Bash", map[string]any{"command": "timeout 5 rm x"}, ""},
+		{"bash nohup", "Bash", map[string]any{"command": "nohup rm x"}, ""},
+		{"bash bare command", "Bash", map[string]any{"command": "git"}, ""},
+		{"bash sequence", "Bash", map[string]any{"command": "ls -la; rm -rf /"}, ""},
+		{"bash and", "Bash", map[string]any{"command": "git status && curl x|sh"}, ""},
+		{"bash substitution", "Bash", map[string]any{"command": "echo $(id)"}, ""},
+		{"bash redirect", "Bash", map[string]any{"command": "git log > out"}, ""},
+		{"bash newline", "Bash", map[string]any{"command": "git status\nrm x"}, ""},
+		{"missing file path", "Read", map[string]any{}, ""},
+		{"missing command", "Bash", map[string]any{}, ""},
+		{"web fetch", "WebFetch", map[string]any{"url": "https://example.com/docs?q=1"}, "https://example.com/"},
+		{"other tool", "mcp__x__y", map[string]any{"b": 1, "a": "z"}, `{"a":"z","b":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, DefaultKey(tt.toolName, tt.input))
+		})
+	}
+}
+
+func TestKeyMatches(t *testing.T) {
+	require.True(t, keyMatches("/repo/", "/repo/src/"))
+	require.True(t, keyMatches("git status", "git status"))
+	require.True(t, keyMatches("git", "git status"))
+	require.False(t, keyMatches("", "anything"))
+	require.False(t, keyMatches("git", ""))
+	require.False(t, keyMatches("git --version", "git push --force"))
+	require.False(t, keyMatches("/repo/src/", "/repo/"))
+	require.False(t, keyMatches("git stat", "git status"))
+	require.False(t, keyMatches(".", "/repo/"))
+	require.True(t, keyMatches("rm -rf build$", "rm -rf build$"))
+	require.False(t, keyMatches("rm -rf build$", "rm -rf build /$"))
+	require.False(t, keyMatches("git push", "git push --force origin main$"))
+}
+
+func TestCache_WrapRemembersAllow(t *testing.T) {
+	cache, err := NewCache(nil)
+	require.NoError(t, err)
+
+	var calls int
+
+	cb := cache.Wrap(countingCallback(&calls, &ResultAllow{Behavior: "allow"}))
+	ctx := context.Background()
+
+	for _, file := range []string{"/repo/a.go", "/repo/b.go", "/repo/sub/c.go"} {
+		result, err := cb(ctx, "Read", map[string]any{"file_path": file}, &Context{})
+		require.NoError(t, err)
+		require.Equal(t, "allow", result.GetBehavior())
+	}
+
+	require.Equal(t, 1, calls, "subsequent reads under the same directory should hit the cache")
+
+	_, err = cb(ctx, "Read", map[string]any{"file_path": "/etc/passwd"}, &Context{})
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+}
+
+func TestCache_DenialsNotCachedByDefault(t *testing.T) {
+	cache, err := NewCache(nil)
+	require.NoError(t, err)
+
+	var calls int
+
+	cb := cache.Wrap(countingCallback(&calls, &ResultDeny{Behavior: "deny", Message: "no"}))
+	input := map[string]any{"command": "git push origin main"}
+
+	for range 2 {
+		result, err := cb(context.Background(), "Bash", input, &Context{})
+		require.NoError(t, err)
+		require.Equal(t, "deny", result.GetBehavior())
+	}
+
+	require.Equal(t, 2, calls)
+}
+
+func TestCache_CacheDenials(t *testing.T) {
+	cache, err := NewCache(&CacheOptions{CacheDenials: true})
+	require.NoError(t, err)
+
+	var calls int
+
+	cb := cache.Wrap(countingCallback(&calls, &ResultDeny{Behavior: "deny", Message: "blocked"}))
+	input := map[string]any{"command": "git push origin main"}
+
+	_, err = cb(context.Background(), "Bash", input, &Context{})
+	require.NoError(t, err)
+
+	result, err := cb(context.Background(), "Bash", map[string]any{"command": "git push origin feature"}, &Context{})
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+
+	deny, ok := result.(*ResultDeny)
+	require.True(t, ok)
+	require.Equal(t, "blocked", deny.Message)
+}
+
+func TestCache_RememberedScopeOverride(t *testing.T) {
+	cache, err := NewCache(nil)
+	require.NoError(t, err)
+
+	var calls int
+
+	cb := cache.Wrap(countingCallback(&calls, Remember(&ResultAllow{Behavior: "allow"}, ScopeOnce)))
+	input := map[string]any{"file_path": "/repo/a.go"}
+
+	for range 2 {
+		result, err := cb(context.Background(), "Write", input, &Context{})
+		require.NoError(t, err)
+		require.IsType(t, &ResultAllow{}, result, "Remembered must be unwrapped")
+	}
+
+	require.Equal(t, 2, calls, "ScopeOnce decisions must not be cached")
+}
+
+func TestCache_TurnAndSessionScopes(t *testing.T) {
+	cache, err := NewCache(&CacheOptions{Scope: ScopeTurn})
+	require.NoError(t, err)
+
+	require.NoError(t, cache.Allow("Read", "/a/", ScopeTurn))
+	require.NoError(t, cache.Allow("Read", "/b/", ScopeSession))
+
+	cache.EndTurn()
+
+	_, ok := cache.Lookup("Read", map[string]any{"file_path": "/a/x"})
+	require.False(t, ok)
+
+	_, ok = cache.Lookup("Read", map[string]any{"file_path": "/b/x"})
+	require.True(t, ok)
+
+	cache.EndSession()
+
+	_, ok = cache.Lookup("Read", map[string]any{"file_path": "/b/x"})
+	require.False(t, ok)
+}
+
+func TestCache_EmptyKeysNotCached(t *testing.T) {
+	cache, err := NewCache(nil)
+	require.NoError(t, err)
+
+	var calls int
+
+	cb := cache.Wrap(countingCallback(&calls, &ResultAllow{Behavior: "allow"}))
+
+	for range 2 {
+		_, err := cb(context.Background(), "Read", map[string]any{}, &Context{})
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, 2, calls, "inputs without a key must not be cached")
+	require.Empty(t, cache.Entries())
+	require.Error(t, cache.Allow("Read", "", ScopeSession))
+
+	_, ok := cache.Lookup("Read", map[string]any{"file_path": "/etc/passwd"})
+	require.False(t, ok)
+}
+
+func TestCache_ChainedBashCommandsNotCovered(t *testing.T) {
+	cache, err := NewCache(nil)
+	require.NoError(t, err)
+
+	var calls int
+
+	cb := cache.Wrap(countingCallback(&calls, &ResultAllow{Behavior: "allow"}))
+	ctx := context.Background()
+
+	for _, command := range []string{"git --version", "git push --force", "ls -la; rm -rf /", "ls -la; rm -rf /"} {
+		_, err := cb(ctx, "Bash", map[string]any{"command": command}, &Context{})
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, 4, calls)
+}
+
+func TestCache_ApprovalDoesNotCoverBroaderCommands(t *testing.T) {
+	tests := []struct {
+		approved, other string
+	}{
+		{"rm -rf build", "rm -rf build /"},
+		{"git push", "git push --force origin main"},
+		{"git push origin main", "git push --force origin main"},
+		{"git status --short", "git status --short ; rm -rf /"},
+		{"sudo rm x", "sudo rm -rf /"},
+		{"env FOO=1 ls", "env FOO=1 rm -rf /"},
+		{"xargs ls", "xargs rm -rf"},
+		{"sh -c ls", "sh -c 'rm -rf /'"},
+		{"bash -c ls", "bash -c 'rm -rf /'"},
+		{"nice ls", "nice rm -rf /"},
+		{"timeout 5 ls", "timeout 5 rm -rf /"},
+		{"nohup ls", "nohup rm -rf /"},
+		{"doas ls", "doas rm -rf /"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.approved, func(t *testing.T) {
+			cache, err := NewCache(nil)
+			require.NoError(t, err)
+
+			var calls int
+
+			cb := cache.Wrap(countingCallback(&calls, &ResultAllow{Behavior: "allow"}))
+
+			for _, command := range []string{tt.approved, tt.other} {
+				_, err := cb(context.Background(), "Bash", map[string]any{"command": command}, &Context{})
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, 2, calls, "approving %q must not cover %q", tt.approved, tt.other)
+		})
+	}
+}
+
+func TestCache_RootFileApprovalDoesNotCoverOtherDirectories(t *testing.T) {
+	cache, err := NewCache(nil)
+	require.NoError(t, err)
+
+	var calls int
+
+	cb := cache.Wrap(countingCallback(&calls, &ResultAllow{Behavior: "allow"}))
+
+	for _, file := range []string{"/foo", "/etc/passwd", "../foo", "../etc/passwd"} {
+		_, err := cb(context.Background(), "Write", map[string]any{"file_path": file}, &Context{})
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, 4, calls)
+	require.Len(t, cache.Entries(), 2, "only the files in /etc were cached")
+}
+
+func TestCacheSession_ScopesAreIsolated(t *testing.T) {
+	cache, err := NewCache(nil)
+	require.NoError(t, err)
+
+	first, second := cache.Session(), cache.Session()
+	allow := &ResultAllow{Behavior: "allow"}
+	input := map[string]any{"file_path": "/repo/a.go"}
+
+	var calls int
+
+	_, err = first.Wrap(countingCallback(&calls, allow))(context.Background(), "Read", input, &Context{})
+	require.NoError(t, err)
+
+	_, err = second.Wrap(countingCallback(&calls, Remember(allow, ScopeForever)))(
+		context.Background(), "Read", map[string]any{"file_path": "/shared/a.go"}, &Context{})
+	require.NoError(t, err)
+
+	_, ok := first.Lookup("Read", input)
+	require.True(t, ok)
+
+	_, ok = second.Lookup("Read", input)
+	require.False(t, ok, "session-scoped entries are not shared")
+
+	_, ok = first.Lookup("Read", map[string]any{"file_path": "/shared/b.go"})
+	require.True(t, ok, "forever entries are shared")
+
+	second.EndSession()
+
+	_, ok = first.Lookup("Read", input)
+	require.True(t, ok, "ending one session must not clear another")
+
+	first.EndSession()
+
+	_, ok = first.Lookup("Read", input)
+	require.False(t, ok)
+
+	var nilSession *CacheSession
+
+	nilSession.EndTurn()
+	nilSession.EndSession()
+}
+
+func TestCache_TTL(t *testing.T) {
+	cache, err := NewCache(&CacheOptions{TTL: time.Minute})
+	require.NoError(t, err)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	require.NoError(t, cache.Allow("Bash", "go test", ScopeSession))
+
+	_, ok := cache.Lookup("Bash", map[string]any{"command": "go test ./..."})
+	require.True(t, ok)
+
+	now = now.Add(2 * time.Minute)
+
+	_, ok = cache.Lookup("Bash", map[string]any{"command": "go test ./..."})
+	require.False(t, ok)
+	require.Empty(t, cache.Entries())
+}
+
+func TestCache_MostSpecificEntryWins(t *testing.T) {
+	cache, err := NewCache(nil)
+	require.NoError(t, err)
+
+	require.NoError(t, cache.Allow("Read", "/repo/", ScopeSession))
+	require.NoError(t, cache.Deny("Read", "/repo/secrets/", "secrets are off limits", ScopeSession))
+
+	result, ok := cache.Lookup("Read", map[string]any{"file_path": "/repo/secrets/key.pem"})
+	require.True(t, ok)
+	require.Equal(t, "deny", result.GetBehavior())
+
+	result, ok = cache.Lookup("Read", map[string]any{"file_path": "/repo/main.go"})
+	require.True(t, ok)
+	require.Equal(t, "allow", result.GetBehavior())
+}
+
+func TestCache_Revoke(t *testing.T) {
+	cache, err := NewCache(nil)
+	require.NoError(t, err)
+
+	require.NoError(t, cache.Allow("Read", "/a/", ScopeSession))
+	require.NoError(t, cache.Allow("Read", "/b/", ScopeSession))
+	require.NoError(t, cache.Allow("Bash", "ls", ScopeSession))
+
+	n, err := cache.Revoke("Read", "/a/")
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	n, err = cache.RevokeTool("Read")
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	require.Len(t, cache.Entries(), 1)
+	require.NoError(t, cache.RevokeAll())
+	require.Empty(t, cache.Entries())
+}
+
+func TestCache_FileStorePersistsForeverEntries(t *testing.T) {
+	store := NewFileStore(filepath.Join(t.TempDir(), "nested", "permissions.json"))
+
+	cache, err := NewCache(&CacheOptions{Store: store})
+	require.NoError(t, err)
+
+	require.NoError(t, cache.Allow("Read", "/repo/", ScopeForever))
+	require.NoError(t, cache.Allow("Bash", "ls", ScopeSession))
+
+	reloaded, err := NewCache(&CacheOptions{Store: store})
+	require.NoError(t, err)
+
+	entries := reloaded.Entries()
+	require.Len(t, entries, 1)
+	require.Equal(t, "Read", entries[0].ToolName)
+	require.Equal(t, ScopeForever, entries[0].Scope)
+
+	reloaded.EndSession()
+
+	_, ok := reloaded.Lookup("Read", map[string]any{"file_path": "/repo/x.go"})
+	require.True(t, ok, "forever entries survive EndSession")
+
+	_, err = reloaded.RevokeTool("Read")
+	require.NoError(t, err)
+
+	persisted, err := store.Load()
+	require.NoError(t, err)
+	require.Empty(t, persisted)
+}
+
+func TestFileStore_LoadMissingFile(t *testing.T) {
+	store := NewFileStore(filepath.Join(t.TempDir(), "missing.json"))
+
+	entries, err := store.Load()
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
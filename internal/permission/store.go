@@ -0,0 +1,79 @@
+package permission
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Compile-time verification that FileStore implements Store.
+var _ Store = (*FileStore)(nil)
+
+// FileStore persists cache entries as a JSON file.
+type FileStore struct {
+	// Path is the location of the JSON file. Parent directories are created on save.
+	Path string
+}
+
+// NewFileStore creates a FileStore backed by the file at path.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{Path: path}
+}
+
+// Load implements Store. A missing file yields no entries.
+func (s *FileStore) Load() ([]CacheEntry, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", s.Path, err)
+	}
+
+	var entries []CacheEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", s.Path, err)
+	}
+
+	return entries, nil
+}
+
+// Save implements Store. The file is replaced atomically with mode 0600.
+func (s *FileStore) Save(entries []CacheEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode permission cache: %w", err)
+	}
+
+	dir := filepath.Dir(s.Path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.Path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("write %s: %w", tmp.Name(), err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", tmp.Name(), err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.Path); err != nil {
+		return fmt.Errorf("replace %s: %w", s.Path, err)
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package protocol
 import (
 	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"log/slog"
 	"maps"
@@ -10,7 +11,10 @@ import (
 	"time"
 
 	"github.com/oklog/ulid/v2"
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/wagiedev/claude-agent-sdk-go/internal/errors"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/telemetry"
 )
 
 // Transport defines the minimal interface needed for protocol operations.
@@ -36,6 +40,7 @@ type Transport interface {
 type Controller struct {
 	log       *slog.Logger
 	transport Transport
+	recorder  *telemetry.Recorder
 
 	// Request tracking
 	pendingMu sync.RWMutex
@@ -94,6 +99,17 @@ func NewController(log *slog.Logger, transport Transport) *Controller {
 	}
 }
 
+// SetRecorder sets the telemetry recorder used for control request spans.
+// It must be called before Start.
+func (c *Controller) SetRecorder(recorder *telemetry.Recorder) {
+	c.recorder = recorder
+}
+
+// Recorder returns the telemetry recorder, or nil if none is set.
+func (c *Controller) Recorder() *telemetry.Recorder {
+	return c.recorder
+}
+
 // closeDone safely closes the done channel exactly once.
 func (c *Controller) closeDone() {
 	c.closeOnce.Do(func() {
@@ -185,12 +201,33 @@ func (c *Controller) Messages() <-chan map[string]any {
 // Use context cancellation for overall operation timeout.
 //
 // Returns an error if the request fails to send, times out, or the CLI
-// returns an error response.
+// returns an error response. With a recorder set, the round-trip is
+// recorded as a span.
 func (c *Controller) SendRequest(
 	ctx context.Context,
 	subtype string,
 	payload map[string]any,
 	timeout time.Duration,
+) (*ControlResponse, error) {
+	ctx, span := c.recorder.Start(ctx, "claude.control "+subtype,
+		attribute.String(telemetry.AttrControlSubtype, subtype))
+
+	resp, err := c.sendRequest(ctx, subtype, payload, timeout)
+	if stderrors.Is(err, errors.ErrRequestTimeout) {
+		c.recorder.Add(telemetry.MetricControlTimeouts, 1, telemetry.Label{Name: "subtype", Value: subtype})
+	}
+
+	telemetry.End(span, err)
+
+	return resp, err
+}
+
+// sendRequest performs the SendRequest round-trip.
+func (c *Controller) sendRequest(
+	ctx context.Context,
+	subtype string,
+	payload map[string]any,
+	timeout time.Duration,
 ) (*ControlResponse, error) {
 	// Generate unique request ID
 	requestID := c.generateRequestID()
@@ -10,10 +10,14 @@ import (
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
 	"github.com/wagiedev/claude-agent-sdk-go/internal/config"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/hook"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/mcp"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/permission"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/telemetry"
 )
 
 const (
@@ -36,6 +40,9 @@ type Session struct {
 	// SDK MCP servers (keyed by server name)
 	sdkMcpServers map[string]mcp.ServerInstance
 
+	// This session's view of options.PermissionCache, nil if none is configured
+	permissions *permission.CacheSession
+
 	// Server initialization result (protected by initMu)
 	initMu               sync.RWMutex
 	initializationResult map[string]any
@@ -47,13 +54,25 @@ func NewSession(
 	controller *Controller,
 	options *config.Options,
 ) *Session {
-	return &Session{
+	s := &Session{
 		log:           log.With("component", "session"),
 		controller:    controller,
 		options:       options,
 		hookCallbacks: make(map[string]hook.Callback, 16),
 		sdkMcpServers: make(map[string]mcp.ServerInstance, 4),
 	}
+
+	if options != nil {
+		s.permissions = options.PermissionCache.Session()
+	}
+
+	return s
+}
+
+// PermissionCache returns this session's view of the configured permission
+// cache, or nil if none is configured.
+func (s *Session) PermissionCache() *permission.CacheSession {
+	return s.permissions
 }
 
 // RegisterHandlers registers protocol handlers for hooks, MCP, and tool permissions.
@@ -91,43 +110,7 @@ func (s *Session) RegisterMCPServers() {
 func (s *Session) Initialize(ctx context.Context) error {
 	s.log.Debug("Sending initialize request")
 
-	// Build hooks configuration for initialization with callback IDs
-	hooksConfig := make(map[string]any, 8)
-
-	if s.options != nil && s.options.Hooks != nil {
-		s.hookCallbacksMu.Lock()
-
-		for event, matchers := range s.options.Hooks {
-			eventMatchers := make([]map[string]any, 0, len(matchers))
-
-			for _, m := range matchers {
-				// Generate callback IDs for each hook in this matcher
-				callbackIDs := make([]string, 0, len(m.Hooks))
-
-				for _, hookFn := range m.Hooks {
-					callbackID := fmt.Sprintf("hook_%d", s.nextCallbackID)
-					s.nextCallbackID++
-					s.hookCallbacks[callbackID] = hookFn
-					callbackIDs = append(callbackIDs, callbackID)
-				}
-
-				matcherConfig := map[string]any{
-					"matcher":         m.Matcher,
-					"hookCallbackIds": callbackIDs,
-				}
-
-				if m.Timeout != nil {
-					matcherConfig["timeout"] = *m.Timeout
-				}
-
-				eventMatchers = append(eventMatchers, matcherConfig)
-			}
-
-			hooksConfig[string(event)] = eventMatchers
-		}
-
-		s.hookCallbacksMu.Unlock()
-	}
+	hooksConfig := s.registerHooks()
 
 	payload := map[string]any{
 		"hooks": hooksConfig,
@@ -178,6 +161,7 @@ func (s *Session) NeedsInitialization() bool {
 
 	// Need initialization if we have hooks, CanUseTool callback, SDK MCP servers, or agents
 	return len(s.options.Hooks) > 0 ||
+		(s.options.HookRegistry != nil && len(s.options.HookRegistry.Events()) > 0) ||
 		s.options.CanUseTool != nil ||
 		len(s.sdkMcpServers) > 0 ||
 		len(s.options.Agents) > 0
@@ -207,6 +191,65 @@ func (s *Session) GetSDKMCPServerNames() []string {
 	return names
 }
 
+// registerHooks assigns callback IDs to the configured hooks and returns the
+// hooks configuration sent with the initialize request. Matcher predicates are
+// applied here so that the CLI only ever sees plain callback IDs.
+func (s *Session) registerHooks() map[string]any {
+	hooksConfig := make(map[string]any, 8)
+
+	if s.options == nil {
+		return hooksConfig
+	}
+
+	s.hookCallbacksMu.Lock()
+	defer s.hookCallbacksMu.Unlock()
+
+	for event, matchers := range s.options.Hooks {
+		eventMatchers := make([]map[string]any, 0, len(matchers))
+
+		for _, m := range matchers {
+			// Generate callback IDs for each hook in this matcher
+			callbackIDs := make([]string, 0, len(m.Hooks))
+
+			for _, hookFn := range m.Hooks {
+				callbackID := fmt.Sprintf("hook_%d", s.nextCallbackID)
+				s.nextCallbackID++
+				s.hookCallbacks[callbackID] = hook.Filter(m.Predicate, hookFn)
+				callbackIDs = append(callbackIDs, callbackID)
+			}
+
+			matcherConfig := map[string]any{
+				"matcher":         m.Matcher,
+				"hookCallbackIds": callbackIDs,
+			}
+
+			if m.Timeout != nil {
+				matcherConfig["timeout"] = *m.Timeout
+			}
+
+			eventMatchers = append(eventMatchers, matcherConfig)
+		}
+
+		hooksConfig[string(event)] = eventMatchers
+	}
+
+	if s.options.HookRegistry != nil {
+		for _, event := range s.options.HookRegistry.Events() {
+			callbackID := fmt.Sprintf("hook_%d", s.nextCallbackID)
+			s.nextCallbackID++
+			s.hookCallbacks[callbackID] = s.options.HookRegistry.Dispatcher(event)
+
+			eventMatchers, _ := hooksConfig[string(event)].([]map[string]any)
+			hooksConfig[string(event)] = append(eventMatchers, map[string]any{
+				"matcher":         nil,
+				"hookCallbackIds": []string{callbackID},
+			})
+		}
+	}
+
+	return hooksConfig
+}
+
 // HandleHookCallback handles hook_callback control requests from the CLI.
 // The CLI sends callback_id which we use to look up the registered callback.
 func (s *Session) HandleHookCallback(
@@ -243,256 +286,42 @@ func (s *Session) HandleHookCallback(
 		return nil, fmt.Errorf("unknown callback_id: %s", callbackID)
 	}
 
-	// Parse input into appropriate HookInput type
-	hookInput, err := s.parseHookInput(inputData)
-	if err != nil {
-		return nil, fmt.Errorf("parse hook input: %w", err)
-	}
-
-	// Call the callback
-	hookCtx := &hook.Context{}
-
-	output, err := callback(ctx, hookInput, toolUseID, hookCtx)
-	if err != nil {
-		return nil, fmt.Errorf("hook callback error: %w", err)
+	event, _ := inputData["hook_event_name"].(string)
+	attrs := []attribute.KeyValue{
+		attribute.String(telemetry.AttrHookEvent, event),
+		attribute.String(telemetry.AttrHookCallbackID, callbackID),
 	}
 
-	// Convert output to response format
-	return s.convertHookOutput(output)
-}
-
-// parseHookInput converts the input map to the appropriate HookInput type.
-func (s *Session) parseHookInput(inputData map[string]any) (hook.Input, error) {
-	if inputData == nil {
-		return nil, fmt.Errorf("input data is nil")
+	if toolUseID != nil {
+		attrs = append(attrs, attribute.String(telemetry.AttrToolCallID, *toolUseID))
 	}
 
-	hookEventName, _ := inputData["hook_event_name"].(string)
-	sessionID, _ := inputData["session_id"].(string)
-	transcriptPath, _ := inputData["transcript_path"].(string)
-	cwd, _ := inputData["cwd"].(string)
+	ctx, span := s.recorder().Start(ctx, "claude.hook "+event, attrs...)
+	start := time.Now()
 
-	var permissionMode *string
-	if pm, ok := inputData["permission_mode"].(string); ok {
-		permissionMode = &pm
-	}
+	result, err := hook.Invoke(ctx, callback, inputData, toolUseID)
 
-	baseInput := hook.BaseInput{
-		SessionID:      sessionID,
-		TranscriptPath: transcriptPath,
-		Cwd:            cwd,
-		PermissionMode: permissionMode,
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
 	}
 
-	switch hookEventName {
-	case string(hook.EventPreToolUse):
-		toolName, _ := inputData["tool_name"].(string)
-		toolInput, _ := inputData["tool_input"].(map[string]any)
-		toolUseID, _ := inputData["tool_use_id"].(string)
-
-		return &hook.PreToolUseInput{
-			BaseInput:     baseInput,
-			HookEventName: hookEventName,
-			ToolName:      toolName,
-			ToolInput:     toolInput,
-			ToolUseID:     toolUseID,
-		}, nil
-
-	case string(hook.EventPostToolUse):
-		toolName, _ := inputData["tool_name"].(string)
-		toolInput, _ := inputData["tool_input"].(map[string]any)
-		toolUseID, _ := inputData["tool_use_id"].(string)
-		toolResponse := inputData["tool_response"]
-
-		return &hook.PostToolUseInput{
-			BaseInput:     baseInput,
-			HookEventName: hookEventName,
-			ToolName:      toolName,
-			ToolInput:     toolInput,
-			ToolUseID:     toolUseID,
-			ToolResponse:  toolResponse,
-		}, nil
-
-	case string(hook.EventUserPromptSubmit):
-		prompt, _ := inputData["prompt"].(string)
-
-		return &hook.UserPromptSubmitInput{
-			BaseInput:     baseInput,
-			HookEventName: hookEventName,
-			Prompt:        prompt,
-		}, nil
-
-	case string(hook.EventStop):
-		stopHookActive, _ := inputData["stop_hook_active"].(bool)
-
-		return &hook.StopInput{
-			BaseInput:      baseInput,
-			HookEventName:  hookEventName,
-			StopHookActive: stopHookActive,
-		}, nil
-
-	case string(hook.EventSubagentStop):
-		stopHookActive, _ := inputData["stop_hook_active"].(bool)
-		agentID, _ := inputData["agent_id"].(string)
-		agentTranscriptPath, _ := inputData["agent_transcript_path"].(string)
-		agentType, _ := inputData["agent_type"].(string)
-
-		return &hook.SubagentStopInput{
-			BaseInput:           baseInput,
-			HookEventName:       hookEventName,
-			StopHookActive:      stopHookActive,
-			AgentID:             agentID,
-			AgentTranscriptPath: agentTranscriptPath,
-			AgentType:           agentType,
-		}, nil
-
-	case string(hook.EventPreCompact):
-		trigger, _ := inputData["trigger"].(string)
-
-		var customInstructions *string
-		if ci, ok := inputData["custom_instructions"].(string); ok && ci != "" {
-			customInstructions = &ci
-		}
-
-		return &hook.PreCompactInput{
-			BaseInput:          baseInput,
-			HookEventName:      hookEventName,
-			Trigger:            trigger,
-			CustomInstructions: customInstructions,
-		}, nil
-
-	case string(hook.EventPostToolUseFailure):
-		toolName, _ := inputData["tool_name"].(string)
-		toolInput, _ := inputData["tool_input"].(map[string]any)
-		toolUseID, _ := inputData["tool_use_id"].(string)
-		toolError, _ := inputData["error"].(string)
-
-		var isInterrupt *bool
-		if v, ok := inputData["is_interrupt"].(bool); ok {
-			isInterrupt = &v
-		}
-
-		return &hook.PostToolUseFailureInput{
-			BaseInput:     baseInput,
-			HookEventName: hookEventName,
-			ToolName:      toolName,
-			ToolInput:     toolInput,
-			ToolUseID:     toolUseID,
-			Error:         toolError,
-			IsInterrupt:   isInterrupt,
-		}, nil
-
-	case string(hook.EventNotification):
-		msg, _ := inputData["message"].(string)
-		notificationType, _ := inputData["notification_type"].(string)
-
-		var title *string
-		if t, ok := inputData["title"].(string); ok && t != "" {
-			title = &t
-		}
-
-		return &hook.NotificationInput{
-			BaseInput:        baseInput,
-			HookEventName:    hookEventName,
-			Message:          msg,
-			Title:            title,
-			NotificationType: notificationType,
-		}, nil
-
-	case string(hook.EventSubagentStart):
-		agentID, _ := inputData["agent_id"].(string)
-		agentType, _ := inputData["agent_type"].(string)
-
-		return &hook.SubagentStartInput{
-			BaseInput:     baseInput,
-			HookEventName: hookEventName,
-			AgentID:       agentID,
-			AgentType:     agentType,
-		}, nil
-
-	case string(hook.EventPermissionRequest):
-		toolName, _ := inputData["tool_name"].(string)
-		toolInput, _ := inputData["tool_input"].(map[string]any)
+	s.recorder().Observe(telemetry.MetricHookDuration, time.Since(start).Seconds(),
+		telemetry.Label{Name: "event", Value: event},
+		telemetry.Label{Name: "outcome", Value: outcome},
+	)
+	telemetry.End(span, err)
 
-		var permissionSuggestions []any
-		if ps, ok := inputData["permission_suggestions"].([]any); ok {
-			permissionSuggestions = ps
-		}
-
-		return &hook.PermissionRequestInput{
-			BaseInput:             baseInput,
-			HookEventName:         hookEventName,
-			ToolName:              toolName,
-			ToolInput:             toolInput,
-			PermissionSuggestions: permissionSuggestions,
-		}, nil
-
-	default:
-		// Unknown event type - return a generic stop input as fallback
-		return &hook.StopInput{
-			BaseInput:      baseInput,
-			HookEventName:  hookEventName,
-			StopHookActive: false,
-		}, nil
-	}
+	return result, err
 }
 
-// convertHookOutput converts a HookJSONOutput to a response map for the CLI.
-func (s *Session) convertHookOutput(output hook.JSONOutput) (map[string]any, error) {
-	if output == nil {
-		// Default: continue with no special output
-		return map[string]any{
-			"continue": true,
-		}, nil
+// recorder returns the controller's telemetry recorder, if any.
+func (s *Session) recorder() *telemetry.Recorder {
+	if s.controller == nil {
+		return nil
 	}
 
-	switch o := output.(type) {
-	case *hook.SyncJSONOutput:
-		result := make(map[string]any, 8)
-
-		if o.Continue != nil {
-			result["continue"] = *o.Continue
-		} else {
-			result["continue"] = true
-		}
-
-		if o.SuppressOutput != nil {
-			result["suppressOutput"] = *o.SuppressOutput
-		}
-
-		if o.StopReason != nil {
-			result["stopReason"] = *o.StopReason
-		}
-
-		if o.Decision != nil {
-			result["decision"] = *o.Decision
-		}
-
-		if o.SystemMessage != nil {
-			result["systemMessage"] = *o.SystemMessage
-		}
-
-		if o.Reason != nil {
-			result["reason"] = *o.Reason
-		}
-
-		if o.HookSpecificOutput != nil {
-			result["hookSpecificOutput"] = o.HookSpecificOutput
-		}
-
-		return result, nil
-
-	case *hook.AsyncJSONOutput:
-		return map[string]any{
-			"async":        o.Async,
-			"asyncTimeout": o.AsyncTimeout,
-		}, nil
-
-	default:
-		return map[string]any{
-			"continue": true,
-		}, nil
-	}
+	return s.controller.Recorder()
 }
 
 // HandleMCPMessage handles unified mcp_message control requests from the CLI.
@@ -559,7 +388,21 @@ func (s *Session) HandleMCPMessage(
 		return s.handleMCPToolsList(msgID, server)
 
 	case "tools/call":
-		return s.handleMCPToolsCall(ctx, msgID, params, server)
+		toolName, _ := params["name"].(string)
+
+		ctx, span := s.recorder().Start(ctx, "claude.mcp "+serverName+"/"+toolName,
+			attribute.String(telemetry.AttrMCPServer, serverName),
+			attribute.String(telemetry.AttrToolName, toolName),
+		)
+
+		resp, err := s.handleMCPToolsCall(ctx, msgID, params, server)
+		if mcpResponse, ok := resp["mcp_response"].(map[string]any); ok && mcpResponse["error"] != nil {
+			span.SetStatus(codes.Error, "tools/call failed")
+		}
+
+		telemetry.End(span, err)
+
+		return resp, err
 
 	default:
 		return s.mcpErrorResponse(msgID, -32601, fmt.Sprintf("Method not found: %s", method)), nil
@@ -716,11 +559,35 @@ func (s *Session) HandleCanUseTool(
 		Suggestions: suggestions,
 	}
 
-	decision, err := s.options.CanUseTool(ctx, toolName, input, permCtx)
+	callback := s.permissions.Wrap(s.options.CanUseTool)
+
+	ctx, span := s.recorder().Start(ctx, "claude.permission "+toolName,
+		attribute.String(telemetry.AttrToolName, toolName))
+
+	decision, err := callback(ctx, toolName, input, permCtx)
 	if err != nil {
+		telemetry.End(span, err)
+
 		return nil, err
 	}
 
+	// Scope hints only matter to a cache; unwrap them if none is configured
+	result, err := permissionResponse(permission.Unwrap(decision))
+	if behavior, ok := result["behavior"].(string); ok {
+		span.SetAttributes(attribute.String(telemetry.AttrPermissionDecision, behavior))
+		s.recorder().Add(telemetry.MetricPermissionDecisions, 1,
+			telemetry.Label{Name: "tool", Value: toolName},
+			telemetry.Label{Name: "behavior", Value: behavior},
+		)
+	}
+
+	telemetry.End(span, err)
+
+	return result, err
+}
+
+// permissionResponse converts a permission decision to its CLI response.
+func permissionResponse(decision permission.Result) (map[string]any, error) {
 	// Type assert to access fields based on the concrete type
 	switch d := decision.(type) {
 	case *permission.ResultAllow:
@@ -1,6 +1,8 @@
 package protocol
 
 import (
+	"context"
+	"encoding/json"
 	"log/slog"
 	"sync"
 	"testing"
@@ -9,6 +11,7 @@ import (
 	"github.com/wagiedev/claude-agent-sdk-go/internal/config"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/hook"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/mcp"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/permission"
 )
 
 // TestSession_NeedsInitialization_WithAgents tests that NeedsInitialization returns true
@@ -146,3 +149,187 @@ func TestSession_InitializationResult_ConcurrentReadWrite(t *testing.T) {
 
 	wg.Wait()
 }
+
+// TestSession_HandleCanUseTool_PermissionCache tests that a configured permission
+// cache short-circuits repeated CanUseTool callbacks.
+func TestSession_HandleCanUseTool_PermissionCache(t *testing.T) {
+	cache, err := permission.NewCache(nil)
+	require.NoError(t, err)
+
+	var calls int
+
+	session := &Session{
+		log: slog.Default(),
+		options: &config.Options{
+			CanUseTool: func(
+				_ context.Context,
+				_ string,
+				_ map[string]any,
+				_ *permission.Context,
+			) (permission.Result, error) {
+				calls++
+
+				return permission.Remember(&permission.ResultAllow{Behavior: "allow"}, permission.ScopeSession), nil
+			},
+			PermissionCache: cache,
+		},
+		hookCallbacks: make(map[string]hook.Callback, 16),
+		sdkMcpServers: make(map[string]mcp.ServerInstance, 4),
+		permissions:   cache.Session(),
+	}
+
+	for _, file := range []string{"/repo/a.go", "/repo/b.go"} {
+		resp, err := session.HandleCanUseTool(context.Background(), &ControlRequest{
+			Request: map[string]any{
+				"subtype":   "can_use_tool",
+				"tool_name": "Read",
+				"input":     map[string]any{"file_path": file},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"behavior": "allow"}, resp)
+	}
+
+	require.Equal(t, 1, calls)
+}
+
+// TestSession_HookPredicate tests that matcher predicates are evaluated on the
+// SDK side before hook callbacks run.
+func TestSession_HookPredicate(t *testing.T) {
+	var calls int
+
+	matcher := hook.MustMatchRegex("mcp__.*", hook.OnPreToolUse(
+		func(context.Context, *hook.PreToolUseInput) (hook.PreToolUseDecision, error) {
+			calls++
+
+			return hook.PreToolUseDecision{Permission: hook.PermissionDeny}, nil
+		},
+	))
+
+	session := &Session{
+		log: slog.Default(),
+		options: &config.Options{
+			Hooks: map[hook.Event][]*hook.Matcher{hook.EventPreToolUse: {matcher}},
+		},
+		hookCallbacks: make(map[string]hook.Callback, 16),
+		sdkMcpServers: make(map[string]mcp.ServerInstance, 4),
+	}
+
+	hooksConfig := session.registerHooks()
+	require.Len(t, hooksConfig[string(hook.EventPreToolUse)], 1)
+
+	call := func(toolName string) map[string]any {
+		resp, err := session.HandleHookCallback(context.Background(), &ControlRequest{
+			Request: map[string]any{
+				"callback_id": "hook_0",
+				"input": map[string]any{
+					"hook_event_name": "PreToolUse",
+					"tool_name":       toolName,
+					"tool_input":      map[string]any{},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		return resp
+	}
+
+	require.Equal(t, map[string]any{"continue": true}, call("Bash"))
+	require.Equal(t, 0, calls)
+
+	resp := call("mcp__github__create_issue")
+	require.Equal(t, 1, calls)
+
+	specific, ok := resp["hookSpecificOutput"].(*hook.PreToolUseSpecificOutput)
+	require.True(t, ok)
+	require.Equal(t, "deny", *specific.PermissionDecision)
+}
+
+// TestSession_HookRegistry tests that registry events are registered as
+// catch-all matchers and pick up hooks added after initialization.
+func TestSession_HookRegistry(t *testing.T) {
+	registry := hook.NewRegistry(hook.EventPreToolUse)
+
+	session := &Session{
+		log: slog.Default(),
+		options: &config.Options{
+			HookRegistry: registry,
+		},
+		hookCallbacks: make(map[string]hook.Callback, 16),
+		sdkMcpServers: make(map[string]mcp.ServerInstance, 4),
+	}
+
+	require.True(t, session.NeedsInitialization())
+
+	hooksConfig := session.registerHooks()
+	require.Equal(t, []map[string]any{{
+		"matcher":         nil,
+		"hookCallbackIds": []string{"hook_0"},
+	}}, hooksConfig[string(hook.EventPreToolUse)])
+
+	req := &ControlRequest{
+		Request: map[string]any{
+			"callback_id": "hook_0",
+			"input": map[string]any{
+				"hook_event_name": "PreToolUse",
+				"tool_name":       "Bash",
+				"tool_input":      map[string]any{},
+			},
+		},
+	}
+
+	resp, err := session.HandleHookCallback(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"continue": true}, resp)
+
+	_, err = registry.Add(hook.EventPreToolUse, &hook.Matcher{
+		Hooks: []hook.Callback{hook.OnPreToolUse(
+			func(context.Context, *hook.PreToolUseInput) (hook.PreToolUseDecision, error) {
+				return hook.PreToolUseDecision{Outcome: hook.Outcome{Stop: true}}, nil
+			},
+		)},
+	})
+	require.NoError(t, err)
+
+	resp, err = session.HandleHookCallback(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, false, resp["continue"])
+}
+
+// TestSession_HandleHookCallback_SessionStartContext tests that SessionStart
+// hooks can inject additional context.
+func TestSession_HandleHookCallback_SessionStartContext(t *testing.T) {
+	session := &Session{
+		log: slog.Default(),
+		options: &config.Options{
+			Hooks: map[hook.Event][]*hook.Matcher{
+				hook.EventSessionStart: {{
+					Hooks: []hook.Callback{hook.OnSessionStart(
+						func(_ context.Context, in *hook.SessionStartInput) (hook.ContextDecision, error) {
+							return hook.ContextDecision{AdditionalContext: "tenant=acme source=" + in.Source}, nil
+						},
+					)},
+				}},
+			},
+		},
+		hookCallbacks: make(map[string]hook.Callback, 16),
+		sdkMcpServers: make(map[string]mcp.ServerInstance, 4),
+	}
+
+	session.registerHooks()
+
+	resp, err := session.HandleHookCallback(context.Background(), &ControlRequest{
+		Request: map[string]any{
+			"callback_id": "hook_0",
+			"input":       map[string]any{"hook_event_name": "SessionStart", "source": "startup"},
+		},
+	})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(resp)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"continue": true,
+		"hookSpecificOutput": {"hookEventName": "SessionStart", "additionalContext": "tenant=acme source=startup"}
+	}`, string(data))
+}
@@ -0,0 +1,195 @@
+package ratelimit
+
+import (
+	stderrors "errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/errors"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/message"
+)
+
+const (
+	// DefaultFailureThreshold is the number of consecutive failures that open
+	// a Breaker when BreakerConfig.FailureThreshold is zero.
+	DefaultFailureThreshold = 5
+
+	// DefaultCooldown is how long a Breaker stays open when
+	// BreakerConfig.Cooldown is zero.
+	DefaultCooldown = 30 * time.Second
+)
+
+// BreakerConfig configures a Breaker.
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that open the
+	// circuit. Defaults to DefaultFailureThreshold.
+	FailureThreshold int
+	// Cooldown is how long the circuit stays open before letting a probe run
+	// through. Defaults to DefaultCooldown.
+	Cooldown time.Duration
+}
+
+// BreakerState is the state of a Breaker.
+type BreakerState int
+
+const (
+	// BreakerClosed lets every run through.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects runs until the cooldown has passed.
+	BreakerOpen
+	// BreakerHalfOpen lets one probe run through; its outcome closes or
+	// reopens the circuit.
+	BreakerHalfOpen
+)
+
+// String returns the state's name.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+// Breaker is a circuit breaker shared by many Query and Client runs. It opens
+// after repeated rate limit errors, server errors or CLI process failures,
+// rejects new runs with ErrCircuitOpen during a cooldown, and then half-opens
+// to let a single probe run through. A successful result closes it again.
+//
+// A Breaker is safe for concurrent use.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probeAt  time.Time
+	probing  bool
+}
+
+// NewBreaker creates a closed Breaker.
+func NewBreaker(cfg BreakerConfig) *Breaker {
+	b := &Breaker{
+		threshold: cfg.FailureThreshold,
+		cooldown:  cfg.Cooldown,
+		now:       time.Now,
+	}
+
+	if b.threshold <= 0 {
+		b.threshold = DefaultFailureThreshold
+	}
+
+	if b.cooldown <= 0 {
+		b.cooldown = DefaultCooldown
+	}
+
+	return b
+}
+
+// State returns the current state. An open Breaker whose cooldown has passed
+// reports BreakerHalfOpen.
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		return BreakerHalfOpen
+	}
+
+	return b.state
+}
+
+// Allow reports whether a run may start. It returns ErrCircuitOpen while the
+// circuit is open, or while a half-open probe is in flight. A nil Breaker
+// allows every run.
+func (b *Breaker) Allow() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+
+	if b.state == BreakerOpen {
+		if wait := b.cooldown - now.Sub(b.openedAt); wait > 0 {
+			return fmt.Errorf("%w: retry in %s", errors.ErrCircuitOpen, wait.Round(time.Second))
+		}
+
+		b.state = BreakerHalfOpen
+	}
+
+	if b.state == BreakerHalfOpen {
+		// A probe that never reported is given up on after a cooldown
+		if b.probing && now.Sub(b.probeAt) < b.cooldown {
+			return fmt.Errorf("%w: probe in flight", errors.ErrCircuitOpen)
+		}
+
+		b.probing, b.probeAt = true, now
+	}
+
+	return nil
+}
+
+// Success records a successful run, closing the circuit.
+func (b *Breaker) Success() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state, b.failures, b.probing = BreakerClosed, 0, false
+}
+
+// Failure records a failed run. The circuit opens once failures reach the
+// threshold in a row, or at once if a half-open probe failed.
+func (b *Breaker) Failure() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+
+	if b.state != BreakerClosed || b.failures >= b.threshold {
+		b.state, b.openedAt, b.probing = BreakerOpen, b.now(), false
+	}
+}
+
+// Observe records the outcome carried by a message: an assistant message with
+// a rate limit or server error is a failure, and a result that is not an
+// error is a success.
+func (b *Breaker) Observe(msg message.Message) {
+	switch m := msg.(type) {
+	case *message.AssistantMessage:
+		if m.Error != nil && (*m.Error == message.AssistantMessageErrorRateLimit ||
+			*m.Error == message.AssistantMessageErrorServer) {
+			b.Failure()
+		}
+	case *message.ResultMessage:
+		if !m.IsError {
+			b.Success()
+		}
+	}
+}
+
+// Error records a run error; CLI process failures count as failures.
+func (b *Breaker) Error(err error) {
+	if _, ok := stderrors.AsType[*errors.ProcessError](err); ok {
+		b.Failure()
+	}
+}
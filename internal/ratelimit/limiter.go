@@ -0,0 +1,219 @@
+// Package ratelimit paces CLI launches and stops them while the API is
+// failing.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/message"
+)
+
+// window is the period requests and tokens are counted over.
+const window = time.Minute
+
+// LimiterConfig sets the limits of a Limiter. A zero field is unlimited.
+type LimiterConfig struct {
+	// RequestsPerMinute caps how many runs may start in any one-minute window.
+	RequestsPerMinute int
+	// TokensPerMinute holds back new runs while the tokens used by runs in the
+	// last minute reach this many. Input, output and cache write tokens count.
+	TokensPerMinute int
+	// MaxConcurrent caps how many runs may have a CLI process at once.
+	MaxConcurrent int
+}
+
+type tokenEvent struct {
+	at     time.Time
+	tokens int
+}
+
+// Limiter admits runs within request, token and concurrency limits shared by
+// many Query and Client runs. Runs wait for a slot rather than fail.
+//
+// A Limiter is safe for concurrent use.
+type Limiter struct {
+	cfg LimiterConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	requests []time.Time
+	tokens   []tokenEvent
+	active   int
+	changed  chan struct{}
+}
+
+// NewLimiter creates a Limiter with the given limits.
+func NewLimiter(cfg LimiterConfig) *Limiter {
+	return &Limiter{cfg: cfg, now: time.Now, changed: make(chan struct{})}
+}
+
+// Acquire waits until a run may start and returns its Permit. It fails only
+// when ctx is done. A nil Limiter admits every run and returns a nil Permit,
+// whose methods do nothing.
+func (l *Limiter) Acquire(ctx context.Context) (*Permit, error) {
+	if l == nil {
+		return nil, nil //nolint:nilnil // a nil Permit is the no-op permit
+	}
+
+	for {
+		l.mu.Lock()
+
+		now := l.now()
+
+		wait, ok := l.admitLocked(now)
+		if ok {
+			l.requests = append(l.requests, now)
+			l.active++
+			l.mu.Unlock()
+
+			return &Permit{limiter: l, seen: make(map[string]struct{}, 16)}, nil
+		}
+
+		changed := l.changed
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, fmt.Errorf("wait for rate limiter: %w", context.Cause(ctx))
+		case <-timer.C:
+		case <-changed:
+			timer.Stop()
+		}
+	}
+}
+
+// Active returns the number of runs holding a permit.
+func (l *Limiter) Active() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.active
+}
+
+// admitLocked reports whether a run may start now, or else how long to wait
+// before checking again. Caller must hold l.mu.
+func (l *Limiter) admitLocked(now time.Time) (time.Duration, bool) {
+	cutoff := now.Add(-window)
+
+	for len(l.requests) > 0 && !l.requests[0].After(cutoff) {
+		l.requests = l.requests[1:]
+	}
+
+	for len(l.tokens) > 0 && !l.tokens[0].at.After(cutoff) {
+		l.tokens = l.tokens[1:]
+	}
+
+	var wait time.Duration
+
+	if l.cfg.MaxConcurrent > 0 && l.active >= l.cfg.MaxConcurrent {
+		// Woken early by Release
+		wait = window
+	}
+
+	if l.cfg.RequestsPerMinute > 0 && len(l.requests) >= l.cfg.RequestsPerMinute {
+		oldest := l.requests[len(l.requests)-l.cfg.RequestsPerMinute]
+		wait = max(wait, oldest.Sub(cutoff))
+	}
+
+	if l.cfg.TokensPerMinute > 0 {
+		used := 0
+		for _, e := range l.tokens {
+			used += e.tokens
+		}
+
+		// Wait until enough of the oldest usage leaves the window
+		for _, e := range l.tokens {
+			if used < l.cfg.TokensPerMinute {
+				break
+			}
+
+			used -= e.tokens
+			wait = max(wait, e.at.Sub(cutoff))
+		}
+	}
+
+	return wait, wait == 0
+}
+
+// record adds tokens used now.
+func (l *Limiter) record(tokens int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens = append(l.tokens, tokenEvent{at: l.now(), tokens: tokens})
+}
+
+// release frees a concurrency slot and wakes waiting runs.
+func (l *Limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.active--
+
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// Permit is a run admitted by a Limiter.
+type Permit struct {
+	limiter *Limiter
+
+	mu       sync.Mutex
+	seen     map[string]struct{}
+	released bool
+}
+
+// Observe records the tokens of an assistant message against the limiter.
+// Assistant messages that share an API message ID are counted once.
+func (p *Permit) Observe(msg message.Message) {
+	if p == nil {
+		return
+	}
+
+	m, ok := msg.(*message.AssistantMessage)
+	if !ok || m.Usage == nil {
+		return
+	}
+
+	p.mu.Lock()
+
+	if m.ID != "" {
+		if _, dup := p.seen[m.ID]; dup {
+			p.mu.Unlock()
+
+			return
+		}
+
+		p.seen[m.ID] = struct{}{}
+	}
+
+	p.mu.Unlock()
+
+	tokens := m.Usage.InputTokens + m.Usage.OutputTokens + m.Usage.CacheCreationInputTokens
+	if tokens > 0 {
+		p.limiter.record(tokens)
+	}
+}
+
+// Release frees the run's concurrency slot. It is safe to call more than once.
+func (p *Permit) Release() {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	released := p.released
+	p.released = true
+	p.mu.Unlock()
+
+	if !released {
+		p.limiter.release()
+	}
+}
@@ -0,0 +1,154 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/errors"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/message"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func newClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func TestLimiter_Concurrency(t *testing.T) {
+	l := NewLimiter(LimiterConfig{MaxConcurrent: 1})
+
+	first, err := l.Acquire(t.Context())
+	require.NoError(t, err)
+
+	acquired := make(chan *Permit)
+
+	go func() {
+		p, err := l.Acquire(t.Context())
+		assert.NoError(t, err)
+		acquired <- p
+	}()
+
+	select {
+	case <-acquired:
+		require.FailNow(t, "second run must wait for a slot")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	first.Release()
+	first.Release()
+
+	select {
+	case second := <-acquired:
+		assert.Equal(t, 1, l.Active())
+		second.Release()
+	case <-time.After(time.Second):
+		require.FailNow(t, "release must wake waiting runs")
+	}
+
+	assert.Equal(t, 0, l.Active())
+}
+
+func TestLimiter_RequestAndTokenWindows(t *testing.T) {
+	clock := newClock()
+	l := NewLimiter(LimiterConfig{RequestsPerMinute: 2, TokensPerMinute: 1000})
+	l.now = clock.Now
+
+	for range 2 {
+		p, err := l.Acquire(t.Context())
+		require.NoError(t, err)
+		p.Release()
+	}
+
+	wait, ok := l.admitLocked(clock.Now())
+	require.False(t, ok)
+	assert.Equal(t, time.Minute, wait)
+
+	clock.Advance(time.Minute)
+
+	p, err := l.Acquire(t.Context())
+	require.NoError(t, err)
+
+	usage := &message.Usage{InputTokens: 600, OutputTokens: 300, CacheCreationInputTokens: 100}
+	p.Observe(&message.AssistantMessage{ID: "msg_1", Usage: usage})
+	p.Observe(&message.AssistantMessage{ID: "msg_1", Usage: usage})
+	p.Release()
+
+	clock.Advance(30 * time.Second)
+
+	wait, ok = l.admitLocked(clock.Now())
+	require.False(t, ok, "a full token window holds back new runs")
+	assert.Equal(t, 30*time.Second, wait)
+
+	clock.Advance(30 * time.Second)
+
+	_, ok = l.admitLocked(clock.Now())
+	assert.True(t, ok)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	blocked := NewLimiter(LimiterConfig{MaxConcurrent: 1})
+	_, err = blocked.Acquire(t.Context())
+	require.NoError(t, err)
+	_, err = blocked.Acquire(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestBreaker(t *testing.T) {
+	clock := newClock()
+	b := NewBreaker(BreakerConfig{FailureThreshold: 2, Cooldown: time.Minute})
+	b.now = clock.Now
+
+	rateLimited := new(message.AssistantMessageErrorRateLimit)
+
+	b.Observe(&message.AssistantMessage{Error: rateLimited})
+	require.NoError(t, b.Allow())
+	assert.Equal(t, BreakerClosed, b.State())
+
+	b.Error(&errors.ProcessError{ExitCode: 1})
+	assert.Equal(t, BreakerOpen, b.State())
+	require.ErrorIs(t, b.Allow(), errors.ErrCircuitOpen)
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, BreakerHalfOpen, b.State())
+	require.NoError(t, b.Allow(), "the first run after the cooldown is a probe")
+	require.ErrorIs(t, b.Allow(), errors.ErrCircuitOpen, "only one probe at a time")
+
+	b.Observe(&message.AssistantMessage{Error: new(message.AssistantMessageErrorServer)})
+	assert.Equal(t, BreakerOpen, b.State(), "a failed probe reopens the circuit")
+
+	clock.Advance(time.Minute)
+	require.NoError(t, b.Allow())
+	b.Observe(&message.ResultMessage{})
+	assert.Equal(t, BreakerClosed, b.State())
+
+	b.Error(context.Canceled)
+	b.Observe(&message.AssistantMessage{Error: new(message.AssistantMessageErrorAuthFailed)})
+	assert.Equal(t, BreakerClosed, b.State(), "other errors do not count")
+
+	var nilBreaker *Breaker
+	require.NoError(t, nilBreaker.Allow())
+	nilBreaker.Failure()
+}
@@ -0,0 +1,255 @@
+// Package retry classifies run failures and retries queries that hit
+// transient ones.
+package retry
+
+import (
+	stderrors "errors"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/errors"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/message"
+)
+
+// Result subtypes the CLI reports when a run stops at a limit.
+const (
+	resultMaxTurns  = "error_max_turns"
+	resultMaxBudget = "error_max_budget_usd"
+)
+
+// textRule maps CLI output to a failure class.
+type textRule struct {
+	pattern   *regexp.Regexp
+	class     error
+	retryable bool
+}
+
+// apiError starts the line the CLI prints for a failed API request, as in
+// "API Error: 529 {...}". Statuses and keywords only count on such lines, or
+// in the CLI's own messages below, so that a tool's output such as
+// "read 500 bytes" is not taken for an API failure.
+const apiError = `API Error:[^\n]*?`
+
+// textRules are checked in order against stderr and error text. Credential
+// and billing failures come first: they are not retryable even when the
+// text also mentions a status that is.
+var textRules = []textRule{
+	{
+		pattern: regexp.MustCompile(`(?i)` + apiError + `(\b401\b|authentication_error|unauthorized)` +
+			`|invalid (x-)?api[ -]key|oauth token (has expired|revoked)|please run /login`),
+		class: errors.ErrAuth,
+	},
+	{
+		pattern: regexp.MustCompile(`(?i)` + apiError + `(\b402\b|billing_error)|credit balance is too low`),
+		class:   errors.ErrBilling,
+	},
+	{
+		pattern:   regexp.MustCompile(`(?i)` + apiError + `(\b429\b|rate[ _-]?limit)|too many requests`),
+		class:     errors.ErrRateLimited,
+		retryable: true,
+	},
+	{
+		pattern: regexp.MustCompile(`(?i)` + apiError +
+			`(\b(500|502|503|504|529)\b|overloaded|internal server error|service unavailable)`),
+		class:     errors.ErrOverloaded,
+		retryable: true,
+	},
+}
+
+// killedExitCodes are the exit codes of a CLI process killed by a signal:
+// -1 as Go reports it, and 128+SIGKILL and 128+SIGTERM as shells report it.
+// The CLI itself exits 1 for every failure, including credential and usage
+// errors, so no other exit code says more than its stderr.
+var killedExitCodes = []int{-1, 137, 143}
+
+// retryAfterPattern finds a wait hint such as "retry-after: 30" or
+// "try again in 2.5 seconds".
+var retryAfterPattern = regexp.MustCompile(
+	`(?i)(?:retry[- ]after|try again in)\D{0,3}(\d+(?:\.\d+)?)\s*(ms|milliseconds?|s|secs?|seconds?|m|mins?|minutes?)?\b`,
+)
+
+// Classify sorts err into a failure class. CLI process failures are classified
+// from their stderr, or, when it matches no class, from their exit code: a
+// process killed by a signal is ErrKilled, which is not retryable. The result
+// wraps err, so errors.As still finds the *ProcessError. Errors that match no
+// class, and errors that are already classified, are returned unchanged.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := stderrors.AsType[*errors.ClassifiedError](err); ok {
+		return err
+	}
+
+	processErr, ok := stderrors.AsType[*errors.ProcessError](err)
+	if !ok {
+		return err
+	}
+
+	text := processErr.Stderr
+	if processErr.Err != nil {
+		text += "\n" + processErr.Err.Error()
+	}
+
+	classified := classifyText(text)
+	if classified == nil && slices.Contains(killedExitCodes, processErr.ExitCode) {
+		classified = &errors.ClassifiedError{Class: errors.ErrKilled, Detail: processErr.Error()}
+	}
+
+	if classified == nil {
+		return err
+	}
+
+	classified.Err = err
+
+	return classified
+}
+
+// ClassifyMessage returns the failure a message reports, or nil if it
+// reports none or the failure matches no class. Assistant messages report
+// API errors; result messages report limits and failed runs. lastErr is the
+// failure of an earlier assistant message in the same run, which a failed
+// result without details of its own is attributed to; it may be nil.
+func ClassifyMessage(msg message.Message, lastErr error) error {
+	switch m := msg.(type) {
+	case *message.AssistantMessage:
+		if m.Error == nil {
+			return nil
+		}
+
+		return classifyAssistant(m)
+	case *message.ResultMessage:
+		if !m.IsError {
+			return nil
+		}
+
+		return classifyResult(m, lastErr)
+	default:
+		return nil
+	}
+}
+
+func classifyAssistant(m *message.AssistantMessage) error {
+	text := messageText(m)
+
+	var classified *errors.ClassifiedError
+
+	switch *m.Error {
+	case message.AssistantMessageErrorRateLimit:
+		classified = &errors.ClassifiedError{Class: errors.ErrRateLimited, Retryable: true}
+	case message.AssistantMessageErrorServer:
+		classified = &errors.ClassifiedError{Class: errors.ErrOverloaded, Retryable: true}
+	case message.AssistantMessageErrorAuthFailed:
+		classified = &errors.ClassifiedError{Class: errors.ErrAuth}
+	case message.AssistantMessageErrorBilling:
+		classified = &errors.ClassifiedError{Class: errors.ErrBilling}
+	default:
+		if classified = classifyText(text); classified == nil {
+			return nil
+		}
+	}
+
+	classified.Detail = text
+	classified.RetryAfter = parseRetryAfter(text)
+
+	return classified
+}
+
+func classifyResult(m *message.ResultMessage, lastErr error) error {
+	detail := m.Subtype
+	if m.Result != nil && *m.Result != "" {
+		detail = *m.Result
+	}
+
+	switch m.Subtype {
+	case resultMaxTurns:
+		return &errors.ClassifiedError{Class: errors.ErrMaxTurns, Detail: detail}
+	case resultMaxBudget:
+		return &errors.ClassifiedError{Class: errors.ErrBudgetExceeded, Detail: detail}
+	}
+
+	if m.Result != nil {
+		if classified := classifyText(*m.Result); classified != nil {
+			classified.Detail = detail
+
+			return classified
+		}
+	}
+
+	return lastErr
+}
+
+// classifyText matches CLI error text against textRules.
+func classifyText(text string) *errors.ClassifiedError {
+	for _, rule := range textRules {
+		if rule.pattern.MatchString(text) {
+			return &errors.ClassifiedError{
+				Class:      rule.class,
+				Retryable:  rule.retryable,
+				RetryAfter: parseRetryAfter(text),
+				Detail:     strings.TrimSpace(text),
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseRetryAfter returns the wait hint in text, or zero if there is none.
+// Bare numbers are seconds.
+func parseRetryAfter(text string) time.Duration {
+	match := retryAfterPattern.FindStringSubmatch(text)
+	if match == nil {
+		return 0
+	}
+
+	value, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0
+	}
+
+	unit, suffix := time.Second, strings.ToLower(match[2])
+
+	switch {
+	case suffix == "ms" || strings.HasPrefix(suffix, "milli"):
+		unit = time.Millisecond
+	case strings.HasPrefix(suffix, "m"):
+		unit = time.Minute
+	}
+
+	return time.Duration(value * float64(unit))
+}
+
+// messageText joins the text blocks of an assistant message.
+func messageText(m *message.AssistantMessage) string {
+	var parts []string
+
+	for _, block := range m.Content {
+		if text, ok := block.(*message.TextBlock); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+
+	return strings.Join(parts, "\n")
+}
+
+// IsRetryable reports whether err is a classified failure that retrying may
+// fix.
+func IsRetryable(err error) bool {
+	classified, ok := stderrors.AsType[*errors.ClassifiedError](err)
+
+	return ok && classified.Retryable
+}
+
+// RetryAfter returns the wait hint of a classified failure, or zero.
+func RetryAfter(err error) time.Duration {
+	if classified, ok := stderrors.AsType[*errors.ClassifiedError](err); ok {
+		return classified.RetryAfter
+	}
+
+	return 0
+}
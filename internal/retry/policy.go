@@ -0,0 +1,102 @@
+package retry
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Policy defaults applied to zero fields.
+const (
+	DefaultMaxAttempts    = 3
+	DefaultInitialBackoff = time.Second
+	DefaultMaxBackoff     = time.Minute
+	DefaultMultiplier     = 2.0
+	DefaultJitter         = 0.2
+	DefaultResumePrompt   = "Continue from where you left off."
+)
+
+// Policy controls how a query is retried after a retryable failure.
+// Zero fields take the Default values.
+type Policy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts, except that a longer
+	// retry-after hint from the API is always honored.
+	MaxBackoff time.Duration
+	// Multiplier grows the delay after each retry.
+	Multiplier float64
+	// Jitter randomizes each delay by up to this fraction, so parallel
+	// queries do not retry in lockstep. Negative disables jitter.
+	Jitter float64
+	// ResumePrompt is sent when a retry resumes the failed attempt's session
+	// instead of starting over.
+	ResumePrompt string
+	// ShouldRetry overrides which failures are retried. By default,
+	// classified failures marked retryable are.
+	ShouldRetry func(err error) bool
+}
+
+// Attempts returns the total number of attempts allowed.
+func (p *Policy) Attempts() int {
+	if p.MaxAttempts <= 0 {
+		return DefaultMaxAttempts
+	}
+
+	return p.MaxAttempts
+}
+
+// Retryable reports whether err should be retried.
+func (p *Policy) Retryable(err error) bool {
+	if p.ShouldRetry != nil {
+		return p.ShouldRetry(err)
+	}
+
+	return IsRetryable(err)
+}
+
+// Prompt returns the prompt used to resume a session.
+func (p *Policy) Prompt() string {
+	if p.ResumePrompt == "" {
+		return DefaultResumePrompt
+	}
+
+	return p.ResumePrompt
+}
+
+// Delay returns how long to wait before retry number retry (starting at 1)
+// after err: exponential backoff with jitter, or err's retry-after hint if
+// that is longer.
+func (p *Policy) Delay(retry int, err error) time.Duration {
+	initial, maxBackoff, multiplier, jitter := p.InitialBackoff, p.MaxBackoff, p.Multiplier, p.Jitter
+
+	if initial <= 0 {
+		initial = DefaultInitialBackoff
+	}
+
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+
+	if multiplier < 1 {
+		multiplier = DefaultMultiplier
+	}
+
+	if jitter == 0 {
+		jitter = DefaultJitter
+	}
+
+	delay := float64(initial)
+	for range retry - 1 {
+		delay *= multiplier
+	}
+
+	delay = min(delay, float64(maxBackoff))
+
+	if jitter > 0 {
+		delay *= 1 + jitter*(2*rand.Float64()-1) //nolint:gosec // jitter needs no crypto randomness
+	}
+
+	return max(time.Duration(delay), RetryAfter(err))
+}
@@ -0,0 +1,110 @@
+package retry
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/errors"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/message"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		stderr     string
+		class      error
+		retryable  bool
+		retryAfter time.Duration
+	}{
+		{"API Error: 429 rate_limit_error, retry-after: 30", errors.ErrRateLimited, true, 30 * time.Second},
+		{"Too many requests. Try again in 500ms", errors.ErrRateLimited, true, 500 * time.Millisecond},
+		{"API Error: 529 {\"type\":\"overloaded_error\"}", errors.ErrOverloaded, true, 0},
+		{"Invalid API key · Please run /login", errors.ErrAuth, false, 0},
+		{"Your credit balance is too low", errors.ErrBilling, false, 0},
+	}
+
+	for _, tt := range tests {
+		processErr := &errors.ProcessError{ExitCode: 1, Stderr: tt.stderr}
+		err := Classify(fmt.Errorf("run: %w", processErr))
+
+		require.ErrorIs(t, err, tt.class, tt.stderr)
+		require.ErrorIs(t, err, processErr, "classification keeps the process error")
+		assert.Equal(t, tt.retryable, IsRetryable(err), tt.stderr)
+		assert.Equal(t, tt.retryAfter, RetryAfter(err), tt.stderr)
+		assert.Same(t, err, Classify(err), "classifying twice is a no-op")
+	}
+
+	unknown := &errors.ProcessError{ExitCode: 2, Stderr: "segmentation fault"}
+	assert.Same(t, error(unknown), Classify(unknown))
+
+	for _, code := range []int{-1, 137, 143} {
+		killed := &errors.ProcessError{ExitCode: code}
+		err := Classify(killed)
+		require.ErrorIs(t, err, errors.ErrKilled, "exit %d", code)
+		require.ErrorIs(t, err, killed)
+		assert.False(t, IsRetryable(err))
+	}
+
+	exited := &errors.ProcessError{ExitCode: 1}
+	assert.Same(t, error(exited), Classify(exited), "exit 1 alone says nothing about the failure")
+
+	overloaded := &errors.ProcessError{ExitCode: 143, Stderr: "API Error: 529 overloaded"}
+	require.ErrorIs(t, Classify(overloaded), errors.ErrOverloaded, "stderr is more specific than the exit code")
+
+	for _, stderr := range []string{
+		"read 500 bytes from pipe",
+		"see docs/login for setup",
+		"hook failed: authentication helper exited 1",
+		"API Error: Request timed out.\nretrying after 503 ms",
+	} {
+		other := &errors.ProcessError{ExitCode: 1, Stderr: stderr}
+		assert.Same(t, error(other), Classify(other), "only API error lines are classified: %s", stderr)
+	}
+	assert.NoError(t, Classify(nil))
+}
+
+func TestClassifyMessage(t *testing.T) {
+	rateLimited := &message.AssistantMessage{
+		Error:   new(message.AssistantMessageErrorRateLimit),
+		Content: []message.ContentBlock{&message.TextBlock{Text: "API Error: Rate limited. Retry after 2 minutes"}},
+	}
+
+	lastErr := ClassifyMessage(rateLimited, nil)
+	require.ErrorIs(t, lastErr, errors.ErrRateLimited)
+	assert.Equal(t, 2*time.Minute, RetryAfter(lastErr))
+
+	failed := &message.ResultMessage{Subtype: "error_during_execution", IsError: true}
+	assert.Same(t, lastErr, ClassifyMessage(failed, lastErr), "failed results are attributed to the last API error")
+	assert.NoError(t, ClassifyMessage(failed, nil))
+
+	require.ErrorIs(t, ClassifyMessage(&message.ResultMessage{Subtype: "error_max_budget_usd", IsError: true}, nil),
+		errors.ErrBudgetExceeded)
+	require.ErrorIs(t, ClassifyMessage(&message.ResultMessage{IsError: true, Result: new("API Error: 401")}, lastErr),
+		errors.ErrAuth)
+	assert.NoError(t, ClassifyMessage(&message.ResultMessage{}, lastErr))
+	assert.NoError(t, ClassifyMessage(&message.AssistantMessage{}, nil))
+}
+
+func TestPolicyDelay(t *testing.T) {
+	p := &Policy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Jitter: -1}
+
+	assert.Equal(t, time.Second, p.Delay(1, nil))
+	assert.Equal(t, 4*time.Second, p.Delay(3, nil))
+	assert.Equal(t, 5*time.Second, p.Delay(10, nil))
+
+	hinted := &errors.ClassifiedError{Class: errors.ErrRateLimited, RetryAfter: time.Minute}
+	assert.Equal(t, time.Minute, p.Delay(1, hinted), "retry-after hints override the backoff")
+
+	jittered := &Policy{InitialBackoff: time.Second, Jitter: 0.5}
+	for range 20 {
+		d := jittered.Delay(1, nil)
+		assert.GreaterOrEqual(t, d, 500*time.Millisecond)
+		assert.LessOrEqual(t, d, 1500*time.Millisecond)
+	}
+
+	assert.Equal(t, DefaultMaxAttempts, (&Policy{}).Attempts())
+	assert.Equal(t, DefaultResumePrompt, (&Policy{}).Prompt())
+}
@@ -0,0 +1,425 @@
+// Package subagent reconstructs the tree of subagents from the flat message
+// stream, using the ParentToolUseID the CLI sets on subagent messages.
+package subagent
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/message"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/models"
+)
+
+// Status is the state of a subagent.
+type Status string
+
+const (
+	// StatusRunning means the subagent's Task call has no result yet.
+	StatusRunning Status = "running"
+	// StatusCompleted means the Task call returned a result.
+	StatusCompleted Status = "completed"
+	// StatusFailed means the Task call returned an error, or the run ended
+	// before it returned.
+	StatusFailed Status = "failed"
+)
+
+// EventType identifies a change in the tree.
+type EventType string
+
+const (
+	// EventStarted is sent when a subagent's Task call is seen.
+	EventStarted EventType = "started"
+	// EventMessage is sent for each message a subagent produces.
+	EventMessage EventType = "message"
+	// EventCompleted is sent when a subagent's Task call returns a result.
+	EventCompleted EventType = "completed"
+	// EventFailed is sent when a subagent fails.
+	EventFailed EventType = "failed"
+)
+
+// subagentTools start subagents.
+var subagentTools = map[string]struct{}{"Task": {}, "Agent": {}}
+
+// Agent is a snapshot of one subagent.
+type Agent struct {
+	// ID is the ID of the Task tool call that started the subagent, and the
+	// ParentToolUseID of its messages.
+	ID string
+	// ParentID is the ID of the subagent that started this one, or "" if the
+	// main agent did.
+	ParentID string
+	// Type, Description and Prompt are the Task call's inputs.
+	Type        string
+	Description string
+	Prompt      string
+	Status      Status
+	// Model is the model of the subagent's last response.
+	Model string
+	// Usage and Cost cover the subagent's own API messages, not those of the
+	// subagents it started. Cost is estimated at the current list prices.
+	Usage message.Usage
+	Cost  models.Cost
+	// Messages counts the messages the subagent produced.
+	Messages int
+	// Result is the text the Task call returned.
+	Result  string
+	Started time.Time
+	// Ended is zero while the subagent runs.
+	Ended time.Time
+	// Children are the IDs of the subagents this one started, in start order.
+	Children []string
+}
+
+// Event reports a change in the tree.
+type Event struct {
+	Type EventType
+	// Agent is the subagent after the change.
+	Agent Agent
+	// Message is the subagent's message, for EventMessage.
+	Message message.Message
+}
+
+type node struct {
+	agent Agent
+	msgs  []message.Message
+}
+
+// Tree tracks the subagents of a run. Feed it every message with Observe; it
+// is safe to read from other goroutines while messages arrive.
+type Tree struct {
+	now func() time.Time
+
+	mu       sync.Mutex
+	agents   map[string]*node
+	order    []string
+	seen     map[string]struct{}
+	handlers []func(Event)
+	closed   bool
+	// changed is closed and replaced whenever the tree changes.
+	changed chan struct{}
+}
+
+// NewTree creates an empty Tree.
+func NewTree() *Tree {
+	return &Tree{
+		now:     time.Now,
+		agents:  make(map[string]*node),
+		seen:    make(map[string]struct{}),
+		changed: make(chan struct{}),
+	}
+}
+
+// OnEvent registers fn to be called for every change. Handlers run on the
+// goroutine calling Observe, in registration order, and must not block.
+func (t *Tree) OnEvent(fn func(Event)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.handlers = append(t.handlers, fn)
+}
+
+// Observe updates the tree with a message. Task calls start subagents, their
+// tool results complete them, and messages with a ParentToolUseID are
+// attributed to the subagent that produced them. A result message fails the
+// subagents still running.
+func (t *Tree) Observe(msg message.Message) {
+	t.mu.Lock()
+
+	var events []Event
+
+	switch m := msg.(type) {
+	case *message.AssistantMessage:
+		parent := deref(m.ParentToolUseID)
+		if parent != "" {
+			n := t.attribute(parent, msg, &events)
+
+			t.charge(n, m)
+		}
+
+		for _, block := range m.Content {
+			if use, ok := block.(*message.ToolUseBlock); ok {
+				t.start(use, parent, &events)
+			}
+		}
+	case *message.UserMessage:
+		if parent := deref(m.ParentToolUseID); parent != "" {
+			t.attribute(parent, msg, &events)
+		}
+
+		for _, block := range m.Content.Blocks() {
+			if result, ok := block.(*message.ToolResultBlock); ok {
+				t.finish(result, &events)
+			}
+		}
+	case *message.StreamEvent:
+		if parent := deref(m.ParentToolUseID); parent != "" {
+			t.attribute(parent, msg, &events)
+		}
+	case *message.ResultMessage:
+		for _, id := range t.order {
+			if n := t.agents[id]; n.agent.Status == StatusRunning {
+				t.end(n, StatusFailed, &events)
+			}
+		}
+	}
+
+	handlers := t.handlers
+
+	if len(events) > 0 {
+		close(t.changed)
+		t.changed = make(chan struct{})
+	}
+
+	t.mu.Unlock()
+
+	for _, e := range events {
+		for _, fn := range handlers {
+			fn(e)
+		}
+	}
+}
+
+// Close ends the live iterators returned by Messages. Call it when the run
+// ends without a result message.
+func (t *Tree) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.closed {
+		t.closed = true
+
+		close(t.changed)
+		t.changed = make(chan struct{})
+	}
+}
+
+// Get returns the subagent started by the Task call with the given ID.
+func (t *Tree) Get(id string) (Agent, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n, ok := t.agents[id]
+	if !ok {
+		return Agent{}, false
+	}
+
+	return n.snapshot(), true
+}
+
+// All returns every subagent in start order.
+func (t *Tree) All() []Agent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make([]Agent, 0, len(t.order))
+	for _, id := range t.order {
+		out = append(out, t.agents[id].snapshot())
+	}
+
+	return out
+}
+
+// Roots returns the subagents the main agent started, in start order.
+func (t *Tree) Roots() []Agent {
+	return t.Children("")
+}
+
+// Children returns the subagents started by the subagent with the given ID,
+// or by the main agent for "", in start order.
+func (t *Tree) Children(id string) []Agent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var out []Agent
+
+	for _, childID := range t.order {
+		if n := t.agents[childID]; n.agent.ParentID == id {
+			out = append(out, n.snapshot())
+		}
+	}
+
+	return out
+}
+
+// Messages returns an iterator over the messages of the subagent with the
+// given ID. It yields the messages recorded so far, then follows new ones
+// until the subagent ends, the tree is closed, or ctx is done. A subagent
+// that has not started yet is waited for.
+func (t *Tree) Messages(ctx context.Context, id string) iter.Seq[message.Message] {
+	return func(yield func(message.Message) bool) {
+		next := 0
+
+		for {
+			t.mu.Lock()
+
+			var batch []message.Message
+
+			done := t.closed
+
+			if n, ok := t.agents[id]; ok {
+				batch = slices.Clone(n.msgs[next:])
+				next += len(batch)
+				done = done || n.agent.Status != StatusRunning
+			}
+
+			changed := t.changed
+
+			t.mu.Unlock()
+
+			for _, msg := range batch {
+				if !yield(msg) {
+					return
+				}
+			}
+
+			if done {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+			}
+		}
+	}
+}
+
+// start records a Task call made by the agent parent.
+func (t *Tree) start(use *message.ToolUseBlock, parent string, events *[]Event) {
+	if _, isAgent := subagentTools[use.Name]; !isAgent || use.ID == "" {
+		return
+	}
+
+	if _, known := t.agents[use.ID]; known {
+		return
+	}
+
+	n := t.add(use.ID, parent, events)
+	n.agent.Type = stringInput(use.Input, "subagent_type")
+	n.agent.Description = stringInput(use.Input, "description")
+	n.agent.Prompt = stringInput(use.Input, "prompt")
+}
+
+// add creates a running subagent.
+func (t *Tree) add(id, parent string, events *[]Event) *node {
+	n := &node{agent: Agent{ID: id, ParentID: parent, Status: StatusRunning, Started: t.now()}}
+	t.agents[id] = n
+	t.order = append(t.order, id)
+
+	if p, ok := t.agents[parent]; ok {
+		p.agent.Children = append(p.agent.Children, id)
+	}
+
+	*events = append(*events, Event{Type: EventStarted, Agent: n.snapshot()})
+
+	return n
+}
+
+// attribute records a message of the subagent id. Subagents whose Task call
+// was not seen, e.g. when observation started mid-run, are added on their
+// first message.
+func (t *Tree) attribute(id string, msg message.Message, events *[]Event) *node {
+	n, ok := t.agents[id]
+	if !ok {
+		n = t.add(id, "", events)
+	}
+
+	n.msgs = append(n.msgs, msg)
+	n.agent.Messages++
+
+	*events = append(*events, Event{Type: EventMessage, Agent: n.snapshot(), Message: msg})
+
+	return n
+}
+
+// charge adds an assistant message's usage to its subagent. Messages sharing
+// an API message ID are counted once.
+func (t *Tree) charge(n *node, m *message.AssistantMessage) {
+	if m.Model != "" {
+		n.agent.Model = m.Model
+	}
+
+	if m.Usage == nil {
+		return
+	}
+
+	if m.ID != "" {
+		if _, dup := t.seen[m.ID]; dup {
+			return
+		}
+
+		t.seen[m.ID] = struct{}{}
+	}
+
+	n.agent.Usage.InputTokens += m.Usage.InputTokens
+	n.agent.Usage.OutputTokens += m.Usage.OutputTokens
+	n.agent.Usage.CacheCreationInputTokens += m.Usage.CacheCreationInputTokens
+	n.agent.Usage.CacheReadInputTokens += m.Usage.CacheReadInputTokens
+
+	if cost, err := models.EstimateCost(m.Model, *m.Usage); err == nil {
+		n.agent.Cost = n.agent.Cost.Add(cost)
+	}
+}
+
+// finish ends the subagent whose Task call a tool result answers.
+func (t *Tree) finish(result *message.ToolResultBlock, events *[]Event) {
+	n, ok := t.agents[result.ToolUseID]
+	if !ok || n.agent.Status != StatusRunning {
+		return
+	}
+
+	var parts []string
+
+	for _, block := range result.Content {
+		if text, ok := block.(*message.TextBlock); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+
+	n.agent.Result = strings.Join(parts, "\n")
+
+	status := StatusCompleted
+	if result.IsError {
+		status = StatusFailed
+	}
+
+	t.end(n, status, events)
+}
+
+func (t *Tree) end(n *node, status Status, events *[]Event) {
+	n.agent.Status = status
+	n.agent.Ended = t.now()
+
+	typ := EventCompleted
+	if status == StatusFailed {
+		typ = EventFailed
+	}
+
+	*events = append(*events, Event{Type: typ, Agent: n.snapshot()})
+}
+
+func (n *node) snapshot() Agent {
+	a := n.agent
+	a.Children = slices.Clone(a.Children)
+
+	return a
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
+func stringInput(input map[string]any, key string) string {
+	value, _ := input[key].(string)
+
+	return value
+}
@@ -0,0 +1,134 @@
+package subagent
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/message"
+)
+
+func taskCall(id, parent, typ string) *message.AssistantMessage {
+	msg := &message.AssistantMessage{Content: []message.ContentBlock{&message.ToolUseBlock{
+		ID: id, Name: "Task", Input: map[string]any{"subagent_type": typ, "description": typ + " work", "prompt": "go"},
+	}}}
+
+	if parent != "" {
+		msg.ParentToolUseID = new(parent)
+	}
+
+	return msg
+}
+
+func taskResult(id, text string, isError bool) *message.UserMessage {
+	return &message.UserMessage{Content: message.NewUserMessageContentBlocks([]message.ContentBlock{
+		&message.ToolResultBlock{ToolUseID: id, IsError: isError, Content: []message.ContentBlock{&message.TextBlock{Text: text}}},
+	})}
+}
+
+func TestTree(t *testing.T) {
+	tree := NewTree()
+
+	var events []string
+
+	tree.OnEvent(func(e Event) {
+		events = append(events, string(e.Type)+":"+e.Agent.ID)
+	})
+
+	usage := &message.Usage{InputTokens: 1_000_000}
+
+	tree.Observe(taskCall("t1", "", "Explore"))
+	tree.Observe(&message.AssistantMessage{ID: "m1", ParentToolUseID: new("t1"), Model: "claude-haiku-4-5", Usage: usage})
+	tree.Observe(&message.AssistantMessage{ID: "m1", ParentToolUseID: new("t1"), Model: "claude-haiku-4-5", Usage: usage})
+	tree.Observe(taskCall("t2", "t1", "Plan"))
+	tree.Observe(taskResult("t2", "", true))
+	tree.Observe(taskResult("t1", "found it", false))
+
+	explore, ok := tree.Get("t1")
+	require.True(t, ok)
+	assert.Equal(t, StatusCompleted, explore.Status)
+	assert.Equal(t, "Explore", explore.Type)
+	assert.Equal(t, "Explore work", explore.Description)
+	assert.Equal(t, "found it", explore.Result)
+	assert.Equal(t, 1_000_000, explore.Usage.InputTokens, "split messages are charged once")
+	assert.InDelta(t, 1.0, explore.Cost.Total(), 1e-9)
+	assert.Equal(t, 3, explore.Messages)
+	assert.Equal(t, []string{"t2"}, explore.Children)
+	assert.False(t, explore.Ended.IsZero())
+
+	plan, ok := tree.Get("t2")
+	require.True(t, ok)
+	assert.Equal(t, StatusFailed, plan.Status)
+	assert.Equal(t, "t1", plan.ParentID)
+
+	require.Len(t, tree.Roots(), 1)
+	assert.Len(t, tree.Children("t1"), 1)
+	assert.Len(t, tree.All(), 2)
+
+	assert.Equal(t, []string{
+		"started:t1", "message:t1", "message:t1", "message:t1", "started:t2", "failed:t2", "completed:t1",
+	}, events)
+}
+
+func TestTreeUnknownParentAndResult(t *testing.T) {
+	tree := NewTree()
+
+	tree.Observe(&message.UserMessage{ParentToolUseID: new("late"), Content: message.NewUserMessageContent("hi")})
+
+	late, ok := tree.Get("late")
+	require.True(t, ok, "subagents are added on their first message")
+	assert.Equal(t, StatusRunning, late.Status)
+
+	tree.Observe(&message.ResultMessage{Subtype: "error_during_execution", IsError: true})
+
+	late, _ = tree.Get("late")
+	assert.Equal(t, StatusFailed, late.Status, "a result fails running subagents")
+
+	_, ok = tree.Get("missing")
+	assert.False(t, ok)
+}
+
+func TestTreeMessages(t *testing.T) {
+	tree := NewTree()
+
+	var (
+		wg   sync.WaitGroup
+		seen []message.Message
+	)
+
+	wg.Go(func() {
+		for msg := range tree.Messages(t.Context(), "t1") {
+			seen = append(seen, msg)
+		}
+	})
+
+	tree.Observe(taskCall("t1", "", "Explore"))
+	tree.Observe(&message.StreamEvent{ParentToolUseID: new("t1")})
+	tree.Observe(&message.AssistantMessage{ParentToolUseID: new("t1")})
+	tree.Observe(taskResult("t1", "done", false))
+
+	done := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("iterator did not end when the subagent completed")
+	}
+
+	assert.Len(t, seen, 2)
+
+	open := NewTree()
+	open.Close()
+
+	for range open.Messages(t.Context(), "never") {
+		t.Fatal("closed tree yielded a message")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"log/slog"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -46,10 +47,20 @@ type CLITransport struct {
 	isStreaming    bool         // Whether this transport is in streaming mode
 	closing        bool         // Whether Close() has been called (intentional shutdown)
 	stdinClosed    bool         // Whether stdin was closed (e.g., due to context cancellation)
+	reading        bool         // Whether ReadMessages owns waiting for the process
+
+	// The process is waited for once, by whichever of ReadMessages and
+	// Close needs it first; exited is closed when it has exited.
+	waitOnce sync.Once
+	waitErr  error
+	exited   chan struct{}
 }
 
 // Compile-time verification that CLITransport implements the Transport interface.
-var _ config.Transport = (*CLITransport)(nil)
+var (
+	_ config.Transport      = (*CLITransport)(nil)
+	_ config.GracefulCloser = (*CLITransport)(nil)
+)
 
 // NewCLITransport creates a new CLI transport with the given prompt and options.
 //
@@ -100,6 +111,8 @@ func NewCLITransportWithMode(
 //
 // This method discovers the Claude CLI binary, builds command arguments,
 // and spawns the process with the configured environment variables.
+// The process is launched through options.Wrapper when set, and
+// options.Launcher can adjust the command before it starts.
 // It sets up stdin, stdout, and stderr pipes for communication.
 //
 // Returns CLINotFoundError if the CLI binary cannot be located,
@@ -120,12 +133,20 @@ func (t *CLITransport) Start(ctx context.Context) error {
 
 	t.cliPath = cliPath
 
+	// Check that the settings sources can be merged
+	if _, err := cli.BuildSettings(t.options); err != nil {
+		return fmt.Errorf("build settings: %w", err)
+	}
+
 	// Build command arguments
 	t.args = cli.BuildArgs(t.prompt, t.options, t.isStreaming)
 	t.log.Debug("Built command arguments", "args", t.args)
 
 	// Build environment
-	t.env = cli.BuildEnvironment(t.options)
+	t.env, err = cli.BuildEnvironment(t.options)
+	if err != nil {
+		return fmt.Errorf("build environment: %w", err)
+	}
 
 	// Set working directory
 	t.cwd = t.options.Cwd
@@ -138,11 +159,42 @@ func (t *CLITransport) Start(ctx context.Context) error {
 
 	t.log.Debug("Set working directory", "cwd", t.cwd)
 
+	name, args := t.cliPath, t.args
+	if wrapper := t.options.Wrapper; len(wrapper) > 0 {
+		name, args = wrapper[0], slices.Concat(wrapper[1:], []string{t.cliPath}, t.args)
+		t.log.Debug("Launching CLI through wrapper", "wrapper", wrapper)
+	}
+
+	if t.options.Process.HasLimits() {
+		name, args, err = limitCommand(name, args, t.options.Process)
+		if err != nil {
+			return &errors.CLIConnectionError{Err: fmt.Errorf("set resource limits: %w", err)}
+		}
+	}
+
 	//nolint:gosec // G204: Subprocess launching with dynamic args is expected for CLI invocation
-	cmd := exec.CommandContext(ctx, t.cliPath, t.args...)
+	cmd := exec.CommandContext(ctx, name, args...)
 	cmd.Dir = t.cwd
 	cmd.Env = t.env
 
+	// Let the caller adjust the command before the pipes are attached, so
+	// nothing leaks if it fails
+	if t.options.Launcher != nil {
+		if err := t.options.Launcher(cmd); err != nil {
+			t.log.Error("CLI launcher failed", "error", err)
+
+			return &errors.CLIConnectionError{Err: fmt.Errorf("launcher: %w", err)}
+		}
+	}
+
+	// Configured after the launcher so attributes it sets are merged
+	// rather than dropping the process group
+	configureProcess(cmd)
+
+	cmd.Cancel = func() error {
+		return kill(cmd.Process)
+	}
+
 	// Set up stdin pipe for sending messages
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
@@ -181,6 +233,8 @@ func (t *CLITransport) Start(ctx context.Context) error {
 	}
 
 	t.cmd = cmd
+	t.exited = make(chan struct{})
+
 	t.log.Info("Claude CLI subprocess started successfully", "pid", cmd.Process.Pid)
 
 	return nil
@@ -206,6 +260,10 @@ func (t *CLITransport) ReadMessages(
 	messages := make(chan map[string]any)
 	errs := make(chan error, 1)
 
+	t.mu.Lock()
+	t.reading = true
+	t.mu.Unlock()
+
 	// Start stderr streaming goroutine if callback is set
 	var stderrWg sync.WaitGroup
 
@@ -261,6 +319,15 @@ func (t *CLITransport) ReadMessages(
 		defer close(errs)
 		defer t.log.Debug("ReadMessages goroutine stopped")
 
+		waited := false
+
+		// Reap the process even when reading stops early, so Close sees it exit
+		defer func() {
+			if !waited {
+				go t.wait()
+			}
+		}()
+
 		scanner := bufio.NewScanner(t.stdout)
 		// Set large buffer for big messages
 		buf := make([]byte, maxScanTokenSize)
@@ -320,7 +387,9 @@ func (t *CLITransport) ReadMessages(
 		// Wait for process to exit and capture any errors
 		t.log.Debug("Waiting for CLI process to exit")
 
-		if err := t.cmd.Wait(); err != nil {
+		waited = true
+
+		if err := t.wait(); err != nil {
 			// Check if this is an intentional shutdown
 			t.mu.Lock()
 			isClosing := t.closing
@@ -478,26 +547,100 @@ func (t *CLITransport) CloseStdin() error {
 
 // Close terminates the CLI process.
 //
-// This forcefully kills the CLI process using SIGKILL. It's safe to call
-// Close multiple times or on an already-terminated process.
+// With a grace period in ProcessOptions, Close sends SIGTERM and waits up
+// to the grace period before killing the process; otherwise it kills the
+// process immediately. On Linux the whole process group is signalled, so
+// commands the CLI started are stopped too. It's safe to call Close multiple
+// times or on an already-terminated process.
 func (t *CLITransport) Close() error {
+	var grace time.Duration
+	if t.options != nil && t.options.Process != nil {
+		grace = t.options.Process.GracePeriod
+	}
+
+	if grace <= 0 {
+		proc := t.beginClose()
+		if proc == nil {
+			return nil
+		}
+
+		return t.kill(proc)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+
+	return t.CloseContext(ctx)
+}
+
+// CloseContext sends SIGTERM to the CLI process and waits for it to exit
+// until ctx is done, then kills it along with any commands it left running.
+func (t *CLITransport) CloseContext(ctx context.Context) error {
+	proc := t.beginClose()
+	if proc == nil {
+		return nil
+	}
+
+	t.log.Debug("Terminating CLI process", "pid", proc.Pid)
+
+	if err := terminate(proc); err != nil {
+		t.log.Debug("Failed to terminate CLI process, killing it", "pid", proc.Pid, "error", err)
+
+		return t.kill(proc)
+	}
+
+	select {
+	case <-t.exited:
+		t.log.Debug("CLI process exited", "pid", proc.Pid)
+	case <-ctx.Done():
+		t.log.Warn("CLI process did not exit in time, killing it", "pid", proc.Pid)
+	}
+
+	// Also stops commands that outlived the CLI
+	return t.kill(proc)
+}
+
+// beginClose marks the transport as closing and returns the process to stop,
+// or nil if there is none.
+func (t *CLITransport) beginClose() *os.Process {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
 	t.closing = true
 	t.stdinClosed = true
 
-	if t.cmd != nil && t.cmd.Process != nil {
-		t.log.Debug("Killing CLI process", "pid", t.cmd.Process.Pid)
+	if t.cmd == nil || t.cmd.Process == nil {
+		return nil
+	}
 
-		if err := t.cmd.Process.Kill(); err != nil {
-			return fmt.Errorf("kill CLI process (pid %d): %w", t.cmd.Process.Pid, err)
-		}
+	// Without a reader nobody else waits for the process
+	if !t.reading && t.exited != nil {
+		go t.wait()
+	}
+
+	return t.cmd.Process
+}
+
+func (t *CLITransport) kill(proc *os.Process) error {
+	t.log.Debug("Killing CLI process", "pid", proc.Pid)
+
+	if err := kill(proc); err != nil {
+		return fmt.Errorf("kill CLI process (pid %d): %w", proc.Pid, err)
 	}
 
 	return nil
 }
 
+// wait waits for the process to exit, once.
+func (t *CLITransport) wait() error {
+	t.waitOnce.Do(func() {
+		t.waitErr = t.cmd.Wait()
+		close(t.exited)
+	})
+
+	return t.waitErr
+}
+
 // cleanStderr parses and cleans stderr output from the CLI.
 // Bun includes minified source context in error output which is not useful.
 // This extracts just the error message and stack trace.
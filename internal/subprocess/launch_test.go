@@ -0,0 +1,115 @@
+//go:build unix
+
+package subprocess
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/config"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/errors"
+)
+
+// writeScript writes an executable shell script to a temporary directory.
+func writeScript(t *testing.T, name, script string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o700))
+
+	return path
+}
+
+// fakeCLI starts a transport running script as the CLI.
+func fakeCLI(t *testing.T, script string, options *config.Options) *CLITransport {
+	t.Helper()
+	t.Setenv("CLAUDE_AGENT_SDK_SKIP_VERSION_CHECK", "1")
+
+	options.CliPath = writeScript(t, "claude", script)
+
+	transport := NewCLITransportWithMode(slog.Default(), "", options, true)
+	require.NoError(t, transport.Start(context.Background()))
+
+	t.Cleanup(func() { _ = transport.Close() })
+
+	return transport
+}
+
+// waitForFile returns the trimmed content of path once it is written.
+func waitForFile(t *testing.T, path string) string {
+	t.Helper()
+
+	var data []byte
+
+	require.Eventually(t, func() bool {
+		var err error
+
+		data, err = os.ReadFile(path)
+
+		return err == nil && bytes.HasSuffix(data, []byte("\n"))
+	}, 5*time.Second, 10*time.Millisecond)
+
+	return strings.TrimSpace(string(data))
+}
+
+func TestStart_Wrapper(t *testing.T) {
+	dir := t.TempDir()
+	wrapper := writeScript(t, "wrap", fmt.Sprintf("echo \"$1\" > %s/wrapped\nshift\nexec \"$@\"\n", dir))
+
+	transport := fakeCLI(t, fmt.Sprintf("echo \"$0\" > %s/cli\nsleep 300\n", dir), &config.Options{
+		Wrapper: []string{wrapper, "--flag"},
+	})
+
+	assert.Equal(t, "--flag", waitForFile(t, filepath.Join(dir, "wrapped")))
+	assert.Equal(t, transport.cliPath, waitForFile(t, filepath.Join(dir, "cli")),
+		"the wrapper runs the CLI")
+}
+
+func TestStart_Launcher(t *testing.T) {
+	dir := t.TempDir()
+
+	var launched *exec.Cmd
+
+	fakeCLI(t, fmt.Sprintf("echo \"$LAUNCHED\" > %s/env\nsleep 300\n", dir), &config.Options{
+		Launcher: func(cmd *exec.Cmd) error {
+			launched = cmd
+			cmd.Env = append(cmd.Env, "LAUNCHED=yes")
+
+			return nil
+		},
+	})
+
+	require.NotNil(t, launched)
+	assert.Equal(t, "yes", waitForFile(t, filepath.Join(dir, "env")))
+}
+
+func TestStart_LauncherError(t *testing.T) {
+	t.Setenv("CLAUDE_AGENT_SDK_SKIP_VERSION_CHECK", "1")
+
+	dir := t.TempDir()
+	refused := stderrors.New("no cgroup")
+
+	transport := NewCLITransportWithMode(slog.Default(), "", &config.Options{
+		CliPath:  writeScript(t, "claude", fmt.Sprintf("touch %s/started\n", dir)),
+		Launcher: func(*exec.Cmd) error { return refused },
+	}, true)
+
+	err := transport.Start(context.Background())
+	require.ErrorIs(t, err, refused)
+
+	_, ok := stderrors.AsType[*errors.CLIConnectionError](err)
+	assert.True(t, ok)
+	assert.NoFileExists(t, filepath.Join(dir, "started"))
+}
@@ -0,0 +1,87 @@
+package subprocess
+
+import (
+	stderrors "errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/config"
+)
+
+// configureProcess runs the CLI in its own process group, so signals reach
+// the commands it starts, and kills it if the thread that started it dies.
+// Attributes already set on cmd, for example by a launcher, are kept.
+func configureProcess(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+
+	// A session leader already leads its own process group, and may not
+	// call setpgid
+	if !cmd.SysProcAttr.Setsid {
+		cmd.SysProcAttr.Setpgid = true
+	}
+
+	if cmd.SysProcAttr.Pdeathsig == 0 {
+		cmd.SysProcAttr.Pdeathsig = syscall.SIGKILL
+	}
+}
+
+// terminate asks the CLI's process group to exit.
+func terminate(p *os.Process) error {
+	return signalGroup(p, syscall.SIGTERM)
+}
+
+// kill kills the CLI's process group.
+func kill(p *os.Process) error {
+	return signalGroup(p, syscall.SIGKILL)
+}
+
+func signalGroup(p *os.Process, sig syscall.Signal) error {
+	err := syscall.Kill(-p.Pid, sig)
+	if !stderrors.Is(err, syscall.ESRCH) {
+		return err
+	}
+
+	// Either every process in the group has exited, or the CLI is not
+	// leading a group of its own; signal the CLI itself unless it was reaped
+	err = p.Signal(sig)
+	if stderrors.Is(err, os.ErrProcessDone) {
+		return nil
+	}
+
+	return err
+}
+
+// limitCommand returns a command that applies the resource limits and then
+// execs name with args, so the limits are in place before the CLI runs and
+// every process it starts inherits them. The shell's ulimit sets both the
+// soft and the hard limit; if one cannot be set the shell exits before the
+// CLI starts.
+func limitCommand(name string, args []string, p *config.ProcessOptions) (string, []string, error) {
+	limits := []struct {
+		flag  string
+		value uint64
+	}{
+		{"-t", uint64((p.CPUTime + time.Second - 1) / time.Second)},
+		{"-d", (p.Memory + 1023) / 1024}, // in KiB
+		{"-n", p.OpenFiles},
+	}
+
+	var script []string
+
+	for _, limit := range limits {
+		if limit.value > 0 {
+			script = append(script, fmt.Sprintf("ulimit %s %d", limit.flag, limit.value))
+		}
+	}
+
+	script = append(script, `exec "$@"`)
+
+	return "/bin/sh", slices.Concat([]string{"-c", strings.Join(script, " && "), "sh", name}, args), nil
+}
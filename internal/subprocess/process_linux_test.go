@@ -0,0 +1,151 @@
+package subprocess
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/config"
+)
+
+// alive reports whether pid is a running process; zombies count as exited.
+func alive(pid int) bool {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return false
+	}
+
+	// The state follows the parenthesized command name
+	fields := strings.Fields(string(data[bytes.LastIndexByte(data, ')')+1:]))
+
+	return len(fields) > 0 && fields[0] != "Z"
+}
+
+func TestClose_KillsProcessGroup(t *testing.T) {
+	dir := t.TempDir()
+
+	transport := fakeCLI(t, fmt.Sprintf("sleep 300 &\necho $! > %s/child\nwait\n", dir), &config.Options{})
+
+	child, err := strconv.Atoi(waitForFile(t, filepath.Join(dir, "child")))
+	require.NoError(t, err)
+	require.True(t, alive(child))
+
+	require.NoError(t, transport.Close())
+
+	assert.Eventually(t, func() bool { return !alive(child) }, 5*time.Second, 10*time.Millisecond,
+		"commands started by the CLI are killed with it")
+	require.NoError(t, transport.Close())
+}
+
+func TestCloseContext_WaitsForGracefulExit(t *testing.T) {
+	dir := t.TempDir()
+
+	transport := fakeCLI(t, fmt.Sprintf(
+		"trap 'echo done > %[1]s/term; exit 0' TERM\necho ready > %[1]s/ready\nsleep 300 &\nwait\n", dir), &config.Options{})
+	waitForFile(t, filepath.Join(dir, "ready"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	start := time.Now()
+
+	require.NoError(t, transport.CloseContext(ctx))
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, "done", waitForFile(t, filepath.Join(dir, "term")), "the CLI handled SIGTERM")
+}
+
+func TestClose_GracePeriodKillsStubbornProcess(t *testing.T) {
+	dir := t.TempDir()
+
+	transport := fakeCLI(t, fmt.Sprintf("trap '' TERM\necho ready > %s/ready\nwhile :; do sleep 1; done\n", dir),
+		&config.Options{Process: &config.ProcessOptions{GracePeriod: 200 * time.Millisecond}})
+	waitForFile(t, filepath.Join(dir, "ready"))
+
+	start := time.Now()
+
+	require.NoError(t, transport.Close())
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+
+	select {
+	case <-transport.exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("process was not killed after the grace period")
+	}
+}
+
+func TestLimitCommand(t *testing.T) {
+	name, args, err := limitCommand("cat", []string{"/proc/self/limits"}, &config.ProcessOptions{
+		CPUTime:   90 * time.Second,
+		Memory:    1 << 30,
+		OpenFiles: 64,
+	})
+	require.NoError(t, err)
+
+	out, err := exec.CommandContext(t.Context(), name, args...).Output()
+	require.NoError(t, err)
+
+	limits := string(out)
+	assert.Regexp(t, `Max cpu time\s+90\s+90`, limits)
+	assert.Regexp(t, `Max data size\s+1073741824\s+1073741824`, limits)
+	assert.Regexp(t, `Max open files\s+64\s+64`, limits)
+}
+
+func TestLimitCommand_RoundsUp(t *testing.T) {
+	_, args, err := limitCommand("true", nil, &config.ProcessOptions{CPUTime: 500 * time.Millisecond, Memory: 1})
+	require.NoError(t, err)
+	assert.Equal(t, `ulimit -t 1 && ulimit -d 1 && exec "$@"`, args[1], "sub-second and sub-KiB limits are kept")
+}
+
+func TestStart_LimitsApplyBeforeCLIRuns(t *testing.T) {
+	dir := t.TempDir()
+
+	transport := fakeCLI(t, fmt.Sprintf("ulimit -n > %s/nofile\nsleep 300\n", dir), &config.Options{
+		Process: &config.ProcessOptions{OpenFiles: 64},
+	})
+
+	assert.Equal(t, "64", waitForFile(t, filepath.Join(dir, "nofile")))
+	assert.Equal(t, transport.cliPath, transport.cmd.Args[len(transport.cmd.Args)-1-len(transport.args)])
+}
+
+func TestClose_LauncherSysProcAttrKeepsProcessGroup(t *testing.T) {
+	dir := t.TempDir()
+
+	transport := fakeCLI(t, fmt.Sprintf("sleep 300 &\necho $! > %s/child\nwait\n", dir), &config.Options{
+		Launcher: func(cmd *exec.Cmd) error {
+			cmd.SysProcAttr = &syscall.SysProcAttr{}
+
+			return nil
+		},
+	})
+
+	require.True(t, transport.cmd.SysProcAttr.Setpgid)
+	require.Equal(t, syscall.SIGKILL, transport.cmd.SysProcAttr.Pdeathsig)
+
+	child, err := strconv.Atoi(waitForFile(t, filepath.Join(dir, "child")))
+	require.NoError(t, err)
+	require.NoError(t, transport.Close())
+
+	assert.Eventually(t, func() bool { return !alive(child) }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestKill_FallsBackToProcessWithoutGroup(t *testing.T) {
+	cmd := exec.Command("sleep", "300")
+	require.NoError(t, cmd.Start())
+
+	t.Cleanup(func() { _ = cmd.Wait() })
+
+	require.NoError(t, kill(cmd.Process), "the CLI is killed even when it leads no process group")
+
+	assert.Eventually(t, func() bool { return !alive(cmd.Process.Pid) }, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,32 @@
+//go:build !linux
+
+package subprocess
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/config"
+)
+
+func configureProcess(_ *exec.Cmd) {}
+
+// terminate asks the CLI to exit. Platforms without SIGTERM report an error,
+// and the CLI is killed instead.
+func terminate(p *os.Process) error {
+	return p.Signal(terminateSignal)
+}
+
+func kill(p *os.Process) error {
+	err := p.Kill()
+	if errors.Is(err, os.ErrProcessDone) {
+		return nil
+	}
+
+	return err
+}
+
+func limitCommand(_ string, _ []string, _ *config.ProcessOptions) (string, []string, error) {
+	return "", nil, errors.ErrUnsupported
+}
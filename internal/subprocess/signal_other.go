@@ -0,0 +1,7 @@
+//go:build !unix
+
+package subprocess
+
+import "os"
+
+var terminateSignal os.Signal = os.Interrupt
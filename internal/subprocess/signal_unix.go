@@ -0,0 +1,10 @@
+//go:build unix && !linux
+
+package subprocess
+
+import (
+	"os"
+	"syscall"
+)
+
+var terminateSignal os.Signal = syscall.SIGTERM
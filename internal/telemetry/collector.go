@@ -0,0 +1,314 @@
+package telemetry
+
+import (
+	"bufio"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the histogram bucket upper bounds, in seconds, used when
+// NewCollector is given none. They span fast hooks to long agent turns.
+var DefaultBuckets = []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600}
+
+// Collector is an in-memory Metrics implementation.
+//
+// It serves the Prometheus text exposition format over HTTP and implements
+// expvar.Var, so it can be mounted on a metrics endpoint or published with
+// expvar.Publish.
+type Collector struct {
+	buckets []float64
+
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+var (
+	_ Metrics      = (*Collector)(nil)
+	_ expvar.Var   = (*Collector)(nil)
+	_ http.Handler = (*Collector)(nil)
+)
+
+type metricKind int
+
+const (
+	kindCounter metricKind = iota
+	kindHistogram
+)
+
+type family struct {
+	kind   metricKind
+	series map[string]*series
+}
+
+type series struct {
+	labels []Label
+	value  float64  // counter value
+	counts []uint64 // histogram count per bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+// NewCollector creates a Collector. buckets are the histogram upper bounds;
+// DefaultBuckets are used if none are given.
+func NewCollector(buckets ...float64) *Collector {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+
+	return &Collector{
+		buckets:  buckets,
+		families: make(map[string]*family, len(metricHelp)),
+	}
+}
+
+// Add increments the counter name by delta.
+func (c *Collector) Add(name string, delta float64, labels ...Label) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if s := c.series(name, kindCounter, labels); s != nil {
+		s.value += delta
+	}
+}
+
+// Observe records value in the histogram name.
+func (c *Collector) Observe(name string, value float64, labels ...Label) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := c.series(name, kindHistogram, labels)
+	if s == nil {
+		return
+	}
+
+	if s.counts == nil {
+		s.counts = make([]uint64, len(c.buckets))
+	}
+
+	if i, _ := slices.BinarySearch(c.buckets, value); i < len(c.buckets) {
+		s.counts[i]++
+	}
+
+	s.sum += value
+	s.count++
+}
+
+// Counter returns the current value of the counter name with exactly the
+// given labels, or 0 if it was never incremented.
+func (c *Collector) Counter(name string, labels ...Label) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, ok := c.families[name]
+	if !ok || f.kind != kindCounter {
+		return 0
+	}
+
+	s, ok := f.series[seriesKey(sortedLabels(labels))]
+	if !ok {
+		return 0
+	}
+
+	return s.value
+}
+
+// series returns the series for name and labels, creating it if needed.
+// It returns nil if name is already registered with a different kind.
+// Caller must hold c.mu.
+func (c *Collector) series(name string, kind metricKind, labels []Label) *series {
+	f, ok := c.families[name]
+	if !ok {
+		f = &family{kind: kind, series: make(map[string]*series, 1)}
+		c.families[name] = f
+	}
+
+	if f.kind != kind {
+		return nil
+	}
+
+	labels = sortedLabels(labels)
+	key := seriesKey(labels)
+
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labels: labels}
+		f.series[key] = s
+	}
+
+	return s
+}
+
+// WritePrometheus writes all metrics in the Prometheus text exposition format.
+func (c *Collector) WritePrometheus(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+
+	for _, name := range slices.Sorted(maps.Keys(c.families)) {
+		f := c.families[name]
+
+		if help, ok := metricHelp[name]; ok {
+			fmt.Fprintf(bw, "# HELP %s %s\n", name, help)
+		}
+
+		typ := "counter"
+		if f.kind == kindHistogram {
+			typ = "histogram"
+		}
+
+		fmt.Fprintf(bw, "# TYPE %s %s\n", name, typ)
+
+		for _, key := range slices.Sorted(maps.Keys(f.series)) {
+			s := f.series[key]
+
+			if f.kind == kindCounter {
+				fmt.Fprintf(bw, "%s%s %s\n", name, formatLabels(s.labels), formatFloat(s.value))
+
+				continue
+			}
+
+			var cumulative uint64
+
+			for i, upper := range c.buckets {
+				if s.counts != nil {
+					cumulative += s.counts[i]
+				}
+
+				le := append(slices.Clone(s.labels), Label{Name: "le", Value: formatFloat(upper)})
+				fmt.Fprintf(bw, "%s_bucket%s %d\n", name, formatLabels(le), cumulative)
+			}
+
+			inf := append(slices.Clone(s.labels), Label{Name: "le", Value: "+Inf"})
+			fmt.Fprintf(bw, "%s_bucket%s %d\n", name, formatLabels(inf), s.count)
+			fmt.Fprintf(bw, "%s_sum%s %s\n", name, formatLabels(s.labels), formatFloat(s.sum))
+			fmt.Fprintf(bw, "%s_count%s %d\n", name, formatLabels(s.labels), s.count)
+		}
+	}
+
+	return bw.Flush()
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	if err := c.WritePrometheus(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// expvarSeries is the expvar JSON form of a series.
+type expvarSeries struct {
+	Labels  map[string]string `json:"labels,omitempty"`
+	Value   *float64          `json:"value,omitempty"`
+	Count   *uint64           `json:"count,omitempty"`
+	Sum     *float64          `json:"sum,omitempty"`
+	Buckets map[string]uint64 `json:"buckets,omitempty"`
+}
+
+// String implements expvar.Var. Metrics are rendered as a JSON object keyed
+// by metric name; histogram buckets are cumulative, keyed by upper bound.
+func (c *Collector) String() string {
+	c.mu.Lock()
+
+	out := make(map[string][]expvarSeries, len(c.families))
+
+	for name, f := range c.families {
+		for _, key := range slices.Sorted(maps.Keys(f.series)) {
+			s := f.series[key]
+			e := expvarSeries{}
+
+			if len(s.labels) > 0 {
+				e.Labels = make(map[string]string, len(s.labels))
+				for _, l := range s.labels {
+					e.Labels[l.Name] = l.Value
+				}
+			}
+
+			if f.kind == kindCounter {
+				e.Value = new(s.value)
+			} else {
+				e.Count, e.Sum = new(s.count), new(s.sum)
+				e.Buckets = make(map[string]uint64, len(c.buckets))
+
+				var cumulative uint64
+
+				for i, upper := range c.buckets {
+					if s.counts != nil {
+						cumulative += s.counts[i]
+					}
+
+					e.Buckets[formatFloat(upper)] = cumulative
+				}
+			}
+
+			out[name] = append(out[name], e)
+		}
+	}
+
+	c.mu.Unlock()
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		return "{}"
+	}
+
+	return string(data)
+}
+
+func sortedLabels(labels []Label) []Label {
+	labels = slices.Clone(labels)
+	slices.SortFunc(labels, func(a, b Label) int { return strings.Compare(a.Name, b.Name) })
+
+	return labels
+}
+
+// seriesKey builds a map key from sorted labels.
+func seriesKey(labels []Label) string {
+	var b strings.Builder
+
+	for _, l := range labels {
+		b.WriteString(l.Name)
+		b.WriteByte(0)
+		b.WriteString(l.Value)
+		b.WriteByte(0)
+	}
+
+	return b.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(labels []Label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	parts := make([]string, len(labels))
+	for i, l := range labels {
+		parts[i] = l.Name + `="` + labelEscaper.Replace(l.Value) + `"`
+	}
+
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,60 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector_WritePrometheus(t *testing.T) {
+	c := NewCollector(0.1, 1)
+	c.Add(MetricToolCalls, 1, Label{Name: "tool", Value: "Bash"}, Label{Name: "outcome", Value: "success"})
+	c.Add(MetricToolCalls, 2, Label{Name: "outcome", Value: "success"}, Label{Name: "tool", Value: "Bash"})
+	c.Add("custom_total", 1, Label{Name: "path", Value: `C:\tmp "x"`})
+	c.Observe(MetricHookDuration, 0.05, Label{Name: "event", Value: "PreToolUse"})
+	c.Observe(MetricHookDuration, 0.1, Label{Name: "event", Value: "PreToolUse"})
+	c.Observe(MetricHookDuration, 3, Label{Name: "event", Value: "PreToolUse"})
+	c.Observe(MetricToolCalls, 1) // kind mismatch is ignored
+
+	var out strings.Builder
+	require.NoError(t, c.WritePrometheus(&out))
+	require.Equal(t, `# HELP claude_hook_duration_seconds Hook callback latency in seconds.
+# TYPE claude_hook_duration_seconds histogram
+claude_hook_duration_seconds_bucket{event="PreToolUse",le="0.1"} 2
+claude_hook_duration_seconds_bucket{event="PreToolUse",le="1"} 2
+claude_hook_duration_seconds_bucket{event="PreToolUse",le="+Inf"} 3
+claude_hook_duration_seconds_sum{event="PreToolUse"} 3.15
+claude_hook_duration_seconds_count{event="PreToolUse"} 3
+# HELP claude_tool_calls_total Tool calls, by tool and outcome.
+# TYPE claude_tool_calls_total counter
+claude_tool_calls_total{outcome="success",tool="Bash"} 3
+# TYPE custom_total counter
+custom_total{path="C:\\tmp \"x\""} 1
+`, out.String())
+}
+
+func TestCollector_HTTPAndExpvar(t *testing.T) {
+	c := NewCollector(1)
+	c.Add(MetricCost, 0.25)
+	c.Observe(MetricTimeToResult, 2)
+
+	rr := httptest.NewRecorder()
+	c.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Header().Get("Content-Type"), "version=0.0.4")
+	require.Contains(t, rr.Body.String(), "claude_cost_usd_total 0.25\n")
+
+	var vars map[string][]struct {
+		Value   *float64          `json:"value"`
+		Count   *uint64           `json:"count"`
+		Buckets map[string]uint64 `json:"buckets"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(c.String()), &vars))
+	require.InDelta(t, 0.25, *vars[MetricCost][0].Value, 0)
+	require.Equal(t, uint64(1), *vars[MetricTimeToResult][0].Count)
+	require.Equal(t, map[string]uint64{"1": 0}, vars[MetricTimeToResult][0].Buckets)
+}
@@ -0,0 +1,66 @@
+package telemetry
+
+// Label is a metric dimension.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Metrics receives measurements of agent runs. Counters are incremented with
+// Add and histograms fed with Observe; durations are in seconds.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	Add(name string, delta float64, labels ...Label)
+	Observe(name string, value float64, labels ...Label)
+}
+
+// Metric names recorded by the SDK, with their labels.
+const (
+	// MetricSessions counts started sessions by kind (query, query_stream, client).
+	MetricSessions = "claude_sessions_total"
+	// MetricProcessStarts counts CLI process launches; resumed is "true" when
+	// the process continues or resumes an earlier session.
+	MetricProcessStarts = "claude_cli_process_starts_total"
+	// MetricProcessFailures counts CLI processes that exited with an error.
+	MetricProcessFailures = "claude_cli_process_failures_total"
+	// MetricTurns counts completed turns by outcome (success, error).
+	MetricTurns = "claude_turns_total"
+	// MetricToolCalls counts tool calls by tool and outcome (success, error,
+	// abandoned when the turn ended without a tool result).
+	MetricToolCalls = "claude_tool_calls_total"
+	// MetricPermissionDecisions counts CanUseTool decisions by tool and
+	// behavior (allow, deny).
+	MetricPermissionDecisions = "claude_permission_decisions_total"
+	// MetricHookDuration observes hook callback latency by event and outcome
+	// (success, error).
+	MetricHookDuration = "claude_hook_duration_seconds"
+	// MetricControlTimeouts counts control requests that timed out, by subtype.
+	MetricControlTimeouts = "claude_control_request_timeouts_total"
+	// MetricTokens counts tokens by type (input, output, cache_write, cache_read).
+	MetricTokens = "claude_tokens_total"
+	// MetricCost counts spend in USD as reported by result messages, whose
+	// cumulative totals are converted to per-turn increments.
+	MetricCost = "claude_cost_usd_total"
+	// MetricTimeToFirstToken observes the time from the start of a turn to its
+	// first streamed content delta. Requires partial messages to be enabled.
+	MetricTimeToFirstToken = "claude_time_to_first_token_seconds"
+	// MetricTimeToResult observes the time from the start of a turn to its
+	// result message.
+	MetricTimeToResult = "claude_time_to_result_seconds"
+)
+
+// metricHelp holds the HELP text written for the SDK's metrics.
+var metricHelp = map[string]string{
+	MetricSessions:            "Sessions started, by kind.",
+	MetricProcessStarts:       "Claude CLI processes launched.",
+	MetricProcessFailures:     "Claude CLI processes that exited with an error.",
+	MetricTurns:               "Completed turns, by outcome.",
+	MetricToolCalls:           "Tool calls, by tool and outcome.",
+	MetricPermissionDecisions: "Tool permission decisions, by tool and behavior.",
+	MetricHookDuration:        "Hook callback latency in seconds.",
+	MetricControlTimeouts:     "Control requests that timed out, by subtype.",
+	MetricTokens:              "Tokens used, by type.",
+	MetricCost:                "Spend in USD reported by the CLI.",
+	MetricTimeToFirstToken:    "Seconds from turn start to the first streamed token.",
+	MetricTimeToResult:        "Seconds from turn start to the result message.",
+}
@@ -0,0 +1,428 @@
+// Package telemetry records OpenTelemetry spans for SDK sessions.
+//
+// A Recorder follows one Query, QueryStream or Client session. It produces the
+// span tree
+//
+//	claude.session
+//	└── invoke_agent claude            (one per turn, ended by the result message)
+//	    ├── execute_tool <name>        (tool_use → tool_result)
+//	    ├── claude.permission <name>   (can_use_tool callback)
+//	    ├── claude.hook <event>        (hook callback)
+//	    ├── claude.mcp <server>/<tool> (SDK MCP tools/call)
+//	    └── claude.control <subtype>   (outgoing control request round-trip)
+//
+// Model, token usage and cost use the OpenTelemetry GenAI semantic-convention
+// attribute names. The same events feed an optional Metrics sink.
+package telemetry
+
+import (
+	"context"
+	stderrors "errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/errors"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/message"
+)
+
+// ScopeName is the instrumentation scope of the SDK's tracer.
+const ScopeName = "github.com/wagiedev/claude-agent-sdk-go"
+
+// Attribute keys. The gen_ai.* keys follow the GenAI semantic conventions.
+const (
+	AttrOperationName      = "gen_ai.operation.name"
+	AttrSystem             = "gen_ai.system"
+	AttrAgentName          = "gen_ai.agent.name"
+	AttrConversationID     = "gen_ai.conversation.id"
+	AttrRequestModel       = "gen_ai.request.model"
+	AttrResponseModel      = "gen_ai.response.model"
+	AttrUsageInputTokens   = "gen_ai.usage.input_tokens"
+	AttrUsageOutputTokens  = "gen_ai.usage.output_tokens"
+	AttrUsageCacheRead     = "gen_ai.usage.cache_read.input_tokens"
+	AttrUsageCacheWrite    = "gen_ai.usage.cache_creation.input_tokens"
+	AttrToolName           = "gen_ai.tool.name"
+	AttrToolCallID         = "gen_ai.tool.call.id"
+	AttrCostUSD            = "claude.cost_usd"
+	AttrSessionKind        = "claude.session.kind"
+	AttrNumTurns           = "claude.num_turns"
+	AttrResultSubtype      = "claude.result.subtype"
+	AttrDurationAPIMs      = "claude.duration_api_ms"
+	AttrControlSubtype     = "claude.control.subtype"
+	AttrHookEvent          = "claude.hook.event"
+	AttrHookCallbackID     = "claude.hook.callback_id"
+	AttrPermissionDecision = "claude.permission.decision"
+	AttrMCPServer          = "claude.mcp.server"
+)
+
+const (
+	systemName = "anthropic"
+	agentName  = "claude"
+)
+
+// Recorder records the spans and metrics of one session. A nil *Recorder
+// records nothing.
+type Recorder struct {
+	tracer  trace.Tracer
+	metrics Metrics
+	model   string
+
+	mu         sync.Mutex
+	sessionCtx context.Context
+	session    trace.Span
+	turnCtx    context.Context
+	turn       trace.Span
+	turnStart  time.Time
+	firstToken bool
+	tools      map[string]toolSpan
+
+	// costUSD is the CLI's running total at the last result message
+	costUSD float64
+}
+
+type toolSpan struct {
+	ctx  context.Context
+	span trace.Span
+	name string
+}
+
+// New creates a Recorder using tp, or the global TracerProvider when tp is nil.
+// metrics may be nil. model is reported as the requested model and may be
+// empty.
+func New(tp trace.TracerProvider, metrics Metrics, model string) *Recorder {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+
+	return &Recorder{
+		tracer:     tp.Tracer(ScopeName),
+		metrics:    metrics,
+		model:      model,
+		sessionCtx: context.Background(),
+		tools:      make(map[string]toolSpan, 4),
+	}
+}
+
+// StartSession starts the session span as a child of any span in ctx and
+// returns ctx carrying it. kind names the entry point, e.g. "query".
+func (r *Recorder) StartSession(ctx context.Context, kind string) context.Context {
+	if r == nil {
+		return ctx
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String(AttrSystem, systemName),
+		attribute.String(AttrAgentName, agentName),
+		attribute.String(AttrSessionKind, kind),
+	}
+
+	if r.model != "" {
+		attrs = append(attrs, attribute.String(AttrRequestModel, r.model))
+	}
+
+	ctx, span := r.tracer.Start(ctx, "claude.session", trace.WithAttributes(attrs...))
+	r.Add(MetricSessions, 1, Label{Name: "kind", Value: kind})
+
+	r.mu.Lock()
+	r.sessionCtx, r.session = ctx, span
+	r.mu.Unlock()
+
+	return ctx
+}
+
+// EndSession ends any open tool and turn spans and then the session span.
+// It is safe to call more than once.
+func (r *Recorder) EndSession() {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.endTurnLocked()
+
+	if r.session != nil {
+		r.session.End()
+		r.session = nil
+	}
+}
+
+// ProcessStarted counts a CLI process launch. resumed reports whether the
+// process continues or resumes an earlier session.
+func (r *Recorder) ProcessStarted(resumed bool) {
+	r.Add(MetricProcessStarts, 1, Label{Name: "resumed", Value: strconv.FormatBool(resumed)})
+}
+
+// Error records err on the session span and marks it failed. CLI process
+// failures are also counted.
+func (r *Recorder) Error(err error) {
+	if r == nil || err == nil {
+		return
+	}
+
+	if _, failed := stderrors.AsType[*errors.ProcessError](err); failed {
+		r.Add(MetricProcessFailures, 1)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.session != nil {
+		r.session.RecordError(err)
+		r.session.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// StartTurn starts a turn span if none is open. Turns also start implicitly
+// with the first message after the previous result.
+func (r *Recorder) StartTurn() {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.startTurnLocked()
+}
+
+func (r *Recorder) startTurnLocked() {
+	if r.turn != nil {
+		return
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String(AttrOperationName, "invoke_agent"),
+		attribute.String(AttrSystem, systemName),
+		attribute.String(AttrAgentName, agentName),
+	}
+
+	if r.model != "" {
+		attrs = append(attrs, attribute.String(AttrRequestModel, r.model))
+	}
+
+	r.turnCtx, r.turn = r.tracer.Start(r.sessionCtx, "invoke_agent "+agentName, trace.WithAttributes(attrs...))
+	r.turnStart, r.firstToken = time.Now(), false
+}
+
+func (r *Recorder) endTurnLocked() {
+	for id, tool := range r.tools {
+		tool.span.SetStatus(codes.Error, "no tool result before turn ended")
+		tool.span.End()
+		r.Add(MetricToolCalls, 1, Label{Name: "tool", Value: tool.name}, Label{Name: "outcome", Value: "abandoned"})
+		delete(r.tools, id)
+	}
+
+	if r.turn != nil {
+		r.turn.End()
+		r.turn, r.turnCtx = nil, nil
+	}
+}
+
+// Message updates the open spans with a message read from the CLI: assistant
+// tool_use blocks start tool spans, user tool_result blocks end them, and a
+// result message ends the turn.
+func (r *Recorder) Message(msg message.Message) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch m := msg.(type) {
+	case *message.SystemMessage:
+		if m.Subtype != "init" {
+			return
+		}
+
+		if id, ok := m.Data["session_id"].(string); ok && r.session != nil {
+			r.session.SetAttributes(attribute.String(AttrConversationID, id))
+		}
+	case *message.AssistantMessage:
+		r.startTurnLocked()
+
+		if m.Model != "" {
+			r.turn.SetAttributes(attribute.String(AttrResponseModel, m.Model))
+		}
+
+		parent := r.turnCtx
+		if m.ParentToolUseID != nil {
+			if tool, ok := r.tools[*m.ParentToolUseID]; ok {
+				parent = tool.ctx
+			}
+		}
+
+		for _, block := range m.Content {
+			if use, ok := block.(*message.ToolUseBlock); ok {
+				ctx, span := r.tracer.Start(parent, "execute_tool "+use.Name, trace.WithAttributes(
+					attribute.String(AttrOperationName, "execute_tool"),
+					attribute.String(AttrToolName, use.Name),
+					attribute.String(AttrToolCallID, use.ID),
+				))
+				r.tools[use.ID] = toolSpan{ctx: ctx, span: span, name: use.Name}
+			}
+		}
+	case *message.UserMessage:
+		r.startTurnLocked()
+
+		for _, block := range m.Content.Blocks() {
+			if result, ok := block.(*message.ToolResultBlock); ok {
+				r.endTool(result)
+			}
+		}
+	case *message.StreamEvent:
+		r.startTurnLocked()
+
+		if eventType, _ := m.Event["type"].(string); eventType == "content_block_delta" &&
+			!r.firstToken && m.ParentToolUseID == nil {
+			r.firstToken = true
+			r.Observe(MetricTimeToFirstToken, time.Since(r.turnStart).Seconds())
+		}
+	case *message.ResultMessage:
+		r.startTurnLocked()
+		r.turn.SetAttributes(resultAttributes(m)...)
+		r.recordResult(m)
+
+		if m.IsError {
+			r.turn.SetStatus(codes.Error, m.Subtype)
+		}
+
+		if m.SessionID != "" && r.session != nil {
+			r.session.SetAttributes(attribute.String(AttrConversationID, m.SessionID))
+		}
+
+		r.endTurnLocked()
+	}
+}
+
+func (r *Recorder) endTool(result *message.ToolResultBlock) {
+	tool, ok := r.tools[result.ToolUseID]
+	if !ok {
+		return
+	}
+
+	outcome := "success"
+	if result.IsError {
+		outcome = "error"
+		tool.span.SetStatus(codes.Error, "tool returned an error")
+	}
+
+	r.Add(MetricToolCalls, 1, Label{Name: "tool", Value: tool.name}, Label{Name: "outcome", Value: outcome})
+
+	tool.span.End()
+	delete(r.tools, result.ToolUseID)
+}
+
+// recordResult records the metrics of a turn's result message.
+func (r *Recorder) recordResult(m *message.ResultMessage) {
+	outcome := "success"
+	if m.IsError {
+		outcome = "error"
+	}
+
+	r.Add(MetricTurns, 1, Label{Name: "outcome", Value: outcome})
+	r.Observe(MetricTimeToResult, time.Since(r.turnStart).Seconds())
+
+	if m.Usage != nil {
+		r.Add(MetricTokens, float64(m.Usage.InputTokens), Label{Name: "type", Value: "input"})
+		r.Add(MetricTokens, float64(m.Usage.OutputTokens), Label{Name: "type", Value: "output"})
+		r.Add(MetricTokens, float64(m.Usage.CacheCreationInputTokens), Label{Name: "type", Value: "cache_write"})
+		r.Add(MetricTokens, float64(m.Usage.CacheReadInputTokens), Label{Name: "type", Value: "cache_read"})
+	}
+
+	// TotalCostUSD is the CLI's running total for the process, so only the
+	// spend since the previous result is new
+	if m.TotalCostUSD != nil && *m.TotalCostUSD > r.costUSD {
+		r.Add(MetricCost, *m.TotalCostUSD-r.costUSD)
+		r.costUSD = *m.TotalCostUSD
+	}
+}
+
+func resultAttributes(m *message.ResultMessage) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.String(AttrConversationID, m.SessionID),
+		attribute.String(AttrResultSubtype, m.Subtype),
+		attribute.Int(AttrNumTurns, m.NumTurns),
+		attribute.Int(AttrDurationAPIMs, m.DurationAPIMs),
+	}
+
+	if m.Usage != nil {
+		attrs = append(attrs,
+			attribute.Int(AttrUsageInputTokens, m.Usage.InputTokens),
+			attribute.Int(AttrUsageOutputTokens, m.Usage.OutputTokens),
+			attribute.Int(AttrUsageCacheRead, m.Usage.CacheReadInputTokens),
+			attribute.Int(AttrUsageCacheWrite, m.Usage.CacheCreationInputTokens),
+		)
+	}
+
+	if m.TotalCostUSD != nil {
+		attrs = append(attrs, attribute.Float64(AttrCostUSD, *m.TotalCostUSD))
+	}
+
+	return attrs
+}
+
+// Start starts a span for work done on behalf of the session. If ctx carries
+// no span, the span is parented to the open turn, or else the session, so
+// callbacks running on the controller's contexts still join the trace. The
+// returned context keeps ctx's deadline and cancellation.
+func (r *Recorder) Start(
+	ctx context.Context,
+	name string,
+	attrs ...attribute.KeyValue,
+) (context.Context, trace.Span) {
+	if r == nil {
+		return ctx, noop.Span{}
+	}
+
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		r.mu.Lock()
+
+		parent := r.sessionCtx
+		if r.turnCtx != nil {
+			parent = r.turnCtx
+		}
+
+		r.mu.Unlock()
+
+		ctx = trace.ContextWithSpan(ctx, trace.SpanFromContext(parent))
+	}
+
+	return r.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// Add increments a counter on the metrics sink, if any.
+func (r *Recorder) Add(name string, delta float64, labels ...Label) {
+	if r == nil || r.metrics == nil {
+		return
+	}
+
+	r.metrics.Add(name, delta, labels...)
+}
+
+// Observe records a histogram value on the metrics sink, if any.
+func (r *Recorder) Observe(name string, value float64, labels ...Label) {
+	if r == nil || r.metrics == nil {
+		return
+	}
+
+	r.metrics.Observe(name, value, labels...)
+}
+
+// End records err, if any, and ends span.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
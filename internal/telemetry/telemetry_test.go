@@ -0,0 +1,299 @@
+package telemetry
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/errors"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/message"
+)
+
+// recordingProvider is a minimal in-memory TracerProvider.
+type recordingProvider struct {
+	embedded.TracerProvider
+
+	mu     sync.Mutex
+	spans  []*recordedSpan
+	nextID byte
+}
+
+func (p *recordingProvider) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return &recordingTracer{provider: p}
+}
+
+func (p *recordingProvider) find(t *testing.T, name string) *recordedSpan {
+	t.Helper()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, s := range p.spans {
+		if s.name == name {
+			return s
+		}
+	}
+
+	require.Failf(t, "span not found", "no span named %q", name)
+
+	return nil
+}
+
+type recordingTracer struct {
+	embedded.Tracer
+
+	provider *recordingProvider
+}
+
+func (tr *recordingTracer) Start(
+	ctx context.Context,
+	name string,
+	opts ...trace.SpanStartOption,
+) (context.Context, trace.Span) {
+	p := tr.provider
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.nextID++
+	parent := trace.SpanContextFromContext(ctx)
+
+	traceID := parent.TraceID()
+	if !parent.IsValid() {
+		traceID = trace.TraceID{p.nextID}
+	}
+
+	span := &recordedSpan{
+		name:   name,
+		parent: parent.SpanID(),
+		attrs:  map[attribute.Key]attribute.Value{},
+		sc: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  trace.SpanID{p.nextID},
+		}),
+	}
+
+	cfg := trace.NewSpanStartConfig(opts...)
+	for _, kv := range cfg.Attributes() {
+		span.attrs[kv.Key] = kv.Value
+	}
+
+	p.spans = append(p.spans, span)
+
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+type recordedSpan struct {
+	embedded.Span
+
+	mu     sync.Mutex
+	name   string
+	sc     trace.SpanContext
+	parent trace.SpanID
+	attrs  map[attribute.Key]attribute.Value
+	status codes.Code
+	errs   []error
+	ended  bool
+}
+
+func (s *recordedSpan) End(...trace.SpanEndOption) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ended = true
+}
+
+func (s *recordedSpan) AddEvent(string, ...trace.EventOption) {}
+func (s *recordedSpan) AddLink(trace.Link)                    {}
+func (s *recordedSpan) IsRecording() bool                     { return true }
+func (s *recordedSpan) SpanContext() trace.SpanContext        { return s.sc }
+func (s *recordedSpan) SetName(name string)                   { s.name = name }
+func (s *recordedSpan) TracerProvider() trace.TracerProvider  { return nil }
+
+func (s *recordedSpan) RecordError(err error, _ ...trace.EventOption) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.errs = append(s.errs, err)
+}
+
+func (s *recordedSpan) SetStatus(code codes.Code, _ string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.status = code
+}
+
+func (s *recordedSpan) SetAttributes(kv ...attribute.KeyValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, a := range kv {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *recordedSpan) attr(key string) attribute.Value {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.attrs[attribute.Key(key)]
+}
+
+func TestRecorder_TurnAndToolSpans(t *testing.T) {
+	tp := &recordingProvider{}
+	rec := New(tp, nil, "claude-sonnet-4-5")
+	rec.StartSession(context.Background(), "query")
+
+	rec.Message(&message.SystemMessage{Subtype: "init", Data: map[string]any{"session_id": "sess-1"}})
+	rec.Message(&message.AssistantMessage{
+		Model: "claude-sonnet-4-5-20250929",
+		Content: []message.ContentBlock{
+			&message.TextBlock{Text: "Listing files"},
+			&message.ToolUseBlock{ID: "toolu_1", Name: "Bash"},
+		},
+	})
+	rec.Message(&message.AssistantMessage{
+		ParentToolUseID: new("toolu_1"),
+		Content:         []message.ContentBlock{&message.ToolUseBlock{ID: "toolu_2", Name: "Read"}},
+	})
+	rec.Message(&message.UserMessage{Content: message.NewUserMessageContentBlocks([]message.ContentBlock{
+		&message.ToolResultBlock{ToolUseID: "toolu_1", IsError: true},
+	})})
+	rec.Message(&message.ResultMessage{
+		Subtype:      "success",
+		SessionID:    "sess-1",
+		NumTurns:     2,
+		TotalCostUSD: new(0.0123),
+		Usage:        &message.Usage{InputTokens: 120, OutputTokens: 45},
+	})
+	rec.EndSession()
+
+	session := tp.find(t, "claude.session")
+	turn := tp.find(t, "invoke_agent claude")
+	bash := tp.find(t, "execute_tool Bash")
+	read := tp.find(t, "execute_tool Read")
+
+	require.True(t, session.ended)
+	require.Equal(t, "sess-1", session.attr(AttrConversationID).AsString())
+	require.Equal(t, "claude-sonnet-4-5", session.attr(AttrRequestModel).AsString())
+
+	require.True(t, turn.ended)
+	require.Equal(t, session.sc.SpanID(), turn.parent)
+	require.Equal(t, "claude-sonnet-4-5-20250929", turn.attr(AttrResponseModel).AsString())
+	require.Equal(t, int64(120), turn.attr(AttrUsageInputTokens).AsInt64())
+	require.Equal(t, int64(45), turn.attr(AttrUsageOutputTokens).AsInt64())
+	require.InDelta(t, 0.0123, turn.attr(AttrCostUSD).AsFloat64(), 1e-9)
+	require.Equal(t, codes.Unset, turn.status)
+
+	require.Equal(t, turn.sc.SpanID(), bash.parent)
+	require.Equal(t, "toolu_1", bash.attr(AttrToolCallID).AsString())
+	require.Equal(t, codes.Error, bash.status, "tool_result reported an error")
+
+	require.Equal(t, bash.sc.SpanID(), read.parent, "subagent tool calls nest under the Task tool")
+	require.True(t, read.ended, "unfinished tools end with the turn")
+	require.Equal(t, codes.Error, read.status)
+}
+
+func TestRecorder_StartParentsToOpenTurn(t *testing.T) {
+	tp := &recordingProvider{}
+	rec := New(tp, nil, "")
+	rec.StartSession(context.Background(), "client")
+
+	_, span := rec.Start(context.Background(), "claude.control interrupt")
+	End(span, nil)
+
+	rec.StartTurn()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hookCtx, span := rec.Start(ctx, "claude.hook PreToolUse")
+	require.NoError(t, hookCtx.Err())
+	cancel()
+	require.Error(t, hookCtx.Err(), "the span context keeps the caller's cancellation")
+	End(span, stderrors.New("boom"))
+
+	session := tp.find(t, "claude.session")
+	turn := tp.find(t, "invoke_agent claude")
+	control := tp.find(t, "claude.control interrupt")
+	hook := tp.find(t, "claude.hook PreToolUse")
+
+	require.Equal(t, session.sc.SpanID(), control.parent)
+	require.Equal(t, turn.sc.SpanID(), hook.parent)
+	require.Equal(t, codes.Error, hook.status)
+	require.Len(t, hook.errs, 1)
+	require.True(t, hook.ended)
+}
+
+func TestRecorder_Nil(t *testing.T) {
+	var rec *Recorder
+
+	ctx := rec.StartSession(context.Background(), "query")
+	rec.StartTurn()
+	rec.Message(&message.ResultMessage{})
+	rec.Error(stderrors.New("boom"))
+	rec.EndSession()
+
+	_, span := rec.Start(ctx, "claude.control initialize")
+	require.False(t, span.IsRecording())
+	End(span, nil)
+}
+
+func TestRecorder_Metrics(t *testing.T) {
+	metrics := NewCollector()
+	rec := New(&recordingProvider{}, metrics, "")
+	rec.StartSession(context.Background(), "client")
+	rec.ProcessStarted(true)
+	rec.StartTurn()
+
+	rec.Message(&message.StreamEvent{Event: map[string]any{"type": "message_start"}})
+	rec.Message(&message.StreamEvent{Event: map[string]any{"type": "content_block_delta"}})
+	rec.Message(&message.StreamEvent{Event: map[string]any{"type": "content_block_delta"}})
+	rec.Message(&message.AssistantMessage{Content: []message.ContentBlock{
+		&message.ToolUseBlock{ID: "toolu_1", Name: "Bash"},
+		&message.ToolUseBlock{ID: "toolu_2", Name: "Bash"},
+		&message.ToolUseBlock{ID: "toolu_3", Name: "Read"},
+	}})
+	rec.Message(&message.UserMessage{Content: message.NewUserMessageContentBlocks([]message.ContentBlock{
+		&message.ToolResultBlock{ToolUseID: "toolu_1"},
+		&message.ToolResultBlock{ToolUseID: "toolu_2", IsError: true},
+	})})
+	rec.Message(&message.ResultMessage{
+		Subtype:      "success",
+		TotalCostUSD: new(0.5),
+		Usage:        &message.Usage{InputTokens: 100, OutputTokens: 20},
+	})
+	// The CLI reports its running total, so the second turn cost 0.25
+	rec.Message(&message.ResultMessage{Subtype: "error_max_turns", IsError: true, TotalCostUSD: new(0.75)})
+	rec.Error(fmt.Errorf("read: %w", &errors.ProcessError{ExitCode: 1}))
+	rec.EndSession()
+
+	outcome := func(v string) Label { return Label{Name: "outcome", Value: v} }
+	tool := func(v string) Label { return Label{Name: "tool", Value: v} }
+
+	require.InDelta(t, 1.0, metrics.Counter(MetricSessions, Label{Name: "kind", Value: "client"}), 0)
+	require.InDelta(t, 1.0, metrics.Counter(MetricProcessStarts, Label{Name: "resumed", Value: "true"}), 0)
+	require.InDelta(t, 1.0, metrics.Counter(MetricProcessFailures), 0)
+	require.InDelta(t, 1.0, metrics.Counter(MetricToolCalls, tool("Bash"), outcome("success")), 0)
+	require.InDelta(t, 1.0, metrics.Counter(MetricToolCalls, outcome("error"), tool("Bash")), 0)
+	require.InDelta(t, 1.0, metrics.Counter(MetricToolCalls, tool("Read"), outcome("abandoned")), 0)
+	require.InDelta(t, 1.0, metrics.Counter(MetricTurns, outcome("success")), 0)
+	require.InDelta(t, 1.0, metrics.Counter(MetricTurns, outcome("error")), 0)
+	require.InDelta(t, 100.0, metrics.Counter(MetricTokens, Label{Name: "type", Value: "input"}), 0)
+	require.InDelta(t, 20.0, metrics.Counter(MetricTokens, Label{Name: "type", Value: "output"}), 0)
+	require.InDelta(t, 0.75, metrics.Counter(MetricCost), 1e-9)
+
+	var out strings.Builder
+	require.NoError(t, metrics.WritePrometheus(&out))
+	require.Contains(t, out.String(), MetricTimeToFirstToken+"_count 1\n", "only the first delta of a turn counts")
+	require.Contains(t, out.String(), MetricTimeToResult+"_count 2\n")
+}
@@ -0,0 +1,55 @@
+package claudesdk
+
+import "github.com/wagiedev/claude-agent-sdk-go/internal/telemetry"
+
+// Metrics receives counters and histograms describing agent runs. Pass an
+// implementation to WithMetrics; MetricsCollector is a ready-made one.
+type Metrics = telemetry.Metrics
+
+// MetricLabel is a metric dimension.
+type MetricLabel = telemetry.Label
+
+// MetricsCollector is an in-memory Metrics implementation that serves the
+// Prometheus text format over HTTP and implements expvar.Var:
+//
+//	metrics := claudesdk.NewMetricsCollector()
+//	http.Handle("/metrics", metrics)
+//	expvar.Publish("claude", metrics)
+type MetricsCollector = telemetry.Collector
+
+// NewMetricsCollector creates a MetricsCollector with the given histogram
+// bucket upper bounds in seconds, or DefaultMetricBuckets if none are given.
+var NewMetricsCollector = telemetry.NewCollector
+
+// DefaultMetricBuckets are the histogram buckets used by NewMetricsCollector
+// when none are given.
+var DefaultMetricBuckets = telemetry.DefaultBuckets
+
+// Metric names recorded by the SDK, with their labels.
+const (
+	// MetricSessions counts started sessions by kind (query, query_stream, client).
+	MetricSessions = telemetry.MetricSessions
+	// MetricProcessStarts counts CLI process launches by resumed (true, false).
+	MetricProcessStarts = telemetry.MetricProcessStarts
+	// MetricProcessFailures counts CLI processes that exited with an error.
+	MetricProcessFailures = telemetry.MetricProcessFailures
+	// MetricTurns counts completed turns by outcome (success, error).
+	MetricTurns = telemetry.MetricTurns
+	// MetricToolCalls counts tool calls by tool and outcome (success, error, abandoned).
+	MetricToolCalls = telemetry.MetricToolCalls
+	// MetricPermissionDecisions counts CanUseTool decisions by tool and behavior (allow, deny).
+	MetricPermissionDecisions = telemetry.MetricPermissionDecisions
+	// MetricHookDuration observes hook callback latency in seconds by event and outcome.
+	MetricHookDuration = telemetry.MetricHookDuration
+	// MetricControlTimeouts counts control requests that timed out, by subtype.
+	MetricControlTimeouts = telemetry.MetricControlTimeouts
+	// MetricTokens counts tokens by type (input, output, cache_write, cache_read).
+	MetricTokens = telemetry.MetricTokens
+	// MetricCost counts spend in USD reported by result messages.
+	MetricCost = telemetry.MetricCost
+	// MetricTimeToFirstToken observes seconds from turn start to the first
+	// streamed token. Requires WithIncludePartialMessages.
+	MetricTimeToFirstToken = telemetry.MetricTimeToFirstToken
+	// MetricTimeToResult observes seconds from turn start to the result message.
+	MetricTimeToResult = telemetry.MetricTimeToResult
+)
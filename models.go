@@ -40,8 +40,8 @@ func Models() []Model {
 	return models.All()
 }
 
-// ModelByID looks up a model by ID, alias, or dated prefix.
-// Returns nil if no model is found.
+// ModelByID looks up a model by ID, alias, provider-specific ID, or dated
+// prefix. Returns nil if no model is found.
 func ModelByID(id string) *Model {
 	return models.ByID(id)
 }
@@ -51,8 +51,88 @@ func ModelsByCostTier(tier ModelCostTier) []Model {
 	return models.ByCostTier(tier)
 }
 
+// ModelProvider identifies a platform serving Claude models.
+type ModelProvider = models.Provider
+
+const (
+	// ModelProviderAnthropic is the Anthropic API.
+	ModelProviderAnthropic = models.ProviderAnthropic
+	// ModelProviderBedrock is Amazon Bedrock.
+	ModelProviderBedrock = models.ProviderBedrock
+	// ModelProviderVertex is Google Vertex AI.
+	ModelProviderVertex = models.ProviderVertex
+)
+
+// ProviderModelID maps a catalog model ID or alias to its ID on provider.
+// Unknown and already provider-specific IDs are returned unchanged.
+func ProviderModelID(provider ModelProvider, id string) string {
+	return models.ProviderID(provider, id)
+}
+
 // ModelCapabilities returns capability strings for the given model ID.
 // Returns nil if the model is not found.
 func ModelCapabilities(modelID string) []string {
 	return models.Capabilities(modelID)
 }
+
+// ModelPricing holds per-million-token prices in USD.
+type ModelPricing = models.Pricing
+
+// CostBreakdown is an itemized cost in USD.
+type CostBreakdown = models.Cost
+
+// PriceTable maps model IDs to prices.
+type PriceTable = models.PriceTable
+
+// CostEntry is the estimated cost of one API message.
+type CostEntry = models.CostEntry
+
+// CostTracker attributes estimated cost to models, turns and subagents from
+// the message stream:
+//
+//	tracker := claudesdk.NewCostTracker(nil)
+//	for msg, err := range claudesdk.Query(ctx, prompt) {
+//	    // handle err
+//	    tracker.Observe(msg)
+//	}
+//	fmt.Println(tracker.BySubagent())
+type CostTracker = models.CostTracker
+
+// PricingVersion identifies the built-in price list.
+const PricingVersion = models.PricingVersion
+
+// ErrNoPricing is returned when a model has no price in the price table.
+var ErrNoPricing = models.ErrNoPricing
+
+// NewCostTracker creates a CostTracker. A nil table uses the current price
+// table.
+func NewCostTracker(table *PriceTable) *CostTracker {
+	return models.NewCostTracker(table)
+}
+
+// DefaultPriceTable returns the built-in price table.
+func DefaultPriceTable() *PriceTable {
+	return models.DefaultPriceTable()
+}
+
+// LoadPriceTable reads a JSON price table and merges it over the built-in one.
+func LoadPriceTable(path string) (*PriceTable, error) {
+	return models.LoadPriceTable(path)
+}
+
+// SetPriceTable replaces the price table used by EstimateCost and
+// NewCostTracker(nil). A nil table restores the built-in one.
+func SetPriceTable(table *PriceTable) {
+	models.SetPriceTable(table)
+}
+
+// ModelPrice returns the current price of a model by ID, alias, or dated ID.
+func ModelPrice(modelID string) (ModelPricing, bool) {
+	return models.PriceFor(modelID)
+}
+
+// EstimateCost prices usage of a model with the current price table.
+// It returns ErrNoPricing for models without a price.
+func EstimateCost(modelID string, usage Usage) (CostBreakdown, error) {
+	return models.EstimateCost(modelID, usage)
+}
@@ -2,8 +2,11 @@ package claudesdk
 
 import (
 	"log/slog"
+	"os/exec"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/wagiedev/claude-agent-sdk-go/internal/config"
 )
 
@@ -31,6 +34,65 @@ func WithLogger(logger *slog.Logger) Option {
 	}
 }
 
+// WithTracerProvider sets the OpenTelemetry TracerProvider used to trace
+// sessions, turns, tool calls, permission decisions, hook callbacks, SDK MCP
+// calls and control requests. If not set, the global provider is used, which
+// records nothing unless the application installs one.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(o *ClaudeAgentOptions) {
+		o.TracerProvider = provider
+	}
+}
+
+// WithMetrics records counters and histograms for sessions, turns, tool calls,
+// permission decisions, hook latency, control request timeouts, CLI process
+// launches, tokens and cost. See NewMetricsCollector for a Prometheus and
+// expvar compatible implementation.
+func WithMetrics(metrics Metrics) Option {
+	return func(o *ClaudeAgentOptions) {
+		o.Metrics = metrics
+	}
+}
+
+// WithBudget charges the run's spend to a Budget shared with other queries and
+// clients. The run fails with ErrBudgetExhausted if the budget is already
+// spent, and is interrupted when spend reaches the limit while it runs.
+// Unlike WithMaxBudgetUSD, which the CLI enforces per process, the budget
+// covers every run it is passed to.
+func WithBudget(b *Budget) Option {
+	return func(o *ClaudeAgentOptions) {
+		o.Budget = b
+	}
+}
+
+// WithRateLimiter makes the run wait for a slot in a Limiter shared with
+// other queries and clients before it launches the CLI. A client holds its
+// slot until Close.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(o *ClaudeAgentOptions) {
+		o.RateLimiter = limiter
+	}
+}
+
+// WithCircuitBreaker makes the run fail fast with ErrCircuitOpen while a
+// CircuitBreaker shared with other queries and clients is open, and reports
+// the run's rate limit, server and CLI process failures to it.
+func WithCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(o *ClaudeAgentOptions) {
+		o.CircuitBreaker = breaker
+	}
+}
+
+// WithRetry retries Query with exponential backoff and jitter after
+// retryable failures such as rate limits and overloaded errors. Once the
+// failed attempt has a session ID, the retry resumes that session, so work
+// already done is kept. QueryStream and Client are not retried.
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *ClaudeAgentOptions) {
+		o.Retry = &policy
+	}
+}
+
 // WithSystemPrompt sets the system message to send to Claude.
 func WithSystemPrompt(prompt string) Option {
 	return func(o *ClaudeAgentOptions) {
@@ -53,6 +115,20 @@ func WithModel(model string) Option {
 	}
 }
 
+// WithProvider selects the platform serving the model. The provider sets
+// the CLI's environment and maps catalog models and aliases passed to
+// WithModel and WithFallbackModel to its model IDs:
+//
+//	claudesdk.WithProvider(claudesdk.BedrockProvider{Region: "us-east-1", InferenceProfile: "us"}),
+//	claudesdk.WithModel("claude-sonnet-4-5"), // us.anthropic.claude-sonnet-4-5-20250929-v1:0
+//
+// Start fails with ErrInvalidProvider if required settings are missing.
+func WithProvider(provider Provider) Option {
+	return func(o *ClaudeAgentOptions) {
+		o.Provider = provider
+	}
+}
+
 // WithPermissionMode controls how permissions are handled.
 // Valid values: "default", "acceptEdits", "plan", "bypassPermissions".
 func WithPermissionMode(mode string) Option {
@@ -90,6 +166,22 @@ func WithEnv(env map[string]string) Option {
 	}
 }
 
+// WithEnvPolicy limits the host environment the CLI and its commands see,
+// keeping credentials of the host process away from the agent:
+//
+//	claudesdk.WithEnvPolicy(&claudesdk.EnvPolicy{
+//	    Mode:     claudesdk.EnvAllowlist,
+//	    Allow:    []string{"GOPATH", "GOCACHE"},
+//	    Required: []string{"ANTHROPIC_API_KEY"},
+//	})
+//
+// Variables set with WithEnv are always passed.
+func WithEnvPolicy(policy *EnvPolicy) Option {
+	return func(o *ClaudeAgentOptions) {
+		o.EnvPolicy = policy
+	}
+}
+
 // WithUser sets a user identifier for tracking purposes.
 func WithUser(user string) Option {
 	return func(o *ClaudeAgentOptions) {
@@ -106,6 +198,16 @@ func WithHooks(hooks map[HookEvent][]*HookMatcher) Option {
 	}
 }
 
+// WithHookRegistry enables hooks that can be added and removed after the
+// session starts. Each registry event is registered with the CLI as a
+// catch-all matcher and dispatched in Go; use Client.AddHook and
+// Client.RemoveHook (or the registry directly) to change hooks at runtime.
+func WithHookRegistry(registry *HookRegistry) Option {
+	return func(o *ClaudeAgentOptions) {
+		o.HookRegistry = registry
+	}
+}
+
 // ===== Token/Budget =====
 
 // WithThinking sets the thinking configuration.
@@ -192,6 +294,18 @@ func WithCanUseTool(callback ToolPermissionCallback) Option {
 	}
 }
 
+// WithPermissionCache remembers CanUseTool decisions so repeated requests for
+// the same tool and normalized input skip the callback.
+// Turn-scoped entries are cleared after each result and session-scoped
+// entries when the query or client ends; a cache shared by several queries
+// or clients keeps their turn- and session-scoped entries separate.
+// Requires WithCanUseTool.
+func WithPermissionCache(cache *PermissionCache) Option {
+	return func(o *ClaudeAgentOptions) {
+		o.PermissionCache = cache
+	}
+}
+
 // ===== Session =====
 
 // WithContinueConversation indicates whether to continue an existing conversation.
@@ -215,6 +329,15 @@ func WithForkSession(fork bool) Option {
 	}
 }
 
+// WithResumeSessionAt resumes the session only up to and including the
+// assistant message with the given UUID. Combine with WithResume and
+// WithForkSession to branch a conversation from an earlier point.
+func WithResumeSessionAt(messageUUID string) Option {
+	return func(o *ClaudeAgentOptions) {
+		o.ResumeSessionAt = messageUUID
+	}
+}
+
 // ===== Advanced =====
 
 // WithFallbackModel specifies a model to use if the primary model fails.
@@ -238,13 +361,32 @@ func WithPermissionPromptToolName(name string) Option {
 	}
 }
 
-// WithSettings sets the path to a settings file to load.
+// WithSettings sets the path to a settings file to load, or settings JSON.
+// It is merged with WithTypedSettings and WithSandboxSettings when those are
+// also set.
 func WithSettings(path string) Option {
 	return func(o *ClaudeAgentOptions) {
 		o.Settings = path
 	}
 }
 
+// WithTypedSettings sets CLI settings in code. They are merged with the
+// settings from WithSettings, which they override, and WithSandboxSettings,
+// which overrides them: objects merge key by key, lists such as permission
+// rules are combined, and other values are replaced.
+//
+//	claudesdk.WithSettings(".claude/team-settings.json"),
+//	claudesdk.WithTypedSettings(&claudesdk.Settings{
+//	    Permissions: &claudesdk.PermissionSettings{
+//	        Deny: []string{"Read(./.env)", "Bash(curl:*)"},
+//	    },
+//	}),
+func WithTypedSettings(settings *Settings) Option {
+	return func(o *ClaudeAgentOptions) {
+		o.TypedSettings = settings
+	}
+}
+
 // WithAddDirs adds additional directories to make accessible.
 func WithAddDirs(dirs ...string) Option {
 	return func(o *ClaudeAgentOptions) {
@@ -331,6 +473,16 @@ func WithEnableFileCheckpointing(enable bool) Option {
 	}
 }
 
+// WithCheckpoints records a checkpoint in registry for each prompt and enables
+// file checkpointing, so Client.UndoLastTurn can rewind files and fork the
+// session to before the last prompt.
+func WithCheckpoints(registry *CheckpointRegistry) Option {
+	return func(o *ClaudeAgentOptions) {
+		o.Checkpoints = registry
+		o.EnableFileCheckpointing = true
+	}
+}
+
 // WithInitializeTimeout sets the timeout for the initialize control request.
 func WithInitializeTimeout(timeout time.Duration) Option {
 	return func(o *ClaudeAgentOptions) {
@@ -361,6 +513,35 @@ func WithSDKTools(tools ...Tool) Option {
 	}
 }
 
+// WithWrapper launches the CLI through a wrapper command, such as nice,
+// systemd-run, bwrap or nsjail, for isolation beyond the CLI's own sandbox.
+// The CLI path and arguments are appended to the wrapper's arguments:
+//
+//	claudesdk.WithWrapper("systemd-run", "--user", "--scope", "-p", "MemoryMax=4G", "--")
+func WithWrapper(command ...string) Option {
+	return func(o *ClaudeAgentOptions) {
+		o.Wrapper = command
+	}
+}
+
+// WithLauncher sets a function that can change the CLI command before it is
+// started, for example to set credentials, join a cgroup or pass extra
+// files. Stdin, stdout and stderr must be left alone.
+func WithLauncher(launcher func(cmd *exec.Cmd) error) Option {
+	return func(o *ClaudeAgentOptions) {
+		o.Launcher = launcher
+	}
+}
+
+// WithProcessOptions configures how the CLI subprocess is run and stopped:
+// the grace period Close gives it to exit after SIGTERM, and resource limits
+// for it and the commands it starts.
+func WithProcessOptions(process *ProcessOptions) Option {
+	return func(o *ClaudeAgentOptions) {
+		o.Process = process
+	}
+}
+
 // WithTransport injects a custom transport implementation.
 // The transport must implement the Transport interface.
 func WithTransport(transport config.Transport) Option {
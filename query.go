@@ -14,12 +14,16 @@ import (
 
 	"golang.org/x/sync/errgroup"
 
+	"github.com/wagiedev/claude-agent-sdk-go/internal/budget"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/config"
 	sdkerrors "github.com/wagiedev/claude-agent-sdk-go/internal/errors"
 	internalmcp "github.com/wagiedev/claude-agent-sdk-go/internal/mcp"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/message"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/protocol"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/ratelimit"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/retry"
 	"github.com/wagiedev/claude-agent-sdk-go/internal/subprocess"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/telemetry"
 )
 
 const (
@@ -97,6 +101,122 @@ func hasSDKMCPServer(options *ClaudeAgentOptions) bool {
 	return false
 }
 
+// endPermissionCacheTurn clears the session's turn-scoped permission cache
+// entries once a turn's result message arrives.
+func endPermissionCacheTurn(permissions *PermissionCacheSession, msg Message) {
+	if _, isResult := msg.(*message.ResultMessage); isResult {
+		permissions.EndTurn()
+	}
+}
+
+// traceYield wraps yield so every message and error it receives is recorded
+// by rec.
+func traceYield(rec *telemetry.Recorder, yield func(Message, error) bool) func(Message, error) bool {
+	return func(msg Message, err error) bool {
+		if err != nil {
+			rec.Error(err)
+		} else {
+			rec.Message(msg)
+		}
+
+		return yield(msg, err)
+	}
+}
+
+// admit waits for a rate limiter slot and checks the circuit breaker before
+// a run launches the CLI.
+func admit(ctx context.Context, options *ClaudeAgentOptions) (*ratelimit.Permit, error) {
+	permit, err := options.RateLimiter.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := options.CircuitBreaker.Allow(); err != nil {
+		permit.Release()
+
+		return nil, err
+	}
+
+	return permit, nil
+}
+
+// guardYield wraps yield so token usage reaches the rate limiter and failures
+// and successes reach the circuit breaker.
+func guardYield(
+	options *ClaudeAgentOptions,
+	permit *ratelimit.Permit,
+	yield func(Message, error) bool,
+) func(Message, error) bool {
+	return func(msg Message, err error) bool {
+		if err != nil {
+			options.CircuitBreaker.Error(err)
+		} else {
+			options.CircuitBreaker.Observe(msg)
+			permit.Observe(msg)
+		}
+
+		return yield(msg, err)
+	}
+}
+
+// classifyYield wraps yield so errors reach it classified.
+func classifyYield(yield func(Message, error) bool) func(Message, error) bool {
+	return func(msg Message, err error) bool {
+		return yield(msg, retry.Classify(err))
+	}
+}
+
+// budgetControlTimeout bounds the control requests a budget sends to a run.
+const budgetControlTimeout = 5 * time.Second
+
+// queryControls lets a budget steer a Query or QueryStream run. One-shot
+// queries have no control channel, so they are interrupted by cancelling the
+// run and cannot change models.
+type queryControls struct {
+	controller *protocol.Controller
+	cancel     context.CancelCauseFunc
+	provider   config.Provider
+}
+
+// Interrupt stops the run's current turn.
+func (q *queryControls) Interrupt(ctx context.Context) error {
+	if q.controller == nil {
+		q.cancel(sdkerrors.ErrBudgetExhausted)
+
+		return nil
+	}
+
+	if _, err := q.controller.SendRequest(ctx, "interrupt", nil, budgetControlTimeout); err != nil {
+		return fmt.Errorf("send interrupt signal: %w", err)
+	}
+
+	return nil
+}
+
+// SetModel switches the run's model, mapped to the provider's ID.
+func (q *queryControls) SetModel(ctx context.Context, model *string) error {
+	if q.controller == nil {
+		return fmt.Errorf("set model in one-shot query: %w", errors.ErrUnsupported)
+	}
+
+	if model != nil {
+		model = new(config.ModelID(q.provider, *model))
+	}
+
+	payload := map[string]any{"model": model}
+
+	if _, err := q.controller.SendRequest(ctx, "set_model", payload, budgetControlTimeout); err != nil {
+		return fmt.Errorf("set model: %w", err)
+	}
+
+	return nil
+}
+
+// isResumed reports whether options continue or resume an earlier session.
+func isResumed(options *ClaudeAgentOptions) bool {
+	return options.Resume != "" || options.ContinueConversation
+}
+
 // queryRequiresStreamingMode returns true when Query needs bidirectional stdin.
 // This is required for initialize/control callbacks used by hooks, can_use_tool,
 // in-process SDK MCP servers, and agent definitions.
@@ -171,6 +291,19 @@ func Query(
 	ctx context.Context,
 	prompt string,
 	opts ...Option,
+) iter.Seq2[Message, error] {
+	if policy := applyAgentOptions(opts).Retry; policy != nil {
+		return retryQuery(ctx, prompt, opts, policy)
+	}
+
+	return query(ctx, prompt, opts...)
+}
+
+// query runs a single Query attempt.
+func query(
+	ctx context.Context,
+	prompt string,
+	opts ...Option,
 ) iter.Seq2[Message, error] {
 	return func(yield func(Message, error) bool) {
 		// Apply options
@@ -206,6 +339,43 @@ func Query(
 		log = log.With("component", "query")
 		log.Debug("Starting query execution")
 
+		rec := telemetry.New(options.TracerProvider, options.Metrics, options.Model)
+		ctx = rec.StartSession(ctx, "query")
+		yield = traceYield(rec, yield)
+
+		defer rec.EndSession()
+
+		spend, err := options.Budget.Start()
+		if err != nil {
+			yield(nil, err)
+
+			return
+		}
+
+		defer spend.End()
+
+		permit, err := admit(ctx, options)
+		if err != nil {
+			yield(nil, err)
+
+			return
+		}
+
+		defer permit.Release()
+
+		yield = classifyYield(guardYield(options, permit, yield))
+
+		// One-shot queries are interrupted by cancelling the run
+		var cancel context.CancelCauseFunc
+
+		ctx, cancel = context.WithCancelCause(ctx)
+		defer cancel(nil)
+
+		// Set once the protocol session exists; cleared after the transport closes
+		var permissions *PermissionCacheSession
+
+		defer func() { permissions.EndSession() }()
+
 		// Create or use injected transport
 		var transport config.Transport
 
@@ -233,8 +403,15 @@ func Query(
 
 		log.Info("Successfully started Claude CLI")
 
+		rec.ProcessStarted(isResumed(options))
+
+		// The prompt is on the command line, so the turn starts with the process
+		rec.StartTurn()
+
 		// Create protocol controller for bidirectional communication
 		controller := protocol.NewController(log, transport)
+		controller.SetRecorder(rec)
+
 		if err := controller.Start(ctx); err != nil {
 			yield(nil, fmt.Errorf("start protocol controller: %w", err))
 
@@ -243,8 +420,11 @@ func Query(
 
 		defer controller.Stop()
 
+		spend.Attach(ctx, &queryControls{cancel: cancel})
+
 		// Create session for protocol handling
 		session := protocol.NewSession(log, controller, options)
+		permissions = session.PermissionCache()
 		session.RegisterMCPServers()
 		session.RegisterHandlers()
 
@@ -305,6 +485,9 @@ func Query(
 					continue
 				}
 
+				endPermissionCacheTurn(permissions, parsed)
+				spend.Observe(parsed)
+
 				// Yield parsed message
 				if !yield(parsed, nil) {
 					log.Debug("Yield returned false, stopping iteration")
@@ -325,7 +508,7 @@ func Query(
 
 			case <-ctx.Done():
 				log.Debug("Context cancelled")
-				yield(nil, ctx.Err())
+				yield(nil, context.Cause(ctx))
 
 				return
 			}
@@ -343,6 +526,8 @@ func streamInputMessages(
 	hasMCPOrHooks bool,
 	resultReceived <-chan struct{},
 	streamCloseTimeout time.Duration,
+	rec *telemetry.Recorder,
+	spend *budget.Session,
 ) (err error) {
 	defer func() {
 		if endErr := transport.EndInput(); endErr != nil {
@@ -368,6 +553,12 @@ func streamInputMessages(
 			return fmt.Errorf("marshal streaming message: %w", err)
 		}
 
+		if err := spend.Check(); err != nil {
+			return err
+		}
+
+		rec.StartTurn()
+
 		if err := transport.SendMessage(ctx, data); err != nil {
 			log.Error("Failed to send streaming message", "error", err)
 
@@ -466,6 +657,37 @@ func QueryStream(
 		log := getLoggerWithComponent(options, "query_stream")
 		log.Debug("Starting streaming query execution")
 
+		rec := telemetry.New(options.TracerProvider, options.Metrics, options.Model)
+		ctx = rec.StartSession(ctx, "query_stream")
+		yield = traceYield(rec, yield)
+
+		defer rec.EndSession()
+
+		spend, err := options.Budget.Start()
+		if err != nil {
+			yield(nil, err)
+
+			return
+		}
+
+		defer spend.End()
+
+		permit, err := admit(ctx, options)
+		if err != nil {
+			yield(nil, err)
+
+			return
+		}
+
+		defer permit.Release()
+
+		yield = classifyYield(guardYield(options, permit, yield))
+
+		// Set once the protocol session exists; cleared after the transport closes
+		var permissions *PermissionCacheSession
+
+		defer func() { permissions.EndSession() }()
+
 		// Create transport
 		transport := createStreamingTransport(log, options)
 
@@ -483,8 +705,12 @@ func QueryStream(
 
 		log.Info("Successfully started Claude CLI in streaming mode")
 
+		rec.ProcessStarted(isResumed(options))
+
 		// Create protocol controller for bidirectional communication
 		controller := protocol.NewController(log, transport)
+		controller.SetRecorder(rec)
+
 		if err := controller.Start(ctx); err != nil {
 			yield(nil, fmt.Errorf("start protocol controller: %w", err))
 
@@ -493,8 +719,11 @@ func QueryStream(
 
 		defer controller.Stop()
 
+		spend.Attach(ctx, &queryControls{controller: controller, provider: options.Provider})
+
 		// Create session for protocol handling
 		session := protocol.NewSession(log, controller, options)
+		permissions = session.PermissionCache()
 		session.RegisterMCPServers()
 		session.RegisterHandlers()
 
@@ -550,6 +779,8 @@ func QueryStream(
 				hasMCPOrHooks,
 				resultReceived,
 				streamCloseTimeout,
+				rec,
+				spend,
 			)
 		})
 
@@ -603,6 +834,9 @@ func QueryStream(
 					}
 				}
 
+				endPermissionCacheTurn(permissions, parsed)
+				spend.Observe(parsed)
+
 				if !yield(parsed, nil) {
 					log.Debug("Yield returned false, stopping iteration")
 
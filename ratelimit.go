@@ -0,0 +1,47 @@
+package claudesdk
+
+import "github.com/wagiedev/claude-agent-sdk-go/internal/ratelimit"
+
+// RateLimiter paces CLI launches across many Query, QueryStream and Client
+// runs by requests per minute, tokens per minute and concurrent processes.
+// Runs wait for a slot; pass the limiter to each with WithRateLimiter:
+//
+//	limiter := claudesdk.NewRateLimiter(claudesdk.RateLimiterConfig{
+//	    RequestsPerMinute: 50,
+//	    TokensPerMinute:   400_000,
+//	    MaxConcurrent:     8,
+//	})
+type RateLimiter = ratelimit.Limiter
+
+// RateLimiterConfig sets the limits of a RateLimiter. A zero field is unlimited.
+type RateLimiterConfig = ratelimit.LimiterConfig
+
+// RateLimitPermit is a run admitted by a RateLimiter.
+type RateLimitPermit = ratelimit.Permit
+
+// NewRateLimiter creates a RateLimiter with the given limits.
+var NewRateLimiter = ratelimit.NewLimiter
+
+// CircuitBreaker stops new runs after repeated rate limit errors, server
+// errors or CLI process failures. While open, runs fail with ErrCircuitOpen;
+// after the cooldown one probe run goes through and its result closes or
+// reopens the circuit. Pass it to each run with WithCircuitBreaker.
+type CircuitBreaker = ratelimit.Breaker
+
+// CircuitBreakerConfig configures a CircuitBreaker.
+type CircuitBreakerConfig = ratelimit.BreakerConfig
+
+// CircuitBreakerState is the state of a CircuitBreaker.
+type CircuitBreakerState = ratelimit.BreakerState
+
+const (
+	// CircuitBreakerClosed lets every run through.
+	CircuitBreakerClosed = ratelimit.BreakerClosed
+	// CircuitBreakerOpen rejects runs until the cooldown has passed.
+	CircuitBreakerOpen = ratelimit.BreakerOpen
+	// CircuitBreakerHalfOpen lets one probe run through.
+	CircuitBreakerHalfOpen = ratelimit.BreakerHalfOpen
+)
+
+// NewCircuitBreaker creates a closed CircuitBreaker.
+var NewCircuitBreaker = ratelimit.NewBreaker
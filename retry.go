@@ -0,0 +1,137 @@
+package claudesdk
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"time"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/errors"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/retry"
+)
+
+// ClassifiedError is a run failure sorted into a failure class such as
+// ErrRateLimited. Query, QueryStream and Client return CLI process failures
+// classified from their stderr, so callers can write:
+//
+//	if errors.Is(err, claudesdk.ErrRateLimited) {
+//	    time.Sleep(claudesdk.RetryAfter(err))
+//	}
+//
+// errors.As still finds the underlying *ProcessError.
+type ClassifiedError = errors.ClassifiedError
+
+// RetryPolicy controls how WithRetry retries a Query. Zero fields take the
+// defaults: 3 attempts, backoff from 1s doubling up to 1m, and 20% jitter.
+type RetryPolicy = retry.Policy
+
+// ClassifyError sorts err into a failure class, returning a *ClassifiedError,
+// or err unchanged if it matches no class.
+var ClassifyError = retry.Classify
+
+// ClassifyMessage returns the failure an assistant or result message reports
+// as a *ClassifiedError, or nil. lastErr is the failure of an earlier
+// assistant message in the same run, which failed results without details are
+// attributed to; it may be nil.
+var ClassifyMessage = retry.ClassifyMessage
+
+// IsRetryable reports whether err is a classified failure that retrying may
+// fix.
+var IsRetryable = retry.IsRetryable
+
+// RetryAfter returns the wait hint of a classified failure, or zero.
+var RetryAfter = retry.RetryAfter
+
+// retryQuery runs Query attempts until one finishes without a retryable
+// failure or the policy's attempts run out. Messages of every attempt are
+// yielded, except the failure that causes a retry.
+func retryQuery(
+	ctx context.Context,
+	prompt string,
+	opts []Option,
+	policy *retry.Policy,
+) iter.Seq2[Message, error] {
+	return func(yield func(Message, error) bool) {
+		var sessionID string
+
+		for attempt := 1; ; attempt++ {
+			attemptPrompt, attemptOpts := prompt, opts
+
+			// Resume the failed attempt's session rather than start over
+			if sessionID != "" {
+				attemptPrompt = policy.Prompt()
+				attemptOpts = append(slices.Clone(opts), WithResume(sessionID))
+			}
+
+			canRetry := attempt < policy.Attempts()
+
+			done, failure := runAttempt(query(ctx, attemptPrompt, attemptOpts...), policy, canRetry, &sessionID, yield)
+			if done {
+				return
+			}
+
+			timer := time.NewTimer(policy.Delay(attempt, failure))
+
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				yield(nil, ctx.Err())
+
+				return
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// runAttempt yields the messages of one attempt and records its session ID.
+// It returns done when the attempt finished or the caller stopped, and
+// otherwise the retryable failure that ended it.
+func runAttempt(
+	attempt iter.Seq2[Message, error],
+	policy *retry.Policy,
+	canRetry bool,
+	sessionID *string,
+	yield func(Message, error) bool,
+) (bool, error) {
+	var lastErr error
+
+	for msg, err := range attempt {
+		if err != nil {
+			if canRetry && policy.Retryable(err) {
+				return false, err
+			}
+
+			if !yield(nil, err) {
+				return true, nil
+			}
+
+			continue
+		}
+
+		switch m := msg.(type) {
+		case *SystemMessage:
+			if id, ok := m.Data["session_id"].(string); ok && id != "" {
+				*sessionID = id
+			}
+		case *AssistantMessage:
+			if failure := retry.ClassifyMessage(m, nil); failure != nil {
+				lastErr = failure
+			}
+		case *ResultMessage:
+			if m.SessionID != "" {
+				*sessionID = m.SessionID
+			}
+
+			if failure := retry.ClassifyMessage(m, lastErr); failure != nil && canRetry && policy.Retryable(failure) {
+				return false, failure
+			}
+		}
+
+		if !yield(msg, nil) {
+			return true, nil
+		}
+	}
+
+	return true, nil
+}
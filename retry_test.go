@@ -0,0 +1,177 @@
+package claudesdk
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wagiedev/claude-agent-sdk-go/internal/config"
+)
+
+// scriptedAttempt is what one CLI process emits: messages, or a failure.
+type scriptedAttempt struct {
+	messages []map[string]any
+	err      error
+}
+
+// scriptedTransport plays one scripted attempt per Start.
+type scriptedTransport struct {
+	mu       sync.Mutex
+	attempts []scriptedAttempt
+	starts   int
+	msgChan  chan map[string]any
+	errChan  chan error
+}
+
+func (s *scriptedTransport) Start(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt := s.attempts[min(s.starts, len(s.attempts)-1)]
+	s.starts++
+
+	s.msgChan = make(chan map[string]any, len(attempt.messages))
+	s.errChan = make(chan error, 1)
+
+	if attempt.err != nil {
+		s.errChan <- attempt.err
+
+		return nil
+	}
+
+	for _, msg := range attempt.messages {
+		s.msgChan <- msg
+	}
+
+	close(s.msgChan)
+
+	return nil
+}
+
+func (s *scriptedTransport) ReadMessages(_ context.Context) (<-chan map[string]any, <-chan error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.msgChan, s.errChan
+}
+
+func (s *scriptedTransport) SendMessage(_ context.Context, _ []byte) error { return nil }
+func (s *scriptedTransport) Close() error                                  { return nil }
+func (s *scriptedTransport) IsReady() bool                                 { return true }
+func (s *scriptedTransport) EndInput() error                               { return nil }
+
+func (s *scriptedTransport) Starts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.starts
+}
+
+var _ config.Transport = (*scriptedTransport)(nil)
+
+func initMessage(sessionID string) map[string]any {
+	return map[string]any{"type": "system", "subtype": "init", "session_id": sessionID}
+}
+
+func resultMessage(sessionID, subtype string, isError bool) map[string]any {
+	return map[string]any{
+		"type":            "result",
+		"subtype":         subtype,
+		"is_error":        isError,
+		"session_id":      sessionID,
+		"duration_ms":     1,
+		"duration_api_ms": 1,
+		"num_turns":       1,
+	}
+}
+
+func collect(t *testing.T, seq func(func(Message, error) bool)) ([]Message, []error) {
+	t.Helper()
+
+	var (
+		msgs []Message
+		errs []error
+	)
+
+	for msg, err := range seq {
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		msgs = append(msgs, msg)
+	}
+
+	return msgs, errs
+}
+
+func TestQuery_RetryResumesAfterRateLimit(t *testing.T) {
+	transport := &scriptedTransport{attempts: []scriptedAttempt{
+		{messages: []map[string]any{
+			initMessage("sess-1"),
+			{
+				"type":    "assistant",
+				"error":   "rate_limit",
+				"message": map[string]any{"model": "claude-sonnet-4-6", "content": []any{}},
+			},
+			resultMessage("sess-1", "success", true),
+		}},
+		{messages: []map[string]any{initMessage("sess-1"), resultMessage("sess-1", "success", false)}},
+	}}
+
+	msgs, errs := collect(t, Query(t.Context(), "hello",
+		WithTransport(transport),
+		WithRetry(RetryPolicy{InitialBackoff: time.Millisecond, Jitter: -1}),
+	))
+
+	require.Empty(t, errs)
+	assert.Equal(t, 2, transport.Starts())
+	require.Len(t, msgs, 4, "the failed result is replaced by the retry")
+
+	result, ok := msgs[3].(*ResultMessage)
+	require.True(t, ok)
+	assert.False(t, result.IsError)
+}
+
+func TestQuery_RetryStopsOnPermanentFailure(t *testing.T) {
+	transport := &scriptedTransport{attempts: []scriptedAttempt{
+		{messages: []map[string]any{resultMessage("sess-1", "error_max_turns", true)}},
+	}}
+
+	msgs, errs := collect(t, Query(t.Context(), "hello",
+		WithTransport(transport),
+		WithRetry(RetryPolicy{InitialBackoff: time.Millisecond}),
+	))
+
+	require.Empty(t, errs)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, 1, transport.Starts())
+
+	failure := ClassifyMessage(msgs[0], nil)
+	require.ErrorIs(t, failure, ErrMaxTurns)
+	assert.False(t, IsRetryable(failure))
+}
+
+func TestQuery_RetryGivesUpAfterMaxAttempts(t *testing.T) {
+	transport := &scriptedTransport{attempts: []scriptedAttempt{
+		{err: &ProcessError{ExitCode: 1, Stderr: "API Error: 529 Overloaded"}},
+	}}
+
+	_, errs := collect(t, Query(t.Context(), "hello",
+		WithTransport(transport),
+		WithRetry(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}),
+	))
+
+	require.Len(t, errs, 1)
+	assert.Equal(t, 2, transport.Starts())
+	require.ErrorIs(t, errs[0], ErrOverloaded)
+
+	_, isProcessErr := errors.AsType[*ProcessError](errs[0])
+	assert.True(t, isProcessErr)
+}
@@ -0,0 +1,399 @@
+// Package sessions lists the sessions the Claude CLI has saved for a project
+// and reopens, inspects, archives or deletes them.
+//
+// The CLI keeps one transcript per session under
+// ~/.claude/projects/<project>, where <project> is derived from the working
+// directory. A Catalog reads those transcripts to build a "recent
+// conversations" list, and starts a client from any of them:
+//
+//	catalog, err := sessions.ForProject(cwd)
+//	list, err := catalog.List()
+//	for _, s := range list {
+//	    fmt.Println(s.LastActivity, s.FirstPrompt)
+//	}
+//	client, err := list[0].Resume(ctx)
+package sessions
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	claudesdk "github.com/wagiedev/claude-agent-sdk-go"
+	"github.com/wagiedev/claude-agent-sdk-go/transcript"
+)
+
+// ErrNotFound indicates no session with the given ID exists in the catalog.
+var ErrNotFound = stderrors.New("session not found")
+
+// archiveDir is the catalog subdirectory archived sessions are moved to. The
+// CLI does not look there, so archived sessions cannot be resumed by it.
+const archiveDir = "archive"
+
+// unsafeProjectChars are replaced with '-' to name a project's directory.
+var unsafeProjectChars = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// ConfigDir returns the CLI's configuration directory: $CLAUDE_CONFIG_DIR, or
+// ~/.claude.
+func ConfigDir() (string, error) {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return dir, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("find home directory: %w", err)
+	}
+
+	return filepath.Join(home, ".claude"), nil
+}
+
+// ProjectDir returns the directory where the CLI keeps the sessions of the
+// project in cwd.
+func ProjectDir(cwd string) (string, error) {
+	abs, err := filepath.Abs(cwd)
+	if err != nil {
+		return "", fmt.Errorf("resolve project path: %w", err)
+	}
+
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "projects", unsafeProjectChars.ReplaceAllString(abs, "-")), nil
+}
+
+// Catalog is the set of sessions saved for one project.
+type Catalog struct {
+	dir string
+	cwd string
+}
+
+// ForProject returns the catalog of the project in cwd.
+func ForProject(cwd string) (*Catalog, error) {
+	dir, err := ProjectDir(cwd)
+	if err != nil {
+		return nil, err
+	}
+
+	abs, err := filepath.Abs(cwd)
+	if err != nil {
+		return nil, fmt.Errorf("resolve project path: %w", err)
+	}
+
+	return &Catalog{dir: dir, cwd: abs}, nil
+}
+
+// ForDir returns the catalog of the sessions stored in dir. Sessions started
+// from it run in their recorded working directory.
+func ForDir(dir string) *Catalog {
+	return &Catalog{dir: dir}
+}
+
+// Dir returns the directory the catalog reads.
+func (c *Catalog) Dir() string { return c.dir }
+
+// List returns the project's sessions, most recently active first. A project
+// without sessions has an empty list. Sessions that cannot be read are left
+// out, and their errors are joined in the returned error alongside the
+// sessions that could be read.
+func (c *Catalog) List() ([]*Session, error) {
+	paths, err := filepath.Glob(filepath.Join(c.dir, "*.jsonl"))
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+
+	subagents, err := transcript.IndexSubagents(c.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		list = make([]*Session, 0, len(paths))
+		errs []error
+	)
+
+	for _, path := range paths {
+		// Legacy subagent transcripts share the directory
+		if strings.HasPrefix(filepath.Base(path), "agent-") {
+			continue
+		}
+
+		s, err := c.load(path, subagents)
+
+		switch {
+		case stderrors.Is(err, fs.ErrNotExist):
+			// Deleted since the directory was read
+		case err != nil:
+			errs = append(errs, err)
+		case s.Messages > 0:
+			list = append(list, s)
+		}
+	}
+
+	slices.SortFunc(list, func(a, b *Session) int {
+		return b.LastActivity.Compare(a.LastActivity)
+	})
+
+	return list, stderrors.Join(errs...)
+}
+
+// Get returns the session with the given ID.
+func (c *Catalog) Get(id string) (*Session, error) {
+	path, err := c.path(id)
+	if err != nil {
+		return nil, err
+	}
+
+	subagents, err := transcript.IndexSubagents(c.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.load(path, subagents)
+}
+
+// Delete removes a session's transcript and its subagent transcripts.
+func (c *Catalog) Delete(id string) error {
+	path, err := c.path(id)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("delete session %s: %w", id, err)
+	}
+
+	if err := os.RemoveAll(filepath.Join(c.dir, id)); err != nil {
+		return fmt.Errorf("delete session %s: %w", id, err)
+	}
+
+	return nil
+}
+
+// Archive moves a session out of the catalog into its archive subdirectory,
+// so it no longer appears in List or in the CLI's resume picker.
+func (c *Catalog) Archive(id string) error {
+	path, err := c.path(id)
+	if err != nil {
+		return err
+	}
+
+	archive := filepath.Join(c.dir, archiveDir)
+	if err := os.MkdirAll(archive, 0o700); err != nil {
+		return fmt.Errorf("archive session %s: %w", id, err)
+	}
+
+	if err := os.Rename(path, filepath.Join(archive, id+".jsonl")); err != nil {
+		return fmt.Errorf("archive session %s: %w", id, err)
+	}
+
+	subagents := filepath.Join(c.dir, id)
+	if _, err := os.Stat(subagents); err == nil {
+		if err := os.Rename(subagents, filepath.Join(archive, id)); err != nil {
+			return fmt.Errorf("archive session %s: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
+// path returns the transcript path of a session, rejecting IDs that are not
+// plain file names.
+func (c *Catalog) path(id string) (string, error) {
+	if id == "" || id != filepath.Base(id) || strings.HasPrefix(id, ".") {
+		return "", fmt.Errorf("invalid session ID %q", id)
+	}
+
+	path := filepath.Join(c.dir, id+".jsonl")
+	if _, err := os.Stat(path); err != nil {
+		if stderrors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+
+		return "", fmt.Errorf("stat session %s: %w", id, err)
+	}
+
+	return path, nil
+}
+
+// Session describes a saved session.
+type Session struct {
+	// ID is the session ID to pass to WithResume.
+	ID string
+	// Path is the transcript file.
+	Path string
+	// Summary is the title the CLI generated, if any.
+	Summary string
+	// FirstPrompt is the first prompt the user typed.
+	FirstPrompt string
+	// Created and LastActivity are the times of the first and last entries.
+	Created      time.Time
+	LastActivity time.Time
+	// Model is the model of the last main-agent response.
+	Model string
+	// CostUSD is estimated from the usage recorded in the transcript,
+	// including subagents, at current list prices.
+	CostUSD float64
+	// Turns counts the prompts the user typed.
+	Turns int
+	// Messages counts the user and assistant messages in the transcript.
+	Messages  int
+	Cwd       string
+	GitBranch string
+
+	catalog *Catalog
+}
+
+// Resume starts a client that continues the session.
+func (s *Session) Resume(ctx context.Context, opts ...claudesdk.Option) (claudesdk.Client, error) {
+	return s.start(ctx, slices.Concat(opts, []claudesdk.Option{claudesdk.WithResume(s.ID)}))
+}
+
+// Fork starts a client from the session's history under a new session ID,
+// leaving the original session unchanged.
+func (s *Session) Fork(ctx context.Context, opts ...claudesdk.Option) (claudesdk.Client, error) {
+	return s.start(ctx, slices.Concat(opts, []claudesdk.Option{
+		claudesdk.WithResume(s.ID),
+		claudesdk.WithForkSession(true),
+	}))
+}
+
+// start starts a client in the session's working directory, which the CLI
+// needs to find the transcript. Options passed by the caller come after it,
+// so an explicit WithCwd wins.
+func (s *Session) start(ctx context.Context, opts []claudesdk.Option) (claudesdk.Client, error) {
+	cwd := s.Cwd
+	if s.catalog != nil && s.catalog.cwd != "" {
+		cwd = s.catalog.cwd
+	}
+
+	if cwd != "" {
+		opts = slices.Concat([]claudesdk.Option{claudesdk.WithCwd(cwd)}, opts)
+	}
+
+	client := claudesdk.NewClient()
+	if err := client.Start(ctx, opts...); err != nil {
+		return nil, fmt.Errorf("start session %s: %w", s.ID, err)
+	}
+
+	return client, nil
+}
+
+// load reads a session's metadata from its transcript, and its cost from the
+// subagent transcripts in subagents.
+func (c *Catalog) load(path string, subagents *transcript.SubagentIndex) (*Session, error) {
+	s := &Session{
+		ID:      strings.TrimSuffix(filepath.Base(path), ".jsonl"),
+		Path:    path,
+		catalog: c,
+	}
+
+	costs := claudesdk.NewCostTracker(nil)
+
+	observe := func(entry *transcript.Entry) {
+		if !entry.Timestamp.IsZero() {
+			if s.Created.IsZero() {
+				s.Created = entry.Timestamp
+			}
+
+			s.LastActivity = entry.Timestamp
+		}
+
+		costs.Observe(entry.Message)
+
+		switch msg := entry.Message.(type) {
+		case *claudesdk.UserMessage:
+			s.Messages++
+
+			if entry.IsSidechain || entry.IsMeta {
+				return
+			}
+
+			if prompt := promptText(msg); prompt != "" {
+				s.Turns++
+
+				if s.FirstPrompt == "" {
+					s.FirstPrompt = prompt
+				}
+			}
+		case *claudesdk.AssistantMessage:
+			s.Messages++
+
+			if !entry.IsSidechain && msg.Model != "" && msg.Model != "<synthetic>" {
+				s.Model = msg.Model
+			}
+		}
+	}
+
+	for entry, err := range transcript.ReadFile(path) {
+		if err != nil {
+			// Skip malformed lines, e.g. one the CLI is still writing
+			if _, isLineErr := stderrors.AsType[*transcript.LineError](err); isLineErr {
+				continue
+			}
+
+			return nil, fmt.Errorf("read session %s: %w", s.ID, err)
+		}
+
+		if entry.Summary != nil && entry.Summary.Text != "" {
+			s.Summary = entry.Summary.Text
+		}
+
+		if s.Cwd == "" {
+			s.Cwd = entry.Cwd
+		}
+
+		if entry.GitBranch != "" {
+			s.GitBranch = entry.GitBranch
+		}
+
+		observe(entry)
+	}
+
+	files, err := subagents.Files(s.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		for entry, err := range transcript.ReadFile(file) {
+			if err == nil {
+				costs.Observe(entry.Message)
+			}
+		}
+	}
+
+	s.CostUSD = costs.Total().Total()
+
+	if s.LastActivity.IsZero() {
+		if info, err := os.Stat(path); err == nil {
+			s.LastActivity = info.ModTime()
+		}
+	}
+
+	return s, nil
+}
+
+// promptText returns the text a user typed, or "" for tool results.
+func promptText(msg *claudesdk.UserMessage) string {
+	if text := msg.Content.String(); text != "" {
+		return strings.TrimSpace(text)
+	}
+
+	for _, block := range msg.Content.Blocks() {
+		if text, ok := block.(*claudesdk.TextBlock); ok {
+			return strings.TrimSpace(text.Text)
+		}
+	}
+
+	return ""
+}
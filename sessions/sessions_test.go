@@ -0,0 +1,118 @@
+package sessions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	claudesdk "github.com/wagiedev/claude-agent-sdk-go"
+)
+
+const older = `{"type":"user","uuid":"u1","sessionId":"old","timestamp":"2026-03-01T10:00:00Z","cwd":"/work/app","gitBranch":"main","message":{"role":"user","content":"first question"}}
+{"type":"assistant","uuid":"a1","parentUuid":"u1","sessionId":"old","timestamp":"2026-03-01T10:00:05Z","message":{"id":"msg_1","role":"assistant","model":"claude-haiku-4-5","content":[{"type":"text","text":"answer"}],"usage":{"input_tokens":1000000,"output_tokens":0}}}
+`
+
+const newer = `{"type":"summary","summary":"Refactor the parser","leafUuid":"a2"}
+{"type":"user","uuid":"m0","sessionId":"new","timestamp":"2026-03-02T09:00:00Z","isMeta":true,"message":{"role":"user","content":"<command-name>/clear</command-name>"}}
+{"type":"user","uuid":"u1","parentUuid":"m0","sessionId":"new","timestamp":"2026-03-02T09:00:01Z","message":{"role":"user","content":[{"type":"text","text":"refactor the parser"}]}}
+{"type":"assistant","uuid":"a1","parentUuid":"u1","sessionId":"new","timestamp":"2026-03-02T09:00:02Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-6","content":[{"type":"tool_use","id":"toolu_1","name":"Read","input":{}}],"usage":{"input_tokens":1000000,"output_tokens":0}}}
+{"type":"user","uuid":"r1","parentUuid":"a1","sessionId":"new","timestamp":"2026-03-02T09:00:03Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_1","content":"ok"}]}}
+{"type":"user","uuid":"u2","parentUuid":"r1","sessionId":"new","timestamp":"2026-03-02T09:05:00Z","message":{"role":"user","content":"thanks"}}
+`
+
+func writeSession(t *testing.T, dir, id, content string) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, id+".jsonl"), []byte(content), 0o600))
+}
+
+func TestProjectDir(t *testing.T) {
+	t.Setenv("CLAUDE_CONFIG_DIR", "/cfg")
+
+	dir, err := ProjectDir("/home/me/my_app.v2")
+	require.NoError(t, err)
+	assert.Equal(t, "/cfg/projects/-home-me-my-app-v2", dir)
+}
+
+func TestCatalog(t *testing.T) {
+	t.Setenv("CLAUDE_CONFIG_DIR", t.TempDir())
+
+	catalog, err := ForProject("/work/app")
+	require.NoError(t, err)
+
+	empty, err := catalog.List()
+	require.NoError(t, err)
+	assert.Empty(t, empty, "a project without sessions has none")
+
+	require.NoError(t, os.MkdirAll(catalog.Dir(), 0o700))
+	writeSession(t, catalog.Dir(), "old", older)
+	writeSession(t, catalog.Dir(), "new", newer)
+	writeSession(t, catalog.Dir(), "agent-abc", `{"type":"user","sessionId":"new","message":{"role":"user","content":"sub"}}`)
+
+	list, err := catalog.List()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+
+	latest := list[0]
+	assert.Equal(t, "new", latest.ID)
+	assert.Equal(t, "Refactor the parser", latest.Summary)
+	assert.Equal(t, "refactor the parser", latest.FirstPrompt, "meta entries and tool results are not prompts")
+	assert.Equal(t, 2, latest.Turns)
+	assert.Equal(t, 5, latest.Messages)
+	assert.Equal(t, "claude-sonnet-4-6", latest.Model)
+	assert.InDelta(t, 3.0, latest.CostUSD, 1e-9)
+	assert.Equal(t, 5, latest.LastActivity.Minute())
+
+	previous, err := catalog.Get("old")
+	require.NoError(t, err)
+	assert.Equal(t, "first question", previous.FirstPrompt)
+	assert.Equal(t, "main", previous.GitBranch)
+	assert.InDelta(t, 1.0, previous.CostUSD, 1e-9)
+
+	_, err = catalog.Get("missing")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = catalog.Get("../old")
+	require.Error(t, err)
+
+	require.NoError(t, catalog.Archive("old"))
+	assert.FileExists(t, filepath.Join(catalog.Dir(), "archive", "old.jsonl"))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(catalog.Dir(), "new", "subagents"), 0o700))
+	require.NoError(t, catalog.Delete("new"))
+	assert.NoDirExists(t, filepath.Join(catalog.Dir(), "new"))
+
+	list, err = catalog.List()
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestCatalogList_UnreadableSession(t *testing.T) {
+	dir := t.TempDir()
+	writeSession(t, dir, "old", older)
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "broken.jsonl"), 0o700))
+
+	list, err := ForDir(dir).List()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "read session broken")
+	require.Len(t, list, 1, "readable sessions are still listed")
+	assert.Equal(t, "old", list[0].ID)
+}
+
+func TestSessionResume_CLINotFound(t *testing.T) {
+	dir := t.TempDir()
+	writeSession(t, dir, "old", older)
+
+	s, err := ForDir(dir).Get("old")
+	require.NoError(t, err)
+
+	_, err = s.Resume(t.Context(), claudesdk.WithCliPath(filepath.Join(dir, "no-such-claude")))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "start session old")
+
+	_, err = s.Fork(t.Context(), claudesdk.WithCliPath(filepath.Join(dir, "no-such-claude")))
+	require.Error(t, err)
+}
@@ -0,0 +1,52 @@
+package claudesdk
+
+import "github.com/wagiedev/claude-agent-sdk-go/internal/subagent"
+
+// SubagentTree rebuilds the tree of subagents from the flat message stream,
+// tracking each one's messages, usage and status:
+//
+//	tree := claudesdk.NewSubagentTree()
+//	tree.OnEvent(func(e claudesdk.SubagentEvent) {
+//	    fmt.Println(e.Type, e.Agent.Description)
+//	})
+//	for msg, err := range claudesdk.Query(ctx, prompt) {
+//	    // handle err
+//	    tree.Observe(msg)
+//	}
+type SubagentTree = subagent.Tree
+
+// Subagent is a snapshot of one subagent in a SubagentTree.
+type Subagent = subagent.Agent
+
+// SubagentStatus is the state of a subagent.
+type SubagentStatus = subagent.Status
+
+const (
+	// SubagentStatusRunning means the subagent's Task call has no result yet.
+	SubagentStatusRunning = subagent.StatusRunning
+	// SubagentStatusCompleted means the Task call returned a result.
+	SubagentStatusCompleted = subagent.StatusCompleted
+	// SubagentStatusFailed means the Task call returned an error, or the run
+	// ended before it returned.
+	SubagentStatusFailed = subagent.StatusFailed
+)
+
+// SubagentEvent reports a change in a SubagentTree.
+type SubagentEvent = subagent.Event
+
+// SubagentEventType identifies a change in a SubagentTree.
+type SubagentEventType = subagent.EventType
+
+const (
+	// SubagentEventStarted is sent when a subagent's Task call is seen.
+	SubagentEventStarted = subagent.EventStarted
+	// SubagentEventMessage is sent for each message a subagent produces.
+	SubagentEventMessage = subagent.EventMessage
+	// SubagentEventCompleted is sent when a subagent's Task call returns.
+	SubagentEventCompleted = subagent.EventCompleted
+	// SubagentEventFailed is sent when a subagent fails.
+	SubagentEventFailed = subagent.EventFailed
+)
+
+// NewSubagentTree creates an empty SubagentTree.
+var NewSubagentTree = subagent.NewTree
@@ -0,0 +1,421 @@
+// Package transcript reads the session transcripts the Claude CLI persists as
+// JSONL files, such as the file at a hook input's TranscriptPath.
+//
+// Entries are parsed into the SDK's Message types, keeping the transcript
+// metadata the CLI adds: entry and parent UUIDs, timestamps, sidechain
+// (subagent) markers, summaries and compaction boundaries. Files are read one
+// line at a time, so large transcripts are never held in memory:
+//
+//	for entry, err := range transcript.ReadFile(path) {
+//	    if err != nil {
+//	        log.Println(err) // a malformed line; reading continues
+//	        continue
+//	    }
+//	    if entry.Message != nil {
+//	        render(entry.Message)
+//	    }
+//	}
+package transcript
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	claudesdk "github.com/wagiedev/claude-agent-sdk-go"
+	"github.com/wagiedev/claude-agent-sdk-go/internal/message"
+)
+
+// Entry types written by the CLI. Other types, such as file history
+// snapshots, are kept with only Raw set.
+const (
+	EntryUser      = "user"
+	EntryAssistant = "assistant"
+	EntrySystem    = "system"
+	EntrySummary   = "summary"
+)
+
+// subtypeCompactBoundary marks a system entry written when the conversation
+// was compacted.
+const subtypeCompactBoundary = "compact_boundary"
+
+// Entry is one line of a transcript.
+type Entry struct {
+	// Line is the 1-based line number in the file.
+	Line int
+	// Type is the entry type, e.g. EntryUser.
+	Type string
+	// UUID identifies the entry; ParentUUID is the entry it follows, or ""
+	// for the first entry of a conversation or after compaction.
+	UUID       string
+	ParentUUID string
+	SessionID  string
+	Timestamp  time.Time
+	// IsSidechain is true for entries written by a subagent.
+	IsSidechain bool
+	// AgentID identifies the subagent that wrote a sidechain entry.
+	AgentID string
+	// IsMeta is true for entries the CLI injected rather than the user typed,
+	// such as command output.
+	IsMeta    bool
+	Cwd       string
+	GitBranch string
+	// Version is the CLI version that wrote the entry.
+	Version string
+	// Message is the parsed message of user, assistant and system entries.
+	Message claudesdk.Message
+	// Summary is set for summary entries.
+	Summary *Summary
+	// Compact is set for compaction boundaries.
+	Compact *CompactBoundary
+	// Raw is the decoded line.
+	Raw map[string]any
+}
+
+// Summary is a title the CLI generated for a conversation.
+type Summary struct {
+	Text string
+	// LeafUUID is the last entry the summary covers.
+	LeafUUID string
+}
+
+// CompactBoundary marks where the CLI compacted the conversation. Entries
+// after it continue from a summary rather than from the earlier messages.
+type CompactBoundary struct {
+	// Trigger is "auto" or "manual".
+	Trigger string
+	// PreTokens is the context size before compaction.
+	PreTokens int
+	// LogicalParentUUID is the last entry before compaction.
+	LogicalParentUUID string
+}
+
+// LineError reports a transcript line that could not be parsed.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("transcript line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// Reader reads transcript entries one line at a time.
+type Reader struct {
+	r    *bufio.Reader
+	line int
+}
+
+// NewReader creates a Reader reading a transcript from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReaderSize(r, 64*1024)}
+}
+
+// Next returns the next entry, or io.EOF after the last one. A malformed line
+// returns a *LineError; the following call continues with the next line.
+func (r *Reader) Next() (*Entry, error) {
+	for {
+		data, err := r.r.ReadBytes('\n')
+		if len(data) == 0 && err != nil {
+			if stderrors.Is(err, io.EOF) {
+				return nil, io.EOF
+			}
+
+			return nil, fmt.Errorf("read transcript: %w", err)
+		}
+
+		r.line++
+
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+
+		entry, parseErr := parseEntry(data)
+		if parseErr != nil {
+			return nil, &LineError{Line: r.line, Err: parseErr}
+		}
+
+		entry.Line = r.line
+
+		return entry, nil
+	}
+}
+
+// Entries returns an iterator over the entries read from r. Malformed lines
+// are yielded as errors and reading continues.
+func Entries(r io.Reader) iter.Seq2[*Entry, error] {
+	return func(yield func(*Entry, error) bool) {
+		reader := NewReader(r)
+
+		for {
+			entry, err := reader.Next()
+			if stderrors.Is(err, io.EOF) {
+				return
+			}
+
+			if !yield(entry, err) {
+				return
+			}
+
+			// Read failures end iteration; malformed lines do not
+			if _, isLineErr := stderrors.AsType[*LineError](err); err != nil && !isLineErr {
+				return
+			}
+		}
+	}
+}
+
+// ReadFile returns an iterator over the entries of the transcript at path.
+// The file is opened when iteration starts and closed when it ends.
+func ReadFile(path string) iter.Seq2[*Entry, error] {
+	return func(yield func(*Entry, error) bool) {
+		f, err := os.Open(path)
+		if err != nil {
+			yield(nil, fmt.Errorf("open transcript: %w", err))
+
+			return
+		}
+
+		defer f.Close()
+
+		for entry, err := range Entries(f) {
+			if !yield(entry, err) {
+				return
+			}
+		}
+	}
+}
+
+// Load reads every entry of the transcript at path. Malformed lines are
+// skipped and reported together in the returned error, alongside the entries
+// that could be read.
+func Load(path string) ([]*Entry, error) {
+	var (
+		entries []*Entry
+		errs    []error
+	)
+
+	for entry, err := range ReadFile(path) {
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, stderrors.Join(errs...)
+}
+
+// Messages returns the SDK messages of entries, skipping entries that carry
+// none such as summaries.
+func Messages(entries iter.Seq2[*Entry, error]) iter.Seq2[claudesdk.Message, error] {
+	return func(yield func(claudesdk.Message, error) bool) {
+		for entry, err := range entries {
+			if err != nil {
+				if !yield(nil, err) {
+					return
+				}
+
+				continue
+			}
+
+			if entry.Message != nil && !yield(entry.Message, nil) {
+				return
+			}
+		}
+	}
+}
+
+// MainThread returns the conversation leading to the last entry outside any
+// sidechain, oldest first, following parent links across compaction
+// boundaries. Branches abandoned by rewinding, and subagent entries, are left
+// out. This is the history to show when a conversation is reopened.
+func MainThread(entries []*Entry) []*Entry {
+	byUUID := make(map[string]*Entry, len(entries))
+
+	var leaf *Entry
+
+	for _, e := range entries {
+		if e.UUID == "" {
+			continue
+		}
+
+		byUUID[e.UUID] = e
+
+		if !e.IsSidechain {
+			leaf = e
+		}
+	}
+
+	var thread []*Entry
+
+	seen := make(map[string]struct{}, len(entries))
+
+	for e := leaf; e != nil; {
+		if _, loop := seen[e.UUID]; loop {
+			break
+		}
+
+		seen[e.UUID] = struct{}{}
+		thread = append(thread, e)
+
+		parent := e.ParentUUID
+		if parent == "" && e.Compact != nil {
+			parent = e.Compact.LogicalParentUUID
+		}
+
+		e = byUUID[parent]
+	}
+
+	slices.Reverse(thread)
+
+	return thread
+}
+
+// SubagentFiles returns the transcripts written by subagents of the session
+// whose transcript is at path. The CLI keeps them in a subagents directory
+// named after the session, or, in older versions, next to the session as
+// agent-*.jsonl files.
+func SubagentFiles(path string) ([]string, error) {
+	index, err := IndexSubagents(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+
+	return index.Files(strings.TrimSuffix(filepath.Base(path), ".jsonl"))
+}
+
+// SubagentIndex finds the subagent transcripts of the sessions in one
+// directory. It reads the legacy agent-*.jsonl files once, so looking up many
+// sessions does not rescan them.
+type SubagentIndex struct {
+	dir    string
+	legacy map[string][]string
+}
+
+// IndexSubagents indexes the subagent transcripts of the sessions in dir.
+func IndexSubagents(dir string) (*SubagentIndex, error) {
+	legacy, err := filepath.Glob(filepath.Join(dir, "agent-*.jsonl"))
+	if err != nil {
+		return nil, fmt.Errorf("list subagent transcripts: %w", err)
+	}
+
+	index := &SubagentIndex{dir: dir, legacy: make(map[string][]string)}
+
+	for _, file := range legacy {
+		for entry, err := range ReadFile(file) {
+			if err == nil {
+				index.legacy[entry.SessionID] = append(index.legacy[entry.SessionID], file)
+			}
+
+			break
+		}
+	}
+
+	return index, nil
+}
+
+// Files returns the subagent transcripts of the session with the given ID.
+func (x *SubagentIndex) Files(sessionID string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(x.dir, sessionID, "subagents", "agent-*.jsonl"))
+	if err != nil {
+		return nil, fmt.Errorf("list subagent transcripts: %w", err)
+	}
+
+	return append(files, x.legacy[sessionID]...), nil
+}
+
+// rawEntry holds the transcript metadata of a line.
+type rawEntry struct {
+	Type              string `json:"type"`
+	Subtype           string `json:"subtype"`
+	UUID              string `json:"uuid"`
+	ParentUUID        string `json:"parentUuid"`
+	LogicalParentUUID string `json:"logicalParentUuid"`
+	SessionID         string `json:"sessionId"`
+	Timestamp         string `json:"timestamp"`
+	IsSidechain       bool   `json:"isSidechain"`
+	AgentID           string `json:"agentId"`
+	IsMeta            bool   `json:"isMeta"`
+	Cwd               string `json:"cwd"`
+	GitBranch         string `json:"gitBranch"`
+	Version           string `json:"version"`
+	Summary           string `json:"summary"`
+	LeafUUID          string `json:"leafUuid"`
+	CompactMetadata   *struct {
+		Trigger   string `json:"trigger"`
+		PreTokens int    `json:"preTokens"`
+	} `json:"compactMetadata"`
+}
+
+func parseEntry(data []byte) (*Entry, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, &claudesdk.CLIJSONDecodeError{RawData: string(data), Err: err}
+	}
+
+	var meta rawEntry
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("decode entry: %w", err)
+	}
+
+	entry := &Entry{
+		Type:        meta.Type,
+		UUID:        meta.UUID,
+		ParentUUID:  meta.ParentUUID,
+		SessionID:   meta.SessionID,
+		IsSidechain: meta.IsSidechain,
+		AgentID:     meta.AgentID,
+		IsMeta:      meta.IsMeta,
+		Cwd:         meta.Cwd,
+		GitBranch:   meta.GitBranch,
+		Version:     meta.Version,
+		Raw:         raw,
+	}
+
+	if meta.Timestamp != "" {
+		if ts, err := time.Parse(time.RFC3339Nano, meta.Timestamp); err == nil {
+			entry.Timestamp = ts
+		}
+	}
+
+	switch {
+	case meta.Type == EntryUser, meta.Type == EntryAssistant,
+		meta.Type == EntrySystem && meta.Subtype != "":
+		msg, err := message.Parse(claudesdk.NopLogger(), raw)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s entry: %w", meta.Type, err)
+		}
+
+		entry.Message = msg
+	case meta.Type == EntrySummary:
+		entry.Summary = &Summary{Text: meta.Summary, LeafUUID: meta.LeafUUID}
+	}
+
+	if meta.Type == EntrySystem && meta.Subtype == subtypeCompactBoundary {
+		entry.Compact = &CompactBoundary{LogicalParentUUID: meta.LogicalParentUUID}
+
+		if meta.CompactMetadata != nil {
+			entry.Compact.Trigger = meta.CompactMetadata.Trigger
+			entry.Compact.PreTokens = meta.CompactMetadata.PreTokens
+		}
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,121 @@
+package transcript
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	claudesdk "github.com/wagiedev/claude-agent-sdk-go"
+)
+
+const session = `{"type":"summary","summary":"Fix the flaky test","leafUuid":"a2"}
+{"type":"user","uuid":"u1","parentUuid":null,"sessionId":"s1","timestamp":"2026-03-01T10:00:00.000Z","isSidechain":false,"cwd":"/work","version":"2.1.70","message":{"role":"user","content":"fix the test"}}
+{"type":"assistant","uuid":"a1","parentUuid":"u1","sessionId":"s1","timestamp":"2026-03-01T10:00:05.000Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-6","content":[{"type":"tool_use","id":"toolu_1","name":"Task","input":{"prompt":"look"}}],"usage":{"input_tokens":10,"output_tokens":5}}}
+{"type":"user","uuid":"side1","parentUuid":null,"sessionId":"s1","isSidechain":true,"agentId":"ag1","message":{"role":"user","content":"look"}}
+{"type":"assistant","uuid":"abandoned","parentUuid":"a1","sessionId":"s1","message":{"id":"msg_2","role":"assistant","model":"claude-sonnet-4-6","content":[{"type":"text","text":"rewound"}]}}
+not json
+{"type":"system","subtype":"compact_boundary","uuid":"c1","parentUuid":null,"logicalParentUuid":"a1","sessionId":"s1","content":"Conversation compacted","compactMetadata":{"trigger":"auto","preTokens":150000}}
+{"type":"file-history-snapshot","messageId":"m1","snapshot":{}}
+
+{"type":"assistant","uuid":"a2","parentUuid":"c1","sessionId":"s1","message":{"id":"msg_3","role":"assistant","model":"claude-sonnet-4-6","content":[{"type":"text","text":"done"}]}}`
+
+func TestReader(t *testing.T) {
+	var (
+		entries []*Entry
+		errs    []error
+	)
+
+	for entry, err := range Entries(strings.NewReader(session)) {
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	require.Len(t, errs, 1)
+
+	lineErr, ok := errors.AsType[*LineError](errs[0])
+	require.True(t, ok)
+	assert.Equal(t, 6, lineErr.Line)
+
+	require.Len(t, entries, 8)
+
+	require.NotNil(t, entries[0].Summary)
+	assert.Equal(t, "Fix the flaky test", entries[0].Summary.Text)
+	assert.Nil(t, entries[0].Message)
+
+	user, ok := entries[1].Message.(*claudesdk.UserMessage)
+	require.True(t, ok)
+	assert.Equal(t, "fix the test", user.Content.String())
+	assert.Equal(t, "2.1.70", entries[1].Version)
+	assert.Equal(t, 2026, entries[1].Timestamp.Year())
+
+	assistant, ok := entries[2].Message.(*claudesdk.AssistantMessage)
+	require.True(t, ok)
+	assert.Equal(t, "msg_1", assistant.ID)
+	assert.Equal(t, 10, assistant.Usage.InputTokens)
+
+	assert.True(t, entries[3].IsSidechain)
+	assert.Equal(t, "ag1", entries[3].AgentID)
+
+	compact := entries[5].Compact
+	require.NotNil(t, compact)
+	assert.Equal(t, "auto", compact.Trigger)
+	assert.Equal(t, 150000, compact.PreTokens)
+
+	assert.Equal(t, "file-history-snapshot", entries[6].Type)
+	assert.Nil(t, entries[6].Message)
+	assert.Equal(t, 10, entries[7].Line)
+
+	var uuids []string
+	for _, e := range MainThread(entries) {
+		uuids = append(uuids, e.UUID)
+	}
+
+	assert.Equal(t, []string{"u1", "a1", "c1", "a2"}, uuids)
+}
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "s1.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(session), 0o600))
+
+	entries, err := Load(path)
+	require.Error(t, err, "malformed lines are reported")
+	require.Len(t, entries, 8)
+
+	var count int
+
+	for _, err := range Messages(ReadFile(path)) {
+		if err == nil {
+			count++
+		}
+	}
+
+	assert.Equal(t, 6, count)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "s1", "subagents"), 0o700))
+
+	nested := filepath.Join(dir, "s1", "subagents", "agent-ag1.jsonl")
+	legacy := filepath.Join(dir, "agent-ag2.jsonl")
+	other := filepath.Join(dir, "agent-ag3.jsonl")
+
+	require.NoError(t, os.WriteFile(nested, []byte(`{"type":"user","sessionId":"s1"}`), 0o600))
+	require.NoError(t, os.WriteFile(legacy, []byte(`{"type":"summary","sessionId":"s1"}`), 0o600))
+	require.NoError(t, os.WriteFile(other, []byte(`{"type":"summary","sessionId":"s2"}`), 0o600))
+
+	files, err := SubagentFiles(path)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{nested, legacy}, files)
+
+	_, err = Load(filepath.Join(dir, "missing.jsonl"))
+	require.Error(t, err)
+}
@@ -9,3 +9,12 @@ import "github.com/wagiedev/claude-agent-sdk-go/internal/config"
 // The default implementation is CLITransport which spawns a subprocess.
 // Custom transports can be injected via ClaudeAgentOptions.Transport.
 type Transport = config.Transport
+
+// GracefulCloser is implemented by transports that can wait for the CLI to
+// exit on its own. Client.CloseContext uses it when available.
+type GracefulCloser = config.GracefulCloser
+
+// ProcessOptions controls how the CLI subprocess is run and stopped. On
+// Linux the CLI runs in its own process group and is killed if the SDK's
+// process dies; resource limits are Linux only. See WithProcessOptions.
+type ProcessOptions = config.ProcessOptions
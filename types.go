@@ -92,6 +92,43 @@ type ToolsConfig = config.ToolsConfig
 // ToolsList is a list of tool names to make available.
 type ToolsList = config.ToolsList
 
+// EnvPolicy controls which host environment variables reach the CLI and
+// the commands it runs.
+type EnvPolicy = config.EnvPolicy
+
+// EnvMode selects which host environment variables the CLI inherits.
+type EnvMode = config.EnvMode
+
+const (
+	// EnvInherit passes the whole host environment.
+	EnvInherit = config.EnvInherit
+	// EnvAllowlist passes the variables the CLI needs and EnvPolicy.Allow.
+	EnvAllowlist = config.EnvAllowlist
+	// EnvClean passes no host variables.
+	EnvClean = config.EnvClean
+)
+
+// DefaultSecretPatterns are the variable names EnvPolicy.RedactSecrets
+// drops unless SecretPatterns is set.
+var DefaultSecretPatterns = config.DefaultSecretPatterns
+
+// Provider configures the platform the CLI sends requests to: the Anthropic
+// API, Amazon Bedrock, Google Vertex AI or a custom gateway.
+type Provider = config.Provider
+
+// AnthropicProvider sends requests to the Anthropic API.
+type AnthropicProvider = config.AnthropicProvider
+
+// BedrockProvider sends requests to Amazon Bedrock.
+type BedrockProvider = config.BedrockProvider
+
+// VertexProvider sends requests to Google Vertex AI.
+type VertexProvider = config.VertexProvider
+
+// GatewayProvider sends requests to a custom endpoint that speaks the
+// Anthropic API.
+type GatewayProvider = config.GatewayProvider
+
 // ===== Messages =====
 
 // Message represents any message in the conversation.
@@ -142,6 +179,9 @@ type StreamEvent = message.StreamEvent
 // Usage contains token usage information.
 type Usage = message.Usage
 
+// ModelUsage contains the usage and cost of one model in a result message.
+type ModelUsage = message.ModelUsage
+
 // ===== Content Blocks =====
 
 // ContentBlock represents a block of content within a message.
@@ -185,6 +225,10 @@ const (
 	HookEventSubagentStart = hook.EventSubagentStart
 	// HookEventPermissionRequest is triggered when a permission is requested.
 	HookEventPermissionRequest = hook.EventPermissionRequest
+	// HookEventSessionStart is triggered when a session starts, resumes or is cleared.
+	HookEventSessionStart = hook.EventSessionStart
+	// HookEventSessionEnd is triggered when a session ends.
+	HookEventSessionEnd = hook.EventSessionEnd
 )
 
 // HookInput is the interface for all hook input types.
@@ -223,6 +267,16 @@ type SubagentStartHookInput = hook.SubagentStartInput
 // PermissionRequestHookInput is the input for PermissionRequest hooks.
 type PermissionRequestHookInput = hook.PermissionRequestInput
 
+// SessionStartHookInput is the input for SessionStart hooks.
+type SessionStartHookInput = hook.SessionStartInput
+
+// SessionEndHookInput is the input for SessionEnd hooks.
+type SessionEndHookInput = hook.SessionEndInput
+
+// UnknownHookInput is the input for hook events this SDK version does not know.
+// Raw holds the complete input as sent by the CLI.
+type UnknownHookInput = hook.UnknownInput
+
 // HookJSONOutput is the interface for hook output types.
 type HookJSONOutput = hook.JSONOutput
 
@@ -256,6 +310,13 @@ type SubagentStartHookSpecificOutput = hook.SubagentStartSpecificOutput
 // PermissionRequestHookSpecificOutput is the hook-specific output for PermissionRequest.
 type PermissionRequestHookSpecificOutput = hook.PermissionRequestSpecificOutput
 
+// SessionStartHookSpecificOutput is the hook-specific output for SessionStart.
+type SessionStartHookSpecificOutput = hook.SessionStartSpecificOutput
+
+// RawHookSpecificOutput is a hook-specific output for events or fields without
+// a dedicated type. Fields are sent alongside hookEventName as-is.
+type RawHookSpecificOutput = hook.RawSpecificOutput
+
 // HookContext provides context for hook execution.
 type HookContext = hook.Context
 
@@ -346,6 +407,63 @@ type PermissionResultDeny = permission.ResultDeny
 // ToolPermissionCallback is called before each tool use for permission checking.
 type ToolPermissionCallback = permission.Callback
 
+// PermissionScope controls how long a cached permission decision is remembered.
+type PermissionScope = permission.Scope
+
+const (
+	// PermissionScopeOnce applies a decision to the current request only.
+	PermissionScopeOnce = permission.ScopeOnce
+	// PermissionScopeTurn remembers a decision until the current turn ends.
+	PermissionScopeTurn = permission.ScopeTurn
+	// PermissionScopeSession remembers a decision until the session ends.
+	PermissionScopeSession = permission.ScopeSession
+	// PermissionScopeForever remembers a decision across sessions.
+	PermissionScopeForever = permission.ScopeForever
+)
+
+// PermissionCache remembers tool permission decisions by tool and normalized input.
+type PermissionCache = permission.Cache
+
+// PermissionCacheSession is a single session's view of a PermissionCache.
+type PermissionCacheSession = permission.CacheSession
+
+// PermissionCacheOptions configures a PermissionCache.
+type PermissionCacheOptions = permission.CacheOptions
+
+// PermissionCacheEntry is a remembered permission decision.
+type PermissionCacheEntry = permission.CacheEntry
+
+// PermissionCacheStore persists forever-scoped permission decisions.
+type PermissionCacheStore = permission.Store
+
+// PermissionFileStore persists permission decisions as a JSON file.
+type PermissionFileStore = permission.FileStore
+
+// PermissionKeyFunc normalizes a tool input into a permission cache key.
+type PermissionKeyFunc = permission.KeyFunc
+
+// PermissionExactKeySuffix marks a permission cache key that matches only an
+// identical key.
+const PermissionExactKeySuffix = permission.ExactKeySuffix
+
+// PermissionRemembered wraps a permission result with an explicit cache scope.
+type PermissionRemembered = permission.Remembered
+
+// NewPermissionCache creates a PermissionCache, loading persisted entries from the store if set.
+var NewPermissionCache = permission.NewCache
+
+// NewPermissionFileStore creates a PermissionFileStore backed by the file at path.
+var NewPermissionFileStore = permission.NewFileStore
+
+// DefaultPermissionKey is the default PermissionKeyFunc.
+// It keys file tools by directory, Bash by command prefix, and WebFetch by host.
+var DefaultPermissionKey = permission.DefaultKey
+
+// RememberPermission wraps a permission result so a PermissionCache stores it
+// with the given scope, e.g. RememberPermission(allow, PermissionScopeForever)
+// for "always allow".
+var RememberPermission = permission.Remember
+
 // ===== MCP Server Configuration =====
 
 // MCPServerType represents the type of MCP server.
@@ -399,6 +517,19 @@ type SandboxIgnoreViolations = sandbox.IgnoreViolations
 // SandboxSettings configures CLI sandbox behavior.
 type SandboxSettings = sandbox.Settings
 
+// Settings are CLI settings given in code, merged with the settings file
+// and SandboxSettings. See WithTypedSettings.
+type Settings = config.Settings
+
+// PermissionSettings are allow, deny and ask permission rules.
+type PermissionSettings = config.PermissionSettings
+
+// CommandHookMatcher runs shell command hooks for the tools it matches.
+type CommandHookMatcher = config.CommandHookMatcher
+
+// CommandHook is a shell command run by the CLI for a hook event.
+type CommandHook = config.CommandHook
+
 // ===== Streaming Input =====
 
 // MessageStream is an iterator that yields streaming messages.
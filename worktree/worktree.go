@@ -0,0 +1,418 @@
+// Package worktree runs agent tasks in isolated git worktrees, so concurrent
+// agents working on one repository never clobber each other's files.
+//
+// Each task gets a new branch checked out in a temporary worktree, which is
+// used as the query's working directory. When the query finishes, the
+// worktree's changes are collected as a diff and optionally committed to the
+// branch, then the worktree is cleaned up according to the Retention policy:
+//
+//	runner, err := worktree.New(".")
+//	runner.CommitMessage = "Apply agent fix"
+//	results, err := runner.RunAll(ctx,
+//	    worktree.Task{Name: "fix-lint", Prompt: "Fix the lint errors"},
+//	    worktree.Task{Name: "fix-tests", Prompt: "Fix the failing tests"},
+//	)
+//	for _, res := range results {
+//	    fmt.Println(res.Branch, res.Files)
+//	}
+package worktree
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	claudesdk "github.com/wagiedev/claude-agent-sdk-go"
+)
+
+// DefaultBranchPrefix is prepended to task names to name their branches.
+const DefaultBranchPrefix = "claude/"
+
+// unsafeBranchChars are replaced with '-' in task names used as branch names.
+var unsafeBranchChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// Retention controls what is kept after a task finishes.
+type Retention string
+
+const (
+	// RetainBranch removes the worktree and keeps the branch if it has new
+	// commits. This is the default. No retention policy removes a worktree
+	// whose changes could not be collected.
+	RetainBranch Retention = ""
+	// RetainNone removes the worktree and the branch. The Result's Diff is the
+	// only record of the changes.
+	RetainNone Retention = "none"
+	// RetainOnError behaves like RetainBranch, but keeps the worktree of a
+	// task that failed so it can be inspected.
+	RetainOnError Retention = "on_error"
+	// RetainAll keeps the worktree and the branch. Call Result.Remove to clean
+	// them up.
+	RetainAll Retention = "all"
+)
+
+// Task is one agent run.
+type Task struct {
+	// Name names the task's branch and worktree. A random name is used if
+	// empty.
+	Name string
+	// Prompt is sent to the agent.
+	Prompt string
+	// Options are applied after the Runner's options.
+	Options []claudesdk.Option
+	// CommitMessage overrides Runner.CommitMessage.
+	CommitMessage string
+}
+
+// Result is the outcome of a task.
+type Result struct {
+	// Name is the task name.
+	Name string
+	// Branch is the task's branch.
+	Branch string
+	// Dir is the worktree directory. It no longer exists unless Kept is set.
+	Dir string
+	// Base is the commit the branch started from.
+	Base string
+	// Commit is the branch head if the task added commits, either the agent's
+	// own or the one made with the commit message; empty otherwise.
+	Commit string
+	// Diff is the binary-safe diff of everything the task changed since Base.
+	Diff string
+	// Files are the paths changed since Base, relative to the repository.
+	Files []string
+	// Messages are the messages of the query.
+	Messages []claudesdk.Message
+	// Result is the query's result message, if it finished.
+	Result *claudesdk.ResultMessage
+	// Kept reports whether the worktree was kept.
+	Kept bool
+	// BranchKept reports whether the branch was kept.
+	BranchKept bool
+
+	runner *Runner
+}
+
+// Runner runs tasks in worktrees of one repository. Configure its fields
+// before the first Run.
+type Runner struct {
+	// Base is the commit-ish new branches start from. Defaults to HEAD.
+	Base string
+	// Dir is the directory worktrees are created in. Defaults to the system
+	// temporary directory.
+	Dir string
+	// BranchPrefix is prepended to task names. Defaults to
+	// DefaultBranchPrefix.
+	BranchPrefix string
+	// CommitMessage, if set, commits the task's changes to its branch.
+	// Otherwise the changes are only reported as a diff.
+	CommitMessage string
+	// Retention controls what is kept after each task.
+	Retention Retention
+	// Parallel limits how many tasks RunAll runs at once. Zero means no limit.
+	Parallel int
+	// Options are applied to every query. The working directory is always
+	// the task's worktree.
+	Options []claudesdk.Option
+
+	repo string
+	// mu serializes commands that change the repository's shared worktree
+	// and branch state.
+	mu sync.Mutex
+}
+
+// New returns a runner for the git repository containing dir.
+func New(dir string) (*Runner, error) {
+	repo, err := git(context.Background(), dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Runner{repo: repo}, nil
+}
+
+// Repo returns the top-level directory of the repository.
+func (r *Runner) Repo() string { return r.repo }
+
+// Run runs task in a new worktree. The Result is returned whenever the
+// worktree was created, even if the query failed or ctx was cancelled, so
+// its changes are not lost; the error reports the query or git failure.
+func (r *Runner) Run(ctx context.Context, task Task) (*Result, error) {
+	res, err := r.create(ctx, task.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	runErr := res.query(ctx, r, task)
+
+	collectErr := res.collect(ctx, cmp.Or(task.CommitMessage, r.CommitMessage))
+
+	cleanupErr := r.cleanup(ctx, res, runErr != nil, collectErr != nil)
+
+	return res, stderrors.Join(runErr, collectErr, cleanupErr)
+}
+
+// RunAll runs tasks concurrently, up to Parallel at a time. Results are in
+// task order; a task whose worktree could not be created has a nil Result.
+// The error joins the errors of all tasks.
+func (r *Runner) RunAll(ctx context.Context, tasks ...Task) ([]*Result, error) {
+	results := make([]*Result, len(tasks))
+	errs := make([]error, len(tasks))
+
+	var g errgroup.Group
+	if r.Parallel > 0 {
+		g.SetLimit(r.Parallel)
+	}
+
+	for i, task := range tasks {
+		g.Go(func() error {
+			results[i], errs[i] = r.Run(ctx, task)
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("task %s: %w", cmp.Or(task.Name, fmt.Sprint(i)), errs[i])
+			}
+
+			return nil
+		})
+	}
+
+	_ = g.Wait()
+
+	return results, stderrors.Join(errs...)
+}
+
+// create adds the task's branch and worktree.
+func (r *Runner) create(ctx context.Context, name string) (*Result, error) {
+	name = strings.Trim(unsafeBranchChars.ReplaceAllString(name, "-"), "-.")
+	if name == "" {
+		name = randomName()
+	}
+
+	base, err := git(ctx, r.repo, "rev-parse", "--verify", cmp.Or(r.Base, "HEAD")+"^{commit}")
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp(r.Dir, "claude-"+name+"-")
+	if err != nil {
+		return nil, fmt.Errorf("create worktree directory: %w", err)
+	}
+
+	// git resolves relative paths against the repository, not our working
+	// directory
+	if dir, err = filepath.Abs(dir); err != nil {
+		return nil, fmt.Errorf("resolve worktree directory: %w", err)
+	}
+
+	res := &Result{
+		Name:   name,
+		Branch: cmp.Or(r.BranchPrefix, DefaultBranchPrefix) + name,
+		Dir:    dir,
+		Base:   base,
+		runner: r,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := git(ctx, r.repo, "worktree", "add", "-q", "-b", res.Branch, dir, base); err != nil {
+		_ = os.RemoveAll(dir)
+
+		return nil, err
+	}
+
+	res.Kept, res.BranchKept = true, true
+
+	return res, nil
+}
+
+// query runs the task's prompt in the worktree and records its messages.
+func (res *Result) query(ctx context.Context, r *Runner, task Task) error {
+	opts := slices.Concat(r.Options, task.Options, []claudesdk.Option{claudesdk.WithCwd(res.Dir)})
+
+	var runErr error
+
+	for msg, err := range claudesdk.Query(ctx, task.Prompt, opts...) {
+		if err != nil {
+			runErr = stderrors.Join(runErr, err)
+
+			continue
+		}
+
+		res.Messages = append(res.Messages, msg)
+
+		if result, ok := msg.(*claudesdk.ResultMessage); ok {
+			res.Result = result
+		}
+	}
+
+	if runErr == nil && res.Result != nil && res.Result.IsError {
+		runErr = fmt.Errorf("query failed: %s", res.Result.Subtype)
+	}
+
+	return runErr
+}
+
+// collect stages everything in the worktree, commits it if message is set,
+// and records the diff against the base. It runs even if the task's context
+// was cancelled, since that is when the agent's work is most at risk.
+func (res *Result) collect(ctx context.Context, message string) error {
+	ctx = context.WithoutCancel(ctx)
+
+	if _, err := git(ctx, res.Dir, "add", "-A"); err != nil {
+		return err
+	}
+
+	names, err := git(ctx, res.Dir, "diff", "--cached", "--name-only", "-z", res.Base)
+	if err != nil {
+		return err
+	}
+
+	for name := range strings.SplitSeq(names, "\x00") {
+		if name != "" {
+			res.Files = append(res.Files, name)
+		}
+	}
+
+	if res.Diff, err = gitRaw(ctx, res.Dir, "diff", "--cached", "--binary", res.Base); err != nil {
+		return err
+	}
+
+	if message != "" {
+		if _, err := git(ctx, res.Dir, "diff", "--cached", "--quiet"); err != nil {
+			if _, err := git(ctx, res.Dir, "commit", "-q", "-m", message); err != nil {
+				return err
+			}
+		}
+	}
+
+	head, err := git(ctx, res.Dir, "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+
+	if head != res.Base {
+		res.Commit = head
+	}
+
+	return nil
+}
+
+// cleanup removes what the retention policy does not keep. A worktree whose
+// changes were not collected is always kept.
+func (r *Runner) cleanup(ctx context.Context, res *Result, failed, uncollected bool) error {
+	if uncollected {
+		return nil
+	}
+
+	switch r.Retention {
+	case RetainAll:
+		return nil
+	case RetainOnError:
+		if failed {
+			return nil
+		}
+	}
+
+	// Clean up even if the task's context was cancelled
+	ctx = context.WithoutCancel(ctx)
+
+	if err := res.removeWorktree(ctx); err != nil {
+		return err
+	}
+
+	if r.Retention == RetainNone || res.Commit == "" {
+		return res.removeBranch(ctx)
+	}
+
+	return nil
+}
+
+// Remove deletes the task's worktree and branch, for results kept by the
+// retention policy.
+func (res *Result) Remove(ctx context.Context) error {
+	if err := res.removeWorktree(ctx); err != nil {
+		return err
+	}
+
+	return res.removeBranch(ctx)
+}
+
+func (res *Result) removeWorktree(ctx context.Context) error {
+	if !res.Kept {
+		return nil
+	}
+
+	res.runner.mu.Lock()
+	defer res.runner.mu.Unlock()
+
+	if _, err := git(ctx, res.runner.repo, "worktree", "remove", "--force", res.Dir); err != nil {
+		return err
+	}
+
+	res.Kept = false
+
+	return nil
+}
+
+func (res *Result) removeBranch(ctx context.Context) error {
+	if !res.BranchKept {
+		return nil
+	}
+
+	res.runner.mu.Lock()
+	defer res.runner.mu.Unlock()
+
+	if _, err := git(ctx, res.runner.repo, "branch", "-q", "-D", res.Branch); err != nil {
+		return err
+	}
+
+	res.BranchKept = false
+
+	return nil
+}
+
+// git runs a git command in dir and returns its trimmed output.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	out, err := gitRaw(ctx, dir, args...)
+
+	return strings.TrimSpace(out), err
+}
+
+// gitRaw runs a git command in dir and returns its output.
+func gitRaw(ctx context.Context, dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		}
+
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+
+	return stdout.String(), nil
+}
+
+func randomName() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+
+	return "task-" + hex.EncodeToString(b)
+}
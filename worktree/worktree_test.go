@@ -0,0 +1,209 @@
+package worktree
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	claudesdk "github.com/wagiedev/claude-agent-sdk-go"
+)
+
+// editTransport writes files into the query's working directory when
+// started, standing in for an agent, then reports a result.
+type editTransport struct {
+	cwd   *string
+	files map[string]string
+	msgs  chan map[string]any
+	errs  chan error
+}
+
+func (e *editTransport) Start(_ context.Context) error {
+	for name, content := range e.files {
+		if err := os.WriteFile(filepath.Join(*e.cwd, name), []byte(content), 0o600); err != nil {
+			return err
+		}
+	}
+
+	e.msgs = make(chan map[string]any, 1)
+	e.errs = make(chan error)
+	e.msgs <- map[string]any{
+		"type": "result", "subtype": "success", "session_id": "s1",
+		"duration_ms": 1, "duration_api_ms": 1, "num_turns": 1,
+	}
+	close(e.msgs)
+
+	return nil
+}
+
+func (e *editTransport) ReadMessages(context.Context) (<-chan map[string]any, <-chan error) {
+	return e.msgs, e.errs
+}
+
+func (e *editTransport) SendMessage(context.Context, []byte) error { return nil }
+func (e *editTransport) Close() error                              { return nil }
+func (e *editTransport) IsReady() bool                             { return true }
+func (e *editTransport) EndInput() error                           { return nil }
+
+// cancelTransport writes files like editTransport, then cancels the query
+// before it reports a result.
+type cancelTransport struct {
+	editTransport
+	cancel context.CancelFunc
+}
+
+func (c *cancelTransport) Start(ctx context.Context) error {
+	if err := c.editTransport.Start(ctx); err != nil {
+		return err
+	}
+
+	c.cancel()
+
+	return nil
+}
+
+// edits returns options that run a query whose agent writes files.
+func edits(files map[string]string) []claudesdk.Option {
+	transport := &editTransport{files: files}
+
+	return []claudesdk.Option{
+		claudesdk.WithTransport(transport),
+		// The runner sets the working directory last; keep a pointer to it
+		func(o *claudesdk.ClaudeAgentOptions) { transport.cwd = &o.Cwd },
+	}
+}
+
+func run(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	out, err := git(t.Context(), dir, args...)
+	require.NoError(t, err)
+
+	return out
+}
+
+func newRepo(t *testing.T) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	repo := t.TempDir()
+	run(t, repo, "init", "-q", "-b", "main")
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "main.go"), []byte("package main\n"), 0o600))
+	run(t, repo, "add", "-A")
+	run(t, repo, "commit", "-q", "-m", "init")
+
+	return repo
+}
+
+func TestRunAll(t *testing.T) {
+	repo := newRepo(t)
+
+	runner, err := New(repo)
+	require.NoError(t, err)
+
+	runner.Dir = t.TempDir()
+	runner.CommitMessage = "Agent changes"
+
+	results, err := runner.RunAll(t.Context(),
+		Task{Name: "fix one", Options: edits(map[string]string{"main.go": "package main\n\nfunc main() {}\n"})},
+		Task{Name: "fix-two", Options: edits(map[string]string{"main.go": "package other\n", "b.txt": "b\n"})},
+		Task{Name: "noop", Options: edits(nil)},
+	)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	one := results[0]
+	assert.Equal(t, "claude/fix-one", one.Branch)
+	assert.Equal(t, []string{"main.go"}, one.Files)
+	assert.Contains(t, one.Diff, "+func main() {}")
+	assert.NotNil(t, one.Result)
+	assert.NotEmpty(t, one.Commit)
+	assert.False(t, one.Kept)
+	assert.NoDirExists(t, one.Dir)
+	assert.True(t, one.BranchKept)
+
+	committed, err := gitRaw(t.Context(), repo, "show", one.Branch+":main.go")
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\nfunc main() {}\n", committed)
+
+	two := results[1]
+	assert.Equal(t, []string{"b.txt", "main.go"}, two.Files)
+	assert.Equal(t, "Agent changes", run(t, repo, "log", "-1", "--format=%s", two.Branch))
+
+	noop := results[2]
+	assert.Empty(t, noop.Files)
+	assert.Empty(t, noop.Commit)
+	assert.False(t, noop.BranchKept, "branches without commits are removed")
+
+	data, err := os.ReadFile(filepath.Join(repo, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", string(data), "the main checkout is untouched")
+}
+
+func TestRetention(t *testing.T) {
+	repo := newRepo(t)
+
+	runner, err := New(repo)
+	require.NoError(t, err)
+
+	runner.Dir = t.TempDir()
+	runner.Retention = RetainAll
+
+	res, err := runner.Run(t.Context(), Task{Options: edits(map[string]string{"new.txt": "x\n"})})
+	require.NoError(t, err)
+	assert.Regexp(t, `^claude/task-[0-9a-f]{8}$`, res.Branch)
+	assert.Empty(t, res.Commit, "without a commit message changes are only diffed")
+	assert.Contains(t, res.Diff, "+++ b/new.txt")
+	assert.FileExists(t, filepath.Join(res.Dir, "new.txt"))
+
+	require.NoError(t, res.Remove(t.Context()))
+	assert.NoDirExists(t, res.Dir)
+	assert.Empty(t, run(t, repo, "branch", "--list", res.Branch))
+
+	runner.Retention = RetainNone
+	runner.CommitMessage = "x"
+
+	res, err = runner.Run(t.Context(), Task{Name: "gone", Options: edits(map[string]string{"new.txt": "x\n"})})
+	require.NoError(t, err)
+	assert.NotEmpty(t, res.Commit)
+	assert.False(t, res.BranchKept)
+	assert.Empty(t, run(t, repo, "branch", "--list", res.Branch))
+}
+
+func TestRun_CancelledQueryKeepsChanges(t *testing.T) {
+	repo := newRepo(t)
+
+	runner, err := New(repo)
+	require.NoError(t, err)
+
+	runner.Dir = t.TempDir()
+	runner.CommitMessage = "agent work"
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	transport := &cancelTransport{editTransport: editTransport{files: map[string]string{"new.txt": "x\n"}}, cancel: cancel}
+
+	res, err := runner.Run(ctx, Task{Name: "cancelled", Options: []claudesdk.Option{
+		claudesdk.WithTransport(transport),
+		func(o *claudesdk.ClaudeAgentOptions) { transport.cwd = &o.Cwd },
+	}})
+	require.Error(t, err)
+	require.NotNil(t, res)
+	assert.Contains(t, res.Diff, "+++ b/new.txt", "changes are collected after cancellation")
+	assert.NotEmpty(t, res.Commit)
+	assert.True(t, res.BranchKept)
+	assert.NotEmpty(t, run(t, repo, "branch", "--list", res.Branch))
+}